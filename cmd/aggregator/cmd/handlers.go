@@ -0,0 +1,99 @@
+// Copyright © 2020 Emando B.V.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/odf"
+	"github.com/emando/vantage-events/pkg/state"
+	"go.uber.org/zap"
+)
+
+// handler handles a JSON encoded event.
+type handler func(buf []byte)
+
+// handlers is a list of handlers that are called in order.
+type handlers []handler
+
+func (hs handlers) handle(buf []byte) {
+	for _, h := range hs {
+		h(buf)
+	}
+}
+
+// queued returns a handler that queues events for h, which runs in its own goroutine until the context is done, so
+// that a slow side effect does not stall the follower until the queue is full. When the queue is full, the handler
+// blocks until the event is queued or the context is done, so that no events are lost.
+func queued(ctx context.Context, wg *sync.WaitGroup, name string, size int, h handler) handler {
+	ch := make(chan []byte, size)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case buf := <-ch:
+				h(buf)
+			}
+		}
+	}()
+	return func(buf []byte) {
+		select {
+		case ch <- buf:
+			return
+		default:
+		}
+		logger.Warn("side effect queue is full, waiting", zap.String("side_effect", name))
+		select {
+		case <-ctx.Done():
+		case ch <- buf:
+		}
+	}
+}
+
+// applyTo returns a handler that applies events to the store.
+func applyTo(store *state.Store) handler {
+	return func(buf []byte) {
+		if err := store.Apply(buf); err != nil {
+			logger.Warn("failed to apply event", zap.Error(err))
+		}
+	}
+}
+
+// exportODF returns a handler that writes ODF documents to the directory on heat activation, lap and commit.
+// The store must be updated before this handler is called.
+func exportODF(store *state.Store, dir string) handler {
+	return func(buf []byte) {
+		var event events.Race
+		if err := json.Unmarshal(buf, &event); err != nil {
+			return
+		}
+		switch event.TypeName() {
+		case events.HeatActivatedType, events.RaceLapAddedType, events.HeatCommittedType:
+		default:
+			return
+		}
+		var docs []*odf.Document
+		now := time.Now()
+		store.Heat(event.CompetitionID, event.DistanceID, state.KeyOf(event.Heat.Heat), func(c *state.Competition, d *state.Distance, h *state.Heat) {
+			docs = append(docs, odf.FromHeat(c, d, h, now))
+			if event.TypeName() == events.HeatCommittedType {
+				docs = append(docs, odf.FromDistance(c, d, now))
+			}
+		})
+		for _, doc := range docs {
+			name, err := doc.WriteFile(dir)
+			if err != nil {
+				logger.Error("failed to write ODF document", zap.Error(err))
+				continue
+			}
+			logger.Debug("wrote ODF document", zap.String("file", name))
+		}
+	}
+}
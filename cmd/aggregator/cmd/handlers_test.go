@@ -0,0 +1,75 @@
+// Copyright © 2020 Emando B.V.
+
+package cmd
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestQueued(t *testing.T) {
+	logger = zap.NewNop()
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	block := make(chan struct{})
+	handled := make(chan []byte, 10)
+	h := queued(ctx, &wg, "test", 2, func(buf []byte) {
+		<-block
+		handled <- buf
+	})
+
+	// The first event is handled, the next two are queued and the last blocks until the queue has room.
+	queuedCh := make(chan int, 4)
+	go func() {
+		for i := 0; i < 4; i++ {
+			h([]byte{byte(i)})
+			queuedCh <- i
+			if i == 0 {
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+	}()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-queuedCh:
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not queued", i)
+		}
+	}
+	select {
+	case <-queuedCh:
+		t.Fatal("event queued when the queue is full")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(block)
+	for i := 0; i < 4; i++ {
+		select {
+		case buf := <-handled:
+			if int(buf[0]) != i {
+				t.Errorf("handled event %d, want %d", buf[0], i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not handled", i)
+		}
+	}
+
+	// The handler does not block after the context is done.
+	cancel()
+	wg.Wait()
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 4; i++ {
+			h([]byte{byte(i)})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler blocks after the context is done")
+	}
+}
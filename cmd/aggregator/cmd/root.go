@@ -13,6 +13,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// version is set at build time.
+var version = "dev"
+
 var (
 	cfgFile string
 	debug   bool
@@ -21,8 +24,9 @@ var (
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "aggregator",
-	Short: "Event Aggregator for Vantage event streaming.",
+	Use:     "aggregator",
+	Short:   "Event Aggregator for Vantage event streaming.",
+	Version: version,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		var err error
 		if viper.GetBool("debug") {
@@ -61,6 +65,8 @@ func init() {
 	rootCmd.PersistentFlags().Bool("nats-tls", true, "use TLS for NATS")
 	rootCmd.PersistentFlags().String("nats-cluster-id", "vantage", "NATS cluster ID")
 	rootCmd.PersistentFlags().String("nats-client-id", "aggregator", "NATS client ID")
+	rootCmd.PersistentFlags().Int("nats-buffer", 64, "number of NATS messages buffered per subscription")
+	rootCmd.PersistentFlags().String("nats-monitor-url", "", "NATS Streaming Server monitoring URL to look up the last message of subjects, i.e. http://localhost:8222")
 
 	viper.BindPFlags(rootCmd.PersistentFlags())
 }
@@ -6,33 +6,88 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"regexp"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/emando/vantage-events/internal/archive"
+	"github.com/emando/vantage-events/internal/cluster"
 	"github.com/emando/vantage-events/internal/follower"
 	"github.com/emando/vantage-events/internal/hub"
+	"github.com/emando/vantage-events/internal/mqtt"
 	"github.com/emando/vantage-events/internal/nats"
+	"github.com/emando/vantage-events/internal/quality"
+	"github.com/emando/vantage-events/internal/rpc"
+	"github.com/emando/vantage-events/internal/webhook"
 	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
+// invalidClientID matches characters that are not allowed in NATS Streaming Server client IDs.
+var invalidClientID = regexp.MustCompile(`[^0-9A-Za-z_-]+`)
+
 // startCmd represents the start command.
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the Event Aggregator.",
 	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// Side effects run in their own goroutines, which stop before their resources are closed.
+		var sideEffects sync.WaitGroup
+
+		// In cluster mode, instances elect a leader to run side effects and share cursors based on NATS sequences.
+		var elector *cluster.Elector
+		if path := viper.GetString("cluster-lock-file"); path != "" {
+			instance := viper.GetString("cluster-instance")
+			if instance == "" {
+				var err error
+				if instance, err = os.Hostname(); err != nil {
+					logger.Fatal("failed to get hostname", zap.Error(err))
+				}
+			}
+			elector = cluster.NewElector(logger, path, instance, viper.GetDuration("cluster-interval"))
+			go elector.Run(ctx)
+		}
+		// sideEffect returns a handler that queues events for h, which runs only on the leader in cluster mode. The
+		// store, the monitor and the hub handle events in memory, in order, before side effects are queued. Side
+		// effects on the leader are at most once: events that arrive during failover are not handled by any instance.
+		sideEffect := func(name string, h handler) handler {
+			q := queued(ctx, &sideEffects, name, viper.GetInt("side-effect-queue"), h)
+			if elector == nil {
+				return q
+			}
+			return elector.Handle(q)
+		}
+		// instanceEffect returns a handler that queues events for h, which runs on every instance in cluster mode.
+		instanceEffect := func(name string, h handler) handler {
+			return queued(ctx, &sideEffects, name, viper.GetInt("side-effect-queue"), h)
+		}
+
 		var source events.Source
+		var checks []hub.Option
 		switch viper.GetString("driver") {
 		case "nats":
 			opts := nats.Options{
-				URL:       viper.GetString("nats-url"),
-				Username:  viper.GetString("nats-username"),
-				Password:  viper.GetString("nats-password"),
-				UseTLS:    viper.GetBool("nats-tls"),
-				ClusterID: viper.GetString("nats-cluster-id"),
-				ClientID:  viper.GetString("nats-client-id"),
+				URL:        viper.GetString("nats-url"),
+				Username:   viper.GetString("nats-username"),
+				Password:   viper.GetString("nats-password"),
+				UseTLS:     viper.GetBool("nats-tls"),
+				ClusterID:  viper.GetString("nats-cluster-id"),
+				ClientID:   viper.GetString("nats-client-id"),
+				Buffer:     viper.GetInt("nats-buffer"),
+				MonitorURL: viper.GetString("nats-monitor-url"),
+				Logger:     logger,
+			}
+			if elector != nil {
+				// Client IDs must be unique in NATS Streaming Server.
+				opts.ClientID += "-" + invalidClientID.ReplaceAllString(elector.Instance(), "-")
+				opts.Cursors = true
 			}
 			logger.With(
 				zap.String("url", opts.URL),
@@ -47,17 +102,47 @@ var startCmd = &cobra.Command{
 			}
 			defer conn.Close()
 			source = nats.NewSource(logger, conn)
+			checks = append(checks, hub.WithCheck("nats", conn.Check))
 		default:
 			logger.Fatal("invalid driver")
 		}
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+		store := state.NewStore()
+		hubOpts := append([]hub.Option{
+			hub.WithStore(store),
+			hub.WithVersion(version),
+			hub.WithLimits(hub.Limits{
+				MaxConnections:         viper.GetInt("hub-max-connections"),
+				MaxConnectionsPerIP:    viper.GetInt("hub-max-connections-per-ip"),
+				MaxConnectionsPerToken: viper.GetInt("hub-max-connections-per-token"),
+				Rate:                   viper.GetFloat64("hub-connection-rate"),
+				Burst:                  viper.GetInt("hub-connection-burst"),
+				AllowedOrigins:         viper.GetStringSlice("hub-allowed-origins"),
+				TrustedProxies:         viper.GetInt("hub-trusted-proxies"),
+			}),
+		}, checks...)
+		if elector != nil {
+			hubOpts = append(hubOpts, hub.WithCluster(elector.Instance(), elector.Leader))
+		}
+		var archiver *archive.Archive
+		if dir := viper.GetString("archive-dir"); dir != "" {
+			var err error
+			if archiver, err = archive.Open(logger, dir); err != nil {
+				logger.Fatal("failed to open archive", zap.Error(err))
+			}
+			defer archiver.Close()
+			hubOpts = append(hubOpts, hub.WithArchive(archiver))
+		}
+		monitor := quality.NewMonitor(logger, store, quality.Options{
+			LapTimeTolerance: viper.GetFloat64("quality-lap-tolerance"),
+		})
+		hubOpts = append(hubOpts, hub.WithMonitor(monitor))
 
 		hub := hub.NewServer(logger, source,
 			viper.GetString("hub-address"),
 			viper.GetString("cert-file"),
 			viper.GetString("key-file"),
+			hubOpts...,
 		)
 		go func() {
 			if err := hub.ListenAndServeTLS(); err != nil {
@@ -65,23 +150,83 @@ var startCmd = &cobra.Command{
 			}
 		}()
 
+		if address := viper.GetString("grpc-address"); address != "" {
+			server := rpc.NewServer(logger, source, store,
+				address,
+				viper.GetString("cert-file"),
+				viper.GetString("key-file"),
+			)
+			go func() {
+				if err := server.ListenAndServeTLS(); err != nil {
+					logger.With(zap.Error(err)).Fatal("failed to listen and serve gRPC")
+				}
+			}()
+		}
+
 		follower := &follower.Follower{
-			Logger: logger,
-			Source: source,
+			Logger:      logger,
+			Source:      source,
+			OnLifecycle: hub.HandleLifecycle,
+			MinBackoff:  viper.GetDuration("follower-min-backoff"),
+			MaxBackoff:  viper.GetDuration("follower-max-backoff"),
 		}
 		competitionCh, err := follower.Run(ctx, viper.GetDuration("history"), viper.GetStringSlice("filter")...)
 		if err != nil {
 			logger.Fatal("failed to run follower", zap.Error(err))
 		}
-		go followCompetitions(ctx, competitionCh)
+		handlers := handlers{applyTo(store), monitor.Handle, hub.Handle}
+		if archiver != nil {
+			// Every instance archives the events that it follows, so that archives are complete on each instance.
+			handlers = append(handlers, instanceEffect("archive", archiver.Handle))
+		}
+		if dir := viper.GetString("odf-dir"); dir != "" {
+			handlers = append(handlers, sideEffect("odf", exportODF(store, dir)))
+		}
+		if url := viper.GetString("mqtt-url"); url != "" {
+			bridge, err := mqtt.Connect(logger, mqtt.Options{
+				URL:         url,
+				Username:    viper.GetString("mqtt-username"),
+				Password:    viper.GetString("mqtt-password"),
+				ClientID:    viper.GetString("mqtt-client-id"),
+				TopicPrefix: viper.GetString("mqtt-topic-prefix"),
+				QoS:         byte(viper.GetInt("mqtt-qos")),
+			})
+			if err != nil {
+				logger.Fatal("failed to connect to MQTT broker", zap.Error(err))
+			}
+			defer bridge.Close()
+			handlers = append(handlers, sideEffect("mqtt", bridge.Handle))
+		}
+		var endpoints []webhook.Endpoint
+		if err := viper.UnmarshalKey("webhooks", &endpoints); err != nil {
+			logger.Fatal("invalid webhooks configuration", zap.Error(err))
+		}
+		if len(endpoints) > 0 {
+			dispatcher, err := webhook.New(logger, webhook.Options{
+				Endpoints:      endpoints,
+				Dir:            viper.GetString("webhook-dir"),
+				MaxAttempts:    viper.GetInt("webhook-max-attempts"),
+				InitialBackoff: viper.GetDuration("webhook-backoff"),
+				MaxBackoff:     viper.GetDuration("webhook-max-backoff"),
+			})
+			if err != nil {
+				logger.Fatal("failed to initialize webhooks", zap.Error(err))
+			}
+			defer dispatcher.Close()
+			go dispatcher.Run(ctx)
+			handlers = append(handlers, sideEffect("webhook", dispatcher.Handle))
+		}
+		go followCompetitions(ctx, handlers.handle, competitionCh)
 
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, os.Interrupt, os.Kill, syscall.SIGTERM)
 		<-sigCh
+		cancel()
+		sideEffects.Wait()
 	},
 }
 
-func followCompetitions(ctx context.Context, ch <-chan *follower.CompetitionEvents) {
+func followCompetitions(ctx context.Context, handle handler, ch <-chan *follower.CompetitionEvents) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -92,42 +237,49 @@ func followCompetitions(ctx context.Context, ch <-chan *follower.CompetitionEven
 			}
 			logger := logger.With(zap.String("competition_name", c.Competition.Name))
 			logger.Info("competition activated")
-			go followCompetition(ctx, c)
+			handle(c.RawActivation)
+			go followCompetition(ctx, handle, c)
 		}
 	}
 }
 
-func followCompetition(ctx context.Context, competition *follower.CompetitionEvents) {
+func followCompetition(ctx context.Context, handle handler, competition *follower.CompetitionEvents) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case _, ok := <-competition.RawEvents:
+		case buf, ok := <-competition.RawEvents:
 			if !ok {
 				return
 			}
 			logger.Debug("received competition event")
+			handle(buf)
 		case d, ok := <-competition.DistanceEvents:
 			if !ok {
 				return
 			}
-			logger := logger.With(zap.String("distance_name", d.Distance.Name))
+			logger := logger.With(
+				zap.String("distance_name", d.Distance.Name),
+				zap.String("discipline", d.Discipline.Name()),
+			)
 			logger.Info("distance activated")
-			go followDistance(ctx, d)
+			handle(d.RawActivation)
+			go followDistance(ctx, handle, d)
 		}
 	}
 }
 
-func followDistance(ctx context.Context, distance *follower.DistanceEvents) {
+func followDistance(ctx context.Context, handle handler, distance *follower.DistanceEvents) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case _, ok := <-distance.RawEvents:
+		case buf, ok := <-distance.RawEvents:
 			if !ok {
 				return
 			}
 			logger.Debug("received distance event")
+			handle(buf)
 		case h, ok := <-distance.HeatEvents:
 			if !ok {
 				return
@@ -135,23 +287,26 @@ func followDistance(ctx context.Context, distance *follower.DistanceEvents) {
 			logger := logger.With(
 				zap.Int("heat_round", h.Heat.Key.Round),
 				zap.Int("heat_number", h.Heat.Key.Number),
+				zap.String("round_name", h.Round),
 			)
 			logger.Info("heat activated")
-			go followHeat(ctx, h)
+			handle(h.RawActivation)
+			go followHeat(ctx, handle, h)
 		}
 	}
 }
 
-func followHeat(ctx context.Context, heat *follower.HeatEvents) {
+func followHeat(ctx context.Context, handle handler, heat *follower.HeatEvents) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case _, ok := <-heat.RawEvents:
+		case buf, ok := <-heat.RawEvents:
 			if !ok {
 				return
 			}
 			logger.Debug("received heat event")
+			handle(buf)
 		}
 	}
 }
@@ -160,8 +315,35 @@ func init() {
 	rootCmd.AddCommand(startCmd)
 	startCmd.Flags().Duration("history", 24*time.Hour, "time to seek competition activations")
 	startCmd.Flags().StringSlice("filter", nil, "filter competitions by ID")
+	startCmd.Flags().Duration("follower-min-backoff", time.Second, "minimum backoff to retry failed subscriptions")
+	startCmd.Flags().Duration("follower-max-backoff", time.Minute, "maximum backoff to retry failed subscriptions")
 	startCmd.Flags().String("hub-address", ":443", "hub listen address")
+	startCmd.Flags().Int("hub-max-connections", 0, "maximum number of websocket connections (unlimited if 0)")
+	startCmd.Flags().Int("hub-max-connections-per-ip", 0, "maximum number of websocket connections per IP address (unlimited if 0)")
+	startCmd.Flags().Int("hub-max-connections-per-token", 0, "maximum number of websocket connections per API token (unlimited if 0)")
+	startCmd.Flags().Float64("hub-connection-rate", 0, "new websocket connections per second per IP address (unlimited if 0)")
+	startCmd.Flags().Int("hub-connection-burst", 10, "burst of new websocket connections per IP address")
+	startCmd.Flags().StringSlice("hub-allowed-origins", nil, "allowed origins of websocket connections, or * for all (same origin if empty)")
+	startCmd.Flags().Int("hub-trusted-proxies", 0, "number of trusted proxies that set X-Forwarded-For (ignored if 0)")
+	startCmd.Flags().String("grpc-address", "", "gRPC listen address, i.e. :8443 (disabled if empty)")
 	startCmd.Flags().String("cert-file", "cert.pem", "TLS certificate file")
 	startCmd.Flags().String("key-file", "key.pem", "TLS key file")
+	startCmd.Flags().String("archive-dir", "", "directory of the event archive, separate per instance in cluster mode (disabled if empty)")
+	startCmd.Flags().Float64("quality-lap-tolerance", 0.1, "relative deviation of lap times from estimates to alert")
+	startCmd.Flags().String("odf-dir", "", "drop folder for ODF documents (disabled if empty)")
+	startCmd.Flags().String("mqtt-url", "", "MQTT broker URL, i.e. tcp://localhost:1883 (disabled if empty)")
+	startCmd.Flags().String("mqtt-username", "", "MQTT username")
+	startCmd.Flags().String("mqtt-password", "", "MQTT password")
+	startCmd.Flags().String("mqtt-client-id", "aggregator", "MQTT client ID")
+	startCmd.Flags().String("mqtt-topic-prefix", "", "MQTT topic prefix")
+	startCmd.Flags().Int("mqtt-qos", 1, "MQTT quality of service (0, 1 or 2)")
+	startCmd.Flags().String("webhook-dir", "webhooks", "directory for the webhook retry queue and delivery log")
+	startCmd.Flags().Int("webhook-max-attempts", 10, "maximum number of webhook delivery attempts")
+	startCmd.Flags().Duration("webhook-backoff", time.Second, "initial backoff between webhook delivery attempts")
+	startCmd.Flags().Duration("webhook-max-backoff", 5*time.Minute, "maximum backoff between webhook delivery attempts")
+	startCmd.Flags().String("cluster-lock-file", "", "lock file shared by the instances of the cluster (disabled if empty); ODF, MQTT and webhook side effects run at most once, on the leader")
+	startCmd.Flags().String("cluster-instance", "", "name of the instance in the cluster (default is the hostname)")
+	startCmd.Flags().Duration("cluster-interval", 5*time.Second, "interval to campaign for leadership of the cluster")
+	startCmd.Flags().Int("side-effect-queue", 1024, "number of events queued per side effect (archive, ODF, MQTT and webhooks)")
 	viper.BindPFlags(startCmd.Flags())
 }
@@ -0,0 +1,57 @@
+// Copyright © 2020 Emando B.V.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/emando/vantage-events/internal/webhook"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// webhookReceiverCmd represents the webhook-receiver command.
+var webhookReceiverCmd = &cobra.Command{
+	Use:   "webhook-receiver",
+	Short: "Run a local webhook endpoint for testing.",
+	Long: `Run a local webhook endpoint for testing webhook delivery.
+
+The endpoint verifies signatures if a secret is given and logs received events.
+Pass --status to respond with another status code to test retries.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			logger := logger.With(
+				zap.String("event", r.Header.Get(webhook.EventHeader)),
+				zap.String("delivery", r.Header.Get(webhook.DeliveryHeader)),
+			)
+			buf, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				logger.Warn("failed to read body", zap.Error(err))
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if secret := viper.GetString("webhook-secret"); secret != "" &&
+				!webhook.Verify(secret, r.Header.Get(webhook.TimestampHeader), buf, r.Header.Get(webhook.SignatureHeader)) {
+				logger.Warn("invalid signature")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			logger.Info("received event", zap.Int("size", len(buf)))
+			w.WriteHeader(viper.GetInt("webhook-status"))
+		})
+		logger.Info("starting webhook receiver", zap.String("address", viper.GetString("webhook-address")))
+		if err := http.ListenAndServe(viper.GetString("webhook-address"), nil); err != nil {
+			logger.Fatal("failed to listen", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(webhookReceiverCmd)
+	webhookReceiverCmd.Flags().String("webhook-address", ":8080", "listen address")
+	webhookReceiverCmd.Flags().String("webhook-secret", "", "secret to verify signatures")
+	webhookReceiverCmd.Flags().Int("webhook-status", http.StatusNoContent, "status code to respond with")
+	viper.BindPFlags(webhookReceiverCmd.Flags())
+}
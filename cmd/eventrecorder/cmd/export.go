@@ -0,0 +1,149 @@
+// Copyright © 2020 Emando B.V.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/emando/vantage-events/pkg/client"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/results"
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// exportCmd represents the export command.
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export results.",
+	Long: `Export results of distances to CSV or JSON files.
+
+Results are exported from the recording in the file. With --live, results of
+the competition are exported from the Vantage Events Server on each lap and
+heat commit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := os.MkdirAll(viper.GetString("output"), 0755); err != nil {
+			logger.Fatal("failed to create output directory", zap.Error(err))
+		}
+		store := state.NewStore()
+		if viper.GetBool("live") {
+			exportLive(store)
+			return
+		}
+
+		file, err := os.Open(viper.GetString("file"))
+		if err != nil {
+			logger.Fatal("failed to open file for reading",
+				zap.String("file", viper.GetString("file")),
+				zap.Error(err),
+			)
+		}
+		defer file.Close()
+		reader := bufio.NewReader(file)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if err := store.Apply(line); err != nil {
+					logger.Warn("failed to apply event", zap.Error(err))
+				}
+			}
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				logger.Fatal("failed to read from file", zap.Error(err))
+			}
+		}
+		store.Competitions(func(c *state.Competition) {
+			for _, table := range results.FromCompetition(c) {
+				if err := writeTable(table); err != nil {
+					logger.Fatal("failed to write results", zap.Error(err))
+				}
+			}
+		})
+	},
+}
+
+func exportLive(store *state.Store) {
+	id := viper.GetString("competition")
+	if id == "" {
+		logger.Fatal("no competition specified")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := client.New(client.CompetitionURL(viper.GetString("host"), id), client.WithLogger(logger), client.WithStore(store))
+	export := func(client.Event) {
+		store.Competition(id, func(c *state.Competition) {
+			for _, table := range results.FromCompetition(c) {
+				if err := writeTable(table); err != nil {
+					logger.Error("failed to write results", zap.Error(err))
+				}
+			}
+		})
+	}
+	c.On(events.HeatCommittedType, export)
+	c.On(events.LastPresentedRaceLapChangedType, export)
+	go func() {
+		if err := c.Run(ctx); err != nil && err != context.Canceled {
+			logger.Fatal("failed to run client", zap.Error(err))
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, os.Kill, syscall.SIGTERM)
+	<-sigCh
+}
+
+// writeTable writes the results table to the output directory. The file is replaced atomically.
+func writeTable(table *results.Table) error {
+	format := viper.GetString("format")
+	name := filepath.Join(viper.GetString("output"), fmt.Sprintf("%02d-%s.%s", table.DistanceNumber, table.DistanceID, format))
+	tmp, err := ioutil.TempFile(viper.GetString("output"), ".results-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	switch format {
+	case "csv":
+		err = table.WriteCSV(tmp)
+	case "json":
+		err = table.WriteJSON(tmp)
+	default:
+		err = fmt.Errorf("invalid format %q", format)
+	}
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return err
+	}
+	logger.Info("wrote results", zap.String("file", name), zap.Int("rows", len(table.Rows)))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("output", "results", "output directory")
+	exportCmd.Flags().String("format", "csv", "output format (csv, json)")
+	exportCmd.Flags().Bool("live", false, "export live from the Vantage Events Server")
+	viper.BindPFlags(exportCmd.Flags())
+}
@@ -55,6 +55,7 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("debug", "d", false, "enable debugging")
 	rootCmd.PersistentFlags().String("file", "log.json", "file")
 	rootCmd.PersistentFlags().String("host", "events.emandovantage.com", "Vantage Events Server host")
+	rootCmd.PersistentFlags().String("competition", "", "competition ID")
 
 	viper.BindPFlags(rootCmd.PersistentFlags())
 }
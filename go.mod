@@ -2,8 +2,9 @@ module github.com/emando/vantage-events
 
 require (
 	github.com/FiloSottile/mkcert v1.4.1 // indirect
+	github.com/eclipse/paho.mqtt.golang v1.2.0
 	github.com/gogo/protobuf v1.3.1 // indirect
-	github.com/golang/protobuf v1.3.2 // indirect
+	github.com/golang/protobuf v1.3.2
 	github.com/gorilla/mux v1.7.3
 	github.com/gorilla/websocket v1.4.1
 	github.com/nats-io/jwt v0.3.2 // indirect
@@ -24,6 +25,7 @@ require (
 	golang.org/x/lint v0.0.0-20191125180803-fdd1cda4f05f
 	golang.org/x/sys v0.0.0-20200107162124-548cf772de50 // indirect
 	golang.org/x/tools v0.0.0-20200110213125-a7a6caa82ab2 // indirect
+	google.golang.org/grpc v1.26.0
 	gopkg.in/ini.v1 v1.51.1 // indirect
 	gopkg.in/yaml.v2 v2.2.7 // indirect
 	mvdan.cc/gofumpt v0.0.0-20191220113447-b896b372089f
@@ -0,0 +1,230 @@
+// Copyright © 2020 Emando B.V.
+
+// Package archive persists events of competitions after they leave the NATS Streaming Server.
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+	"go.uber.org/zap"
+)
+
+// ErrNotFound is returned when a competition is not in the archive.
+var ErrNotFound = errors.New("archive: competition not found")
+
+// validID matches competition IDs that are safe to use as directory names.
+var validID = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+const (
+	competitionSubject = "competition.%v"
+	distanceSubject    = "competition.%v.distances.%v"
+	heatSubject        = "competition.%v.distances.%v.heats.%d.%d"
+	distancesToken     = "distances"
+)
+
+// Competition is an archived competition.
+type Competition struct {
+	entities.Competition
+	Distances  []Distance `json:"distances"`
+	FirstEvent time.Time  `json:"firstEvent"`
+	LastEvent  time.Time  `json:"lastEvent"`
+	Events     int        `json:"events"`
+}
+
+// Distance is an archived distance.
+type Distance struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Number int    `json:"number"`
+}
+
+// Query selects archived events.
+type Query struct {
+	// DistanceID selects the events of the distance and its heats. Empty selects all events.
+	DistanceID string
+	// Since and Until select events received in the time range. Zero values are unbounded.
+	Since,
+	Until time.Time
+}
+
+// Record is an archived event.
+type Record struct {
+	// Time is the time the event was received.
+	Time time.Time
+	// Subject is the subject of the competition, distance or heat.
+	Subject string
+	// Event is the JSON encoded event.
+	Event []byte
+}
+
+// Archive persists events per competition in an append-only store.
+type Archive struct {
+	logger *zap.Logger
+	dir    string
+
+	mu     sync.Mutex
+	stores map[string]*store
+}
+
+// Open opens the archive in the directory.
+func Open(logger *zap.Logger, dir string) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Archive{
+		logger: logger,
+		dir:    dir,
+		stores: make(map[string]*store),
+	}, nil
+}
+
+// Close closes the archive.
+func (a *Archive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var res error
+	for id, s := range a.stores {
+		if err := s.close(); err != nil && res == nil {
+			res = err
+		}
+		delete(a.stores, id)
+	}
+	return res
+}
+
+// store returns the store of the competition. If create is false and the competition is not in the archive, this
+// method returns ErrNotFound.
+func (a *Archive) store(id string, create bool) (*store, error) {
+	if !validID.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if s, ok := a.stores[id]; ok {
+		return s, nil
+	}
+	dir := filepath.Join(a.dir, id)
+	if !create {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+	}
+	s, err := openStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	a.stores[id] = s
+	return s, nil
+}
+
+// subjectOf returns the subject of the competition, distance or heat of the event.
+func subjectOf(event events.Race) string {
+	switch {
+	case event.Key.Round != 0 || event.Key.Number != 0:
+		return fmt.Sprintf(heatSubject, event.CompetitionID, event.DistanceID, event.Key.Round, event.Key.Number)
+	case event.DistanceID != "":
+		return fmt.Sprintf(distanceSubject, event.CompetitionID, event.DistanceID)
+	default:
+		return fmt.Sprintf(competitionSubject, event.CompetitionID)
+	}
+}
+
+// Handle archives the JSON encoded event.
+func (a *Archive) Handle(buf []byte) {
+	if err := a.Append(time.Now(), buf); err != nil {
+		a.logger.Warn("failed to archive event", zap.Error(err))
+	}
+}
+
+// Append archives the JSON encoded event received at the given time. Events that are already archived are ignored,
+// so that replayed events are archived once.
+func (a *Archive) Append(t time.Time, buf []byte) error {
+	var header events.Race
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return err
+	}
+	if header.CompetitionID == "" {
+		return nil
+	}
+	s, err := a.store(header.CompetitionID, true)
+	if err != nil {
+		return err
+	}
+	switch header.TypeName() {
+	case events.CompetitionActivatedType:
+		event := &events.CompetitionActivated{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		if err := s.setCompetition(event.Value); err != nil {
+			return err
+		}
+	case events.DistanceActivatedType:
+		event := &events.DistanceActivated{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		if err := s.addDistance(Distance{
+			ID:     event.Value.ID,
+			Name:   event.Value.Name,
+			Number: event.Value.Number,
+		}); err != nil {
+			return err
+		}
+	}
+	return s.append(t, subjectOf(header), buf)
+}
+
+// Competitions returns the archived competitions, most recent first.
+func (a *Archive) Competitions() ([]Competition, error) {
+	infos, err := ioutil.ReadDir(a.dir)
+	if err != nil {
+		return nil, err
+	}
+	res := []Competition{}
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		s, err := a.store(info.Name(), false)
+		if err == ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		res = append(res, s.summary())
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].LastEvent.After(res[j].LastEvent)
+	})
+	return res, nil
+}
+
+// Competition returns the archived competition.
+func (a *Archive) Competition(id string) (Competition, error) {
+	s, err := a.store(id, false)
+	if err != nil {
+		return Competition{}, err
+	}
+	return s.summary(), nil
+}
+
+// Events calls f with each archived event of the competition that matches the query, in order of time.
+func (a *Archive) Events(id string, q Query, f func(Record) error) error {
+	s, err := a.store(id, false)
+	if err != nil {
+		return err
+	}
+	return s.read(q, f)
+}
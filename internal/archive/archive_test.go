@@ -0,0 +1,259 @@
+// Copyright © 2020 Emando B.V.
+
+package archive
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	start = time.Date(2020, 1, 12, 10, 0, 0, 0, time.UTC)
+
+	archived = []struct {
+		offset time.Duration
+		event  string
+	}{
+		{0, `{"typeName":"CompetitionActivatedEvent","competitionId":"c1","competition":{"id":"c1","name":"EC Single Distances"}}`},
+		{time.Minute, `{"typeName":"DistanceActivatedEvent","competitionId":"c1","distanceId":"d2","distance":{"id":"d2","name":"Ladies 1000 meter","number":2}}`},
+		{2 * time.Minute, `{"typeName":"HeatActivatedEvent","competitionId":"c1","distanceId":"d2","heat":{"round":1,"number":1}}`},
+		{3 * time.Minute, `{"typeName":"DistanceActivatedEvent","competitionId":"c1","distanceId":"d1","distance":{"id":"d1","name":"Men 500 meter","number":1}}`},
+		{4 * time.Minute, `{"typeName":"HeatActivatedEvent","competitionId":"c1","distanceId":"d1","heat":{"round":1,"number":2}}`},
+		{5 * time.Minute, `{"typeName":"DistanceDeactivatedEvent","competitionId":"c1","distanceId":"d1"}`},
+	}
+)
+
+// appendArchived appends the archived events, each event twice.
+func appendArchived(t *testing.T, a *Archive) {
+	t.Helper()
+	for i := 0; i < 2; i++ {
+		for _, e := range archived {
+			if err := a.Append(start.Add(e.offset), []byte(e.event)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func subjects(t *testing.T, a *Archive, id string, q Query) []string {
+	t.Helper()
+	var res []string
+	if err := a.Events(id, q, func(r Record) error {
+		res = append(res, r.Subject)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a, err := Open(zap.NewNop(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendArchived(t, a)
+
+	for _, tc := range []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{
+			name: "All",
+			expected: []string{
+				"competition.c1",
+				"competition.c1.distances.d2",
+				"competition.c1.distances.d2.heats.1.1",
+				"competition.c1.distances.d1",
+				"competition.c1.distances.d1.heats.1.2",
+				"competition.c1.distances.d1",
+			},
+		},
+		{
+			name:     "Distance",
+			query:    Query{DistanceID: "d2"},
+			expected: []string{"competition.c1.distances.d2", "competition.c1.distances.d2.heats.1.1"},
+		},
+		{
+			name:  "Since",
+			query: Query{Since: start.Add(4 * time.Minute)},
+			expected: []string{
+				"competition.c1.distances.d1.heats.1.2",
+				"competition.c1.distances.d1",
+			},
+		},
+		{
+			name:     "SinceUntil",
+			query:    Query{Since: start.Add(time.Minute), Until: start.Add(2 * time.Minute)},
+			expected: []string{"competition.c1.distances.d2", "competition.c1.distances.d2.heats.1.1"},
+		},
+		{
+			name:     "DistanceUntil",
+			query:    Query{DistanceID: "d1", Until: start.Add(3 * time.Minute)},
+			expected: []string{"competition.c1.distances.d1"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if res := subjects(t, a, "c1", tc.query); !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("subjects are %v, want %v", res, tc.expected)
+			}
+		})
+	}
+
+	c, err := a.Competition("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "EC Single Distances" || c.Events != len(archived) {
+		t.Errorf("competition is %q with %d events, want %d events", c.Name, c.Events, len(archived))
+	}
+	if !c.FirstEvent.Equal(start) || !c.LastEvent.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("events are from %v to %v", c.FirstEvent, c.LastEvent)
+	}
+	expectedDistances := []Distance{{ID: "d1", Name: "Men 500 meter", Number: 1}, {ID: "d2", Name: "Ladies 1000 meter", Number: 2}}
+	if !reflect.DeepEqual(c.Distances, expectedDistances) {
+		t.Errorf("distances are %+v, want %+v", c.Distances, expectedDistances)
+	}
+
+	// The archive is persistent.
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if a, err = Open(zap.NewNop(), dir); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	appendArchived(t, a)
+	competitions, err := a.Competitions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(competitions) != 1 || competitions[0].Events != len(archived) || !reflect.DeepEqual(competitions[0].Distances, expectedDistances) {
+		t.Errorf("competitions after reopening are %+v", competitions)
+	}
+}
+
+func TestArchiveNotFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a, err := Open(zap.NewNop(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	for _, id := range []string{"c2", "..", "c1/../c2", ""} {
+		if _, err := a.Competition(id); err != ErrNotFound {
+			t.Errorf("competition %q error is %v, want %v", id, err, ErrNotFound)
+		}
+	}
+	if err := a.Append(start, []byte(`{"typeName":"CompetitionUpdatedEvent","competitionId":"../c2"}`)); err != ErrNotFound {
+		t.Errorf("append with invalid ID error is %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestArchiveRebuild(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		corrupt func(dir string) error
+	}{
+		{
+			name: "PartialRecord",
+			corrupt: func(dir string) error {
+				f, err := os.OpenFile(filepath.Join(dir, logFile), os.O_APPEND|os.O_WRONLY, 0644)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				_, err = f.WriteString(`{"time":"2020-01-12T10:06:00Z","subj`)
+				return err
+			},
+		},
+		{
+			name: "PartialEntry",
+			corrupt: func(dir string) error {
+				name := filepath.Join(dir, indexFile)
+				info, err := os.Stat(name)
+				if err != nil {
+					return err
+				}
+				return os.Truncate(name, info.Size()-entrySize/2)
+			},
+		},
+		{
+			name: "NoIndex",
+			corrupt: func(dir string) error {
+				if err := os.Remove(filepath.Join(dir, indexFile)); err != nil {
+					return err
+				}
+				return os.Remove(filepath.Join(dir, subjectsFile))
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "archive")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			a, err := Open(zap.NewNop(), dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			appendArchived(t, a)
+			expected := subjects(t, a, "c1", Query{})
+			a.Close()
+
+			if err := tc.corrupt(filepath.Join(dir, "c1")); err != nil {
+				t.Fatal(err)
+			}
+			if a, err = Open(zap.NewNop(), dir); err != nil {
+				t.Fatal(err)
+			}
+			defer a.Close()
+			if res := subjects(t, a, "c1", Query{}); !reflect.DeepEqual(res, expected) {
+				t.Errorf("subjects after rebuild are %v, want %v", res, expected)
+			}
+			// Appending after the rebuild continues the log.
+			if err := a.Append(start.Add(6*time.Minute), []byte(`{"typeName":"CompetitionUpdatedEvent","competitionId":"c1"}`)); err != nil {
+				t.Fatal(err)
+			}
+			if res := subjects(t, a, "c1", Query{Since: start.Add(6 * time.Minute)}); !reflect.DeepEqual(res, []string{"competition.c1"}) {
+				t.Errorf("subjects after appending are %v", res)
+			}
+		})
+	}
+}
+
+func TestHasDistance(t *testing.T) {
+	for _, tc := range []struct {
+		subject  string
+		expected bool
+	}{
+		{subject: "competition.c1.distances.d1", expected: true},
+		{subject: "competition.c1.distances.d1.heats.1.1", expected: true},
+		{subject: "competition.c1.distances.d10", expected: false},
+		{subject: "competition.c1.distances.d10.heats.1.1", expected: false},
+		{subject: "competition.c1", expected: false},
+	} {
+		t.Run(tc.subject, func(t *testing.T) {
+			if res := hasDistance(tc.subject, ".distances.d1"); res != tc.expected {
+				t.Errorf("has distance is %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,414 @@
+// Copyright © 2020 Emando B.V.
+
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+)
+
+const (
+	logFile      = "events.log"
+	indexFile    = "index"
+	subjectsFile = "subjects"
+	metaFile     = "meta.json"
+)
+
+// entrySize is the size of an index entry: time (8), offset (8), length (4), subject (4) and digest (8).
+const entrySize = 32
+
+// entry is an index entry of a record in the log.
+type entry struct {
+	time    int64
+	offset  int64
+	length  uint32
+	subject uint32
+	digest  [8]byte
+}
+
+func (e entry) marshal() []byte {
+	buf := make([]byte, entrySize)
+	binary.BigEndian.PutUint64(buf[0:], uint64(e.time))
+	binary.BigEndian.PutUint64(buf[8:], uint64(e.offset))
+	binary.BigEndian.PutUint32(buf[16:], e.length)
+	binary.BigEndian.PutUint32(buf[20:], e.subject)
+	copy(buf[24:], e.digest[:])
+	return buf
+}
+
+func unmarshalEntry(buf []byte) entry {
+	e := entry{
+		time:    int64(binary.BigEndian.Uint64(buf[0:])),
+		offset:  int64(binary.BigEndian.Uint64(buf[8:])),
+		length:  binary.BigEndian.Uint32(buf[16:]),
+		subject: binary.BigEndian.Uint32(buf[20:]),
+	}
+	copy(e.digest[:], buf[24:])
+	return e
+}
+
+func digestOf(event []byte) [8]byte {
+	var d [8]byte
+	sum := sha256.Sum256(event)
+	copy(d[:], sum[:])
+	return d
+}
+
+// record is a line in the log.
+type record struct {
+	Time    time.Time       `json:"time"`
+	Subject string          `json:"subject"`
+	Event   json.RawMessage `json:"event"`
+}
+
+// meta is the metadata of an archived competition.
+type meta struct {
+	Competition entities.Competition `json:"competition"`
+	Distances   []Distance           `json:"distances"`
+}
+
+// store is the append-only store of a competition. The log contains a JSON record per line. The index contains a
+// fixed size entry per record, ordered by time, that refers to the subject by line number in the subjects file.
+type store struct {
+	dir string
+
+	mu       sync.RWMutex
+	log      *os.File
+	index    *os.File
+	subjects *os.File
+	names    []string
+	ids      map[string]uint32
+	entries  []entry
+	digests  map[[8]byte]struct{}
+	meta     meta
+}
+
+func openStore(dir string) (*store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &store{
+		dir:     dir,
+		ids:     make(map[string]uint32),
+		digests: make(map[[8]byte]struct{}),
+	}
+	var err error
+	if s.log, err = os.OpenFile(filepath.Join(dir, logFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644); err != nil {
+		return nil, err
+	}
+	if s.index, err = os.OpenFile(filepath.Join(dir, indexFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644); err != nil {
+		s.close()
+		return nil, err
+	}
+	if s.subjects, err = os.OpenFile(filepath.Join(dir, subjectsFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644); err != nil {
+		s.close()
+		return nil, err
+	}
+	if buf, err := ioutil.ReadFile(filepath.Join(dir, metaFile)); err == nil {
+		if err := json.Unmarshal(buf, &s.meta); err != nil {
+			s.close()
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		s.close()
+		return nil, err
+	}
+	ok, err := s.load()
+	if err == nil && !ok {
+		err = s.rebuild()
+	}
+	if err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *store) close() error {
+	var res error
+	for _, f := range []*os.File{s.log, s.index, s.subjects} {
+		if f == nil {
+			continue
+		}
+		if err := f.Close(); err != nil && res == nil {
+			res = err
+		}
+	}
+	return res
+}
+
+// load loads the index and subjects. This method returns false if the index is inconsistent with the log.
+func (s *store) load() (bool, error) {
+	buf, err := ioutil.ReadFile(s.subjects.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(buf) > 0 && buf[len(buf)-1] != '\n' {
+		return false, nil
+	}
+	for _, name := range strings.Split(string(buf), "\n") {
+		if name != "" {
+			s.ids[name] = uint32(len(s.names))
+			s.names = append(s.names, name)
+		}
+	}
+	buf, err = ioutil.ReadFile(s.index.Name())
+	if err != nil {
+		return false, err
+	}
+	if len(buf)%entrySize != 0 {
+		return false, nil
+	}
+	var end int64
+	for i := 0; i < len(buf); i += entrySize {
+		e := unmarshalEntry(buf[i : i+entrySize])
+		if int(e.subject) >= len(s.names) || e.offset != end {
+			return false, nil
+		}
+		end = e.offset + int64(e.length)
+		s.entries = append(s.entries, e)
+		s.digests[e.digest] = struct{}{}
+	}
+	info, err := s.log.Stat()
+	if err != nil {
+		return false, err
+	}
+	return info.Size() == end, nil
+}
+
+// rebuild rebuilds the index and subjects from the log. A partially written record at the end of the log is removed.
+func (s *store) rebuild() error {
+	s.names, s.entries = nil, nil
+	s.ids = make(map[string]uint32)
+	s.digests = make(map[[8]byte]struct{})
+	if err := s.index.Truncate(0); err != nil {
+		return err
+	}
+	if err := s.subjects.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(s.log)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			break
+		}
+		e, err := s.indexRecord(rec, offset, len(line))
+		if err != nil {
+			return err
+		}
+		s.entries = append(s.entries, e)
+		s.digests[e.digest] = struct{}{}
+		offset += int64(len(line))
+	}
+	return s.log.Truncate(offset)
+}
+
+// indexRecord appends the index entry of the record at the offset in the log.
+func (s *store) indexRecord(rec record, offset int64, length int) (entry, error) {
+	id, ok := s.ids[rec.Subject]
+	if !ok {
+		id = uint32(len(s.names))
+		if _, err := s.subjects.WriteString(rec.Subject + "\n"); err != nil {
+			return entry{}, err
+		}
+		s.ids[rec.Subject] = id
+		s.names = append(s.names, rec.Subject)
+	}
+	e := entry{
+		time:    rec.Time.UnixNano(),
+		offset:  offset,
+		length:  uint32(length),
+		subject: id,
+		digest:  digestOf(rec.Event),
+	}
+	if _, err := s.index.Write(e.marshal()); err != nil {
+		return entry{}, err
+	}
+	return e, nil
+}
+
+// append appends the event. Events that are already in the store are ignored.
+func (s *store) append(t time.Time, subject string, event []byte) error {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, event); err != nil {
+		return err
+	}
+	event = compact.Bytes()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.digests[digestOf(event)]; ok {
+		return nil
+	}
+	// Keep the index ordered by time.
+	if n := len(s.entries); n > 0 && t.UnixNano() < s.entries[n-1].time {
+		t = time.Unix(0, s.entries[n-1].time)
+	}
+	rec := record{
+		Time:    t,
+		Subject: subject,
+		Event:   event,
+	}
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	buf = append(buf, '\n')
+	var offset int64
+	if n := len(s.entries); n > 0 {
+		offset = s.entries[n-1].offset + int64(s.entries[n-1].length)
+	}
+	if _, err := s.log.Write(buf); err != nil {
+		return err
+	}
+	e, err := s.indexRecord(rec, offset, len(buf))
+	if err != nil {
+		return err
+	}
+	s.entries = append(s.entries, e)
+	s.digests[e.digest] = struct{}{}
+	return nil
+}
+
+// setCompetition sets the competition in the metadata.
+func (s *store) setCompetition(c entities.Competition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meta.Competition = c
+	return s.saveMeta()
+}
+
+// addDistance adds or replaces the distance in the metadata.
+func (s *store) addDistance(d Distance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.meta.Distances {
+		if existing.ID == d.ID {
+			if existing == d {
+				return nil
+			}
+			s.meta.Distances[i] = d
+			return s.saveMeta()
+		}
+	}
+	s.meta.Distances = append(s.meta.Distances, d)
+	sort.Slice(s.meta.Distances, func(i, j int) bool {
+		return s.meta.Distances[i].Number < s.meta.Distances[j].Number
+	})
+	return s.saveMeta()
+}
+
+// saveMeta writes the metadata atomically.
+func (s *store) saveMeta() error {
+	buf, err := json.Marshal(s.meta)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(s.dir, ".meta-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, metaFile))
+}
+
+// summary returns the summary of the archived competition.
+func (s *store) summary() Competition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := Competition{
+		Competition: s.meta.Competition,
+		Distances:   append([]Distance{}, s.meta.Distances...),
+		Events:      len(s.entries),
+	}
+	if n := len(s.entries); n > 0 {
+		c.FirstEvent = time.Unix(0, s.entries[0].time)
+		c.LastEvent = time.Unix(0, s.entries[n-1].time)
+	}
+	return c
+}
+
+// read calls f with each record that matches the query, in order of time.
+func (s *store) read(q Query, f func(Record) error) error {
+	s.mu.RLock()
+	entries := s.entries
+	names := s.names
+	s.mu.RUnlock()
+
+	start := 0
+	if !q.Since.IsZero() {
+		since := q.Since.UnixNano()
+		start = sort.Search(len(entries), func(i int) bool {
+			return entries[i].time >= since
+		})
+	}
+	var prefix string
+	if q.DistanceID != "" {
+		prefix = "." + distancesToken + "." + q.DistanceID
+	}
+	for _, e := range entries[start:] {
+		if !q.Until.IsZero() && e.time > q.Until.UnixNano() {
+			break
+		}
+		subject := names[e.subject]
+		if prefix != "" && !hasDistance(subject, prefix) {
+			continue
+		}
+		buf := make([]byte, e.length)
+		if _, err := s.log.ReadAt(buf, e.offset); err != nil {
+			return err
+		}
+		var rec record
+		if err := json.Unmarshal(bytes.TrimSpace(buf), &rec); err != nil {
+			return err
+		}
+		if err := f(Record{
+			Time:    rec.Time,
+			Subject: rec.Subject,
+			Event:   rec.Event,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasDistance returns whether the subject is of the distance or a heat of the distance.
+func hasDistance(subject, prefix string) bool {
+	i := strings.Index(subject, prefix)
+	if i < 0 {
+		return false
+	}
+	rest := subject[i+len(prefix):]
+	return rest == "" || rest[0] == '.'
+}
@@ -0,0 +1,91 @@
+// Copyright © 2020 Emando B.V.
+
+// Package cluster coordinates instances of the Event Aggregator.
+package cluster
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// errLocked is returned when the lock is held by another instance.
+var errLocked = errors.New("cluster: locked")
+
+// Elector elects the leader among the instances that share a lock file. The leader runs side effects, like webhooks
+// and archiving. The lock is released when the leader stops or exits, so that another instance takes over.
+type Elector struct {
+	logger   *zap.Logger
+	path     string
+	instance string
+	interval time.Duration
+	leader   int32
+}
+
+// NewElector returns a new Elector of the instance that campaigns for the lock file each interval.
+func NewElector(logger *zap.Logger, path, instance string, interval time.Duration) *Elector {
+	return &Elector{
+		logger:   logger.With(zap.String("lock_file", path), zap.String("instance", instance)),
+		path:     path,
+		instance: instance,
+		interval: interval,
+	}
+}
+
+// Instance returns the name of the instance.
+func (e *Elector) Instance() string {
+	return e.instance
+}
+
+// Leader returns whether the instance is the leader.
+func (e *Elector) Leader() bool {
+	return atomic.LoadInt32(&e.leader) == 1
+}
+
+// Run campaigns for leadership until the context is done.
+func (e *Elector) Run(ctx context.Context) {
+	for {
+		f, err := tryLock(e.path)
+		if err == nil {
+			e.lead(ctx, f)
+			return
+		}
+		if !errors.Is(err, errLocked) {
+			e.logger.Warn("failed to lock", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.interval):
+		}
+	}
+}
+
+// lead holds the lock until the context is done. The lock file contains the name of the leader.
+func (e *Elector) lead(ctx context.Context, f *os.File) {
+	defer f.Close()
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(e.instance), 0)
+	}
+	atomic.StoreInt32(&e.leader, 1)
+	e.logger.Info("elected leader")
+	<-ctx.Done()
+	atomic.StoreInt32(&e.leader, 0)
+	unlock(f)
+	e.logger.Info("resigned leader")
+}
+
+// Handle returns a handler that calls h with the JSON encoded event if the instance is the leader. Side effects are at
+// most once, as they are not replayed on failover: events that are handled while no instance holds the lock, from the
+// moment the leader exits until another instance locks the file within the interval, are dropped by all instances.
+func (e *Elector) Handle(h func(buf []byte)) func(buf []byte) {
+	return func(buf []byte) {
+		if e.Leader() {
+			h(buf)
+		}
+	}
+}
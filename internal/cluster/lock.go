@@ -0,0 +1,31 @@
+// Copyright © 2020 Emando B.V.
+
+//go:build !windows
+// +build !windows
+
+package cluster
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLock opens and locks the file without blocking. This function returns errLocked if the file is locked.
+func tryLock(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func unlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
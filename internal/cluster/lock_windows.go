@@ -0,0 +1,17 @@
+// Copyright © 2020 Emando B.V.
+
+package cluster
+
+import (
+	"errors"
+	"os"
+)
+
+// tryLock is not supported on Windows.
+func tryLock(path string) (*os.File, error) {
+	return nil, errors.New("cluster: lock files are not supported on Windows")
+}
+
+func unlock(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright © 2020 Emando B.V.
+
+package follower
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emando/vantage-events/pkg/entities"
+)
+
+// Discipline decides how the heats of a distance are followed.
+type Discipline interface {
+	// Name returns the name of the discipline.
+	Name() string
+	// HeatGroups returns the heat activation groups to subscribe to.
+	HeatGroups(d *entities.Distance) []int
+	// Round returns the name of the round of the heat, i.e. Final, or an empty string if the distance has no rounds.
+	Round(d *entities.Distance, heat *entities.Heat) string
+}
+
+// disciplines are the disciplines in order of precedence. The fallback discipline is used if none matches.
+var disciplines = []struct {
+	match      func(discipline string) bool
+	discipline Discipline
+}{
+	{
+		match:      prefix(entities.LongTrackPairs),
+		discipline: longTrackPairs{},
+	},
+	{
+		match:      equal(entities.LongTrackMassStart),
+		discipline: longTrackMassStart{},
+	},
+	{
+		match:      prefix(shortTrack, inline),
+		discipline: heats{},
+	},
+}
+
+// Discipline prefixes of distances skated in heats with multiple rounds.
+const (
+	shortTrack = "SpeedSkating.ShortTrack"
+	inline     = "InlineSkating"
+)
+
+func prefix(prefixes ...string) func(string) bool {
+	return func(discipline string) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(discipline, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func equal(value string) func(string) bool {
+	return func(discipline string) bool {
+		return discipline == value
+	}
+}
+
+// DisciplineOf returns the discipline of the distance.
+func DisciplineOf(d *entities.Distance) Discipline {
+	for _, entry := range disciplines {
+		if entry.match(d.Discipline) {
+			return entry.discipline
+		}
+	}
+	return fallback{}
+}
+
+// longTrackPairs follows long track distances skated in pairs.
+type longTrackPairs struct{}
+
+func (longTrackPairs) Name() string {
+	return "long track pairs"
+}
+
+// HeatGroups returns both groups of pairs if the start mode is not SingleHeat, as two pairs skate simultaneously.
+func (longTrackPairs) HeatGroups(d *entities.Distance) []int {
+	if d.StartMode != 0 {
+		return []int{0, 1}
+	}
+	return []int{0}
+}
+
+func (longTrackPairs) Round(d *entities.Distance, heat *entities.Heat) string {
+	return numberedRound(d, heat)
+}
+
+// longTrackMassStart follows long track mass start distances.
+type longTrackMassStart struct{}
+
+func (longTrackMassStart) Name() string {
+	return "long track mass start"
+}
+
+// HeatGroups returns a single group, as all competitors skate in a single heat per round regardless of the start mode.
+func (longTrackMassStart) HeatGroups(d *entities.Distance) []int {
+	return []int{0}
+}
+
+func (longTrackMassStart) Round(d *entities.Distance, heat *entities.Heat) string {
+	return knockoutRound(d, heat)
+}
+
+// heats follows distances skated in heats with multiple rounds, as in short track and inline skating.
+type heats struct{}
+
+func (heats) Name() string {
+	return "heats"
+}
+
+// HeatGroups returns a single group, as heats are skated one after the other.
+func (heats) HeatGroups(d *entities.Distance) []int {
+	return []int{0}
+}
+
+func (heats) Round(d *entities.Distance, heat *entities.Heat) string {
+	return knockoutRound(d, heat)
+}
+
+// fallback follows distances of unknown disciplines.
+type fallback struct{}
+
+func (fallback) Name() string {
+	return "fallback"
+}
+
+func (fallback) HeatGroups(d *entities.Distance) []int {
+	return []int{0}
+}
+
+func (fallback) Round(d *entities.Distance, heat *entities.Heat) string {
+	return numberedRound(d, heat)
+}
+
+// numberedRound returns the number of the round if the distance has multiple rounds.
+func numberedRound(d *entities.Distance, heat *entities.Heat) string {
+	if d.Rounds <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("Round %d", heat.Key.Round)
+}
+
+// knockoutRound returns the name of the round counting back from the final, i.e. Semifinal for the round before the
+// final.
+func knockoutRound(d *entities.Distance, heat *entities.Heat) string {
+	if d.Rounds <= 1 {
+		return ""
+	}
+	switch d.Rounds - heat.Key.Round {
+	case 0:
+		return "Final"
+	case 1:
+		return "Semifinal"
+	case 2:
+		return "Quarterfinal"
+	default:
+		return fmt.Sprintf("Round %d", heat.Key.Round)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright © 2020 Emando B.V.
+
+package follower
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emando/vantage-events/pkg/entities"
+)
+
+func TestDisciplineOf(t *testing.T) {
+	for _, tc := range []struct {
+		discipline string
+		startMode  int
+		name       string
+		groups     []int
+	}{
+		{discipline: "SpeedSkating.LongTrack.PairsDistance.Individual", name: "long track pairs", groups: []int{0}},
+		{discipline: "SpeedSkating.LongTrack.PairsDistance.Individual", startMode: 1, name: "long track pairs", groups: []int{0, 1}},
+		{discipline: "SpeedSkating.LongTrack.MassStartDistance", startMode: 1, name: "long track mass start", groups: []int{0}},
+		{discipline: "SpeedSkating.LongTrack.MassStartDistance.Team", name: "fallback", groups: []int{0}},
+		{discipline: "SpeedSkating.ShortTrack.Individual", startMode: 1, name: "heats", groups: []int{0}},
+		{discipline: "InlineSkating.Track", name: "heats", groups: []int{0}},
+		{discipline: "", startMode: 1, name: "fallback", groups: []int{0}},
+	} {
+		t.Run(tc.discipline, func(t *testing.T) {
+			d := &entities.Distance{Discipline: tc.discipline, StartMode: tc.startMode}
+			discipline := DisciplineOf(d)
+			if name := discipline.Name(); name != tc.name {
+				t.Errorf("discipline is %q, want %q", name, tc.name)
+			}
+			if groups := discipline.HeatGroups(d); !reflect.DeepEqual(groups, tc.groups) {
+				t.Errorf("heat groups are %v, want %v", groups, tc.groups)
+			}
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		discipline string
+		rounds     int
+		round      int
+		expected   string
+	}{
+		{name: "PairsSingleRound", discipline: entities.LongTrackPairs + "Individual", rounds: 1, round: 1, expected: ""},
+		{name: "PairsRounds", discipline: entities.LongTrackPairs + "Individual", rounds: 2, round: 2, expected: "Round 2"},
+		{name: "MassStartSingleRound", discipline: entities.LongTrackMassStart, rounds: 1, round: 1, expected: ""},
+		{name: "MassStartFinal", discipline: entities.LongTrackMassStart, rounds: 2, round: 2, expected: "Final"},
+		{name: "MassStartSemifinal", discipline: entities.LongTrackMassStart, rounds: 2, round: 1, expected: "Semifinal"},
+		{name: "ShortTrackQuarterfinal", discipline: shortTrack + ".Individual", rounds: 4, round: 2, expected: "Quarterfinal"},
+		{name: "ShortTrackFirstRound", discipline: shortTrack + ".Individual", rounds: 4, round: 1, expected: "Round 1"},
+		{name: "Fallback", discipline: "Unknown", rounds: 3, round: 3, expected: "Round 3"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &entities.Distance{Discipline: tc.discipline, Rounds: tc.rounds}
+			heat := &entities.Heat{}
+			heat.Key.Round = tc.round
+			if res := DisciplineOf(d).Round(d, heat); res != tc.expected {
+				t.Errorf("round is %q, want %q", res, tc.expected)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/emando/vantage-events/pkg/entities"
 	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
 	"go.uber.org/zap"
 )
 
@@ -16,73 +17,198 @@ import (
 type Follower struct {
 	Logger *zap.Logger
 	Source events.Source
+	// OnLifecycle is called on lifecycle events, including failed subscriptions, if set. It must not block.
+	OnLifecycle func(Lifecycle)
+	// MinBackoff and MaxBackoff are the bounds of the backoff to retry failed subscriptions. The defaults are 1 second
+	// and 1 minute.
+	MinBackoff,
+	MaxBackoff time.Duration
 }
 
-// Run starts the follower.
+// Run starts the follower. The channel is closed when the context is done. Failed subscriptions to competition
+// activations are retried like other subscriptions.
 func (f Follower) Run(ctx context.Context, history time.Duration, ids ...string) (<-chan *CompetitionEvents, error) {
 	activations, err := f.Source.CompetitionActivations(ctx, history)
 	if err != nil {
 		return nil, err
 	}
+	o := &observer{
+		logger:     f.Logger,
+		notify:     f.OnLifecycle,
+		minBackoff: f.MinBackoff,
+		maxBackoff: f.MaxBackoff,
+	}
+	if o.minBackoff <= 0 {
+		o.minBackoff = defaultMinBackoff
+	}
+	if o.maxBackoff < o.minBackoff {
+		o.maxBackoff = defaultMaxBackoff
+	}
+	if r, ok := f.Source.(events.StatusReporter); ok {
+		stop := r.NotifyStatus(func(status events.SourceStatus) {
+			o.observe(f.Logger, Lifecycle{Type: SourceStatusChanged, Source: &status})
+		})
+		go func() {
+			<-ctx.Done()
+			stop()
+		}()
+	}
 	ch := make(chan *CompetitionEvents)
 	go func() {
-		competitions := make(map[string]context.CancelFunc)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case activation := <-activations:
-				logger := f.Logger.With(
-					zap.String("competition_id", activation.CompetitionID),
-					zap.String("competition_name", activation.Value.Name),
-				)
-				if len(ids) > 0 {
-					var found bool
-					for _, id := range ids {
-						if strings.EqualFold(id, activation.CompetitionID) {
-							found = true
-							break
+		competitions := make(map[string]*followed)
+		defer func() {
+			for _, c := range competitions {
+				c.cancel()
+			}
+			close(ch)
+		}()
+		// Competitions are followed with the context of Run, so that they outlive failed activation subscriptions.
+		// Activations that are received again after resubscribing are ignored while the competition is followed.
+		runCtx := ctx
+		followActivations := func(ctx context.Context) error {
+			if activations == nil {
+				var err error
+				if activations, err = f.Source.CompetitionActivations(ctx, history); err != nil {
+					return err
+				}
+			}
+			defer func() {
+				activations = nil
+			}()
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case activation, ok := <-activations:
+					if !ok {
+						return events.ErrClosed
+					}
+					logger := f.Logger.With(
+						zap.String("competition_id", activation.CompetitionID),
+						zap.String("competition_name", activation.Value.Name),
+					)
+					if len(ids) > 0 {
+						var found bool
+						for _, id := range ids {
+							if strings.EqualFold(id, activation.CompetitionID) {
+								found = true
+								break
+							}
+						}
+						if !found {
+							logger.Debug("ignoring competition")
+							continue
 						}
 					}
-					if !found {
-						logger.Debug("ignoring competition")
-						continue
+					base := Lifecycle{CompetitionID: activation.CompetitionID}
+					if c, ok := competitions[activation.CompetitionID]; ok {
+						if c.since.Equal(activation.Time) && !c.ended() {
+							logger.Debug("competition already followed")
+							continue
+						}
+						if c.supersede() {
+							o.observe(logger, with(base, CompetitionSuperseded))
+						}
 					}
-				}
-				if cancel, ok := competitions[activation.CompetitionID]; ok {
-					cancel()
-				}
-				ctx, cancel := context.WithCancel(ctx)
-				defer cancel()
-				competitions[activation.CompetitionID] = cancel
-				ev := &CompetitionEvents{
-					source:         f.Source,
-					logger:         logger,
-					activation:     activation,
-					Competition:    &activation.Value,
-					DistanceEvents: make(chan *DistanceEvents),
-					RawActivation:  activation.Raw,
-					RawEvents:      make(chan []byte),
-				}
-				ch <- ev
-				go func() {
-					defer close(ev.DistanceEvents)
-					defer close(ev.RawEvents)
-					if err := ev.follow(ctx); err != nil && err != context.Canceled {
-						logger.Error("failed to follow competition", zap.Error(err))
+					competitionCtx, c := follow(runCtx, activation.Time)
+					competitions[activation.CompetitionID] = c
+					ev := &CompetitionEvents{
+						source:         f.Source,
+						logger:         logger,
+						observer:       o,
+						activation:     activation,
+						replay:         newReplay(true),
+						distances:      make(map[string]*followed),
+						Competition:    &activation.Value,
+						DistanceEvents: make(chan *DistanceEvents),
+						RawActivation:  activation.Raw,
+						RawEvents:      make(chan []byte),
+					}
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case ch <- ev:
 					}
-				}()
+					o.observe(logger, with(base, CompetitionFollowed))
+					go func() {
+						defer close(c.done)
+						defer c.cancel()
+						defer close(ev.DistanceEvents)
+						defer close(ev.RawEvents)
+						o.supervise(competitionCtx, logger, base, func(attemptCtx context.Context) error {
+							return ev.follow(attemptCtx, competitionCtx)
+						})
+					}()
+				}
 			}
 		}
+		o.supervise(ctx, f.Logger, Lifecycle{}, followActivations)
 	}()
 	return ch, nil
 }
 
+// followed is a competition, distance or heat that is followed until it is superseded.
+type followed struct {
+	since  time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// follow returns the context to follow a competition, distance or heat since the activation time.
+func follow(ctx context.Context, since time.Time) (context.Context, *followed) {
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, &followed{
+		since:  since,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// ended returns whether following has ended.
+func (f *followed) ended() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// supersede stops following. This method returns false if following had already ended.
+func (f *followed) supersede() bool {
+	if f.ended() {
+		return false
+	}
+	f.cancel()
+	return true
+}
+
+// with returns the lifecycle event with the type.
+func with(l Lifecycle, typ LifecycleType) Lifecycle {
+	l.Type = typ
+	return l
+}
+
+// send sends the event to the channel. This function returns false if the context is done.
+func send(ctx context.Context, ch chan<- []byte, buf []byte) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- buf:
+		return true
+	}
+}
+
 // CompetitionEvents provides Vantage events from a competition.
 type CompetitionEvents struct {
 	source     events.Source
 	logger     *zap.Logger
+	observer   *observer
 	activation *events.CompetitionActivated
+	replay     *replay
+	// after is the sequence of the last delivered event, to resume failed subscriptions.
+	after     uint64
+	distances map[string]*followed
 
 	Competition    *entities.Competition
 	DistanceEvents chan *DistanceEvents
@@ -91,43 +217,96 @@ type CompetitionEvents struct {
 	RawEvents     chan []byte
 }
 
-func (c *CompetitionEvents) follow(ctx context.Context) error {
-	rawEvents, err := c.source.CompetitionEvents(ctx, c.activation)
+// Live is closed when the replayed competition events have been delivered on RawEvents, and the distances of the
+// replayed distance activations on DistanceEvents.
+func (c *CompetitionEvents) Live() <-chan struct{} {
+	return c.replay.live
+}
+
+// follow follows the competition until the context is done. Distances are followed with followCtx, so that they
+// outlive failed subscriptions. Distance activations that are received again after resubscribing are ignored.
+func (c *CompetitionEvents) follow(ctx, followCtx context.Context) error {
+	sub, err := c.source.CompetitionEvents(ctx, c.activation, c.after)
 	if err != nil {
 		return err
 	}
+	c.replay.subscribed(sub, c.after)
 	activations, err := c.source.DistanceActivations(ctx, c.Competition.ID)
 	if err != nil {
 		return err
 	}
+	replayed := activations.Replayed
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case rawEvent := <-rawEvents:
+		case <-replayed:
+			// The replayed activations were received, and their distances delivered, in earlier iterations.
+			c.replay.activated()
+			replayed = nil
+		case rawEvent, ok := <-sub.Events:
+			if !ok {
+				return events.ErrClosed
+			}
 			c.logger.With(zap.String("type", rawEvent.Type)).Debug("received competition event")
-			c.RawEvents <- rawEvent.Bytes
-		case activation := <-activations:
+			c.replay.received(sub, rawEvent)
+			if !send(ctx, c.RawEvents, rawEvent.Bytes) {
+				return ctx.Err()
+			}
+			c.after = rawEvent.Sequence
+			c.replay.delivered(sub, rawEvent)
+		case activation, ok := <-activations.Activations:
+			if !ok {
+				return events.ErrClosed
+			}
+			logger := c.logger.With(
+				zap.String("distance_id", activation.DistanceID),
+				zap.String("distance_name", activation.Value.Name),
+				zap.String("discipline", activation.Value.Discipline),
+			)
+			base := Lifecycle{
+				CompetitionID: c.Competition.ID,
+				DistanceID:    activation.DistanceID,
+			}
+			if d, ok := c.distances[activation.DistanceID]; ok {
+				if d.since.Equal(activation.Time) {
+					logger.Debug("distance already followed")
+					continue
+				}
+				if d.supersede() {
+					c.observer.observe(logger, with(base, DistanceSuperseded))
+				}
+			}
+			distanceCtx, d := follow(followCtx, activation.Time)
+			c.distances[activation.DistanceID] = d
 			ev := &DistanceEvents{
-				source: c.source,
-				logger: c.logger.With(
-					zap.String("distance_id", activation.DistanceID),
-					zap.String("distance_name", activation.Value.Name),
-				),
+				source:        c.source,
+				logger:        logger,
+				observer:      c.observer,
 				activation:    activation,
+				replay:        newReplay(true),
+				heats:         make(map[state.HeatKey]*followed),
 				Competition:   c.Competition,
 				Distance:      &activation.Value,
+				Discipline:    DisciplineOf(&activation.Value),
 				HeatEvents:    make(chan *HeatEvents),
 				RawActivation: activation.Raw,
 				RawEvents:     make(chan []byte),
 			}
-			c.DistanceEvents <- ev
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case c.DistanceEvents <- ev:
+			}
+			c.observer.observe(logger, with(base, DistanceFollowed))
 			go func() {
+				defer close(d.done)
+				defer d.cancel()
 				defer close(ev.HeatEvents)
 				defer close(ev.RawEvents)
-				if err := ev.follow(ctx); err != nil && err != context.Canceled {
-					c.logger.Error("failed to follow distance", zap.Error(err))
-				}
+				c.observer.supervise(distanceCtx, logger, base, func(attemptCtx context.Context) error {
+					return ev.follow(attemptCtx, distanceCtx)
+				})
 			}()
 		}
 	}
@@ -137,65 +316,121 @@ func (c *CompetitionEvents) follow(ctx context.Context) error {
 type DistanceEvents struct {
 	source     events.Source
 	logger     *zap.Logger
+	observer   *observer
 	activation *events.DistanceActivated
+	replay     *replay
+	// after is the sequence of the last delivered event, to resume failed subscriptions.
+	after uint64
+	heats map[state.HeatKey]*followed
 
 	Competition *entities.Competition
 	Distance    *entities.Distance
+	Discipline  Discipline
 	HeatEvents  chan *HeatEvents
 
 	RawActivation []byte
 	RawEvents     chan []byte
 }
 
-func (d *DistanceEvents) follow(ctx context.Context) error {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	rawEvents, err := d.source.DistanceEvents(ctx, d.activation)
+// Live is closed when the replayed distance events have been delivered on RawEvents, and the heats of the replayed
+// heat activations on HeatEvents.
+func (d *DistanceEvents) Live() <-chan struct{} {
+	return d.replay.live
+}
+
+// follow follows the distance until it is deactivated. Heats are followed with followCtx, so that they outlive failed
+// subscriptions. Heat activations that are received again after resubscribing are ignored.
+func (d *DistanceEvents) follow(ctx, followCtx context.Context) error {
+	sub, err := d.source.DistanceEvents(ctx, d.activation, d.after)
 	if err != nil {
 		return err
 	}
-	groups := make([]int, 1, 2)
-	switch {
-	case strings.HasPrefix(d.Distance.Discipline, "SpeedSkating.LongTrack.PairsDistance."):
-		// Subscribe to both pairs if the start mode is not SingleHeat.
-		if d.Distance.StartMode != 0 {
-			groups = append(groups, 1)
-		}
-	}
+	d.replay.subscribed(sub, d.after)
+	groups := d.Discipline.HeatGroups(d.Distance)
 	activations, err := d.source.HeatActivations(ctx, d.Competition.ID, d.Distance.ID, groups...)
 	if err != nil {
 		return err
 	}
+	replayed := activations.Replayed
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case rawEvent := <-rawEvents:
+		case <-replayed:
+			// The replayed activations were received, and their heats delivered, in earlier iterations.
+			d.replay.activated()
+			replayed = nil
+		case rawEvent, ok := <-sub.Events:
+			if !ok {
+				return events.ErrClosed
+			}
 			d.logger.With(zap.String("type", rawEvent.Type)).Debug("received distance event")
-			d.RawEvents <- rawEvent.Bytes
+			d.replay.received(sub, rawEvent)
+			if !send(ctx, d.RawEvents, rawEvent.Bytes) {
+				return ctx.Err()
+			}
+			d.after = rawEvent.Sequence
+			d.replay.delivered(sub, rawEvent)
 			if rawEvent.TypeName() == events.DistanceDeactivatedType {
-				cancel()
+				d.observer.observe(d.logger, Lifecycle{
+					Type:          DistanceEnded,
+					CompetitionID: d.Competition.ID,
+					DistanceID:    d.Distance.ID,
+				})
+				return nil
+			}
+		case activation, ok := <-activations.Activations:
+			if !ok {
+				return events.ErrClosed
+			}
+			round := d.Discipline.Round(d.Distance, &activation.Heat.Heat)
+			logger := d.logger.With(
+				zap.Int("heat_round", activation.Key.Round),
+				zap.Int("heat_number", activation.Key.Number),
+				zap.String("round_name", round),
+			)
+			key := state.KeyOf(activation.Heat.Heat)
+			base := Lifecycle{
+				CompetitionID: d.Competition.ID,
+				DistanceID:    d.Distance.ID,
+				HeatRound:     key.Round,
+				HeatNumber:    key.Number,
 			}
-		case activation := <-activations:
+			if h, ok := d.heats[key]; ok {
+				if h.since.Equal(activation.Time) {
+					logger.Debug("heat already followed")
+					continue
+				}
+				if h.supersede() {
+					d.observer.observe(logger, with(base, HeatSuperseded))
+				}
+			}
+			heatCtx, h := follow(followCtx, activation.Time)
+			d.heats[key] = h
 			ev := &HeatEvents{
-				source: d.source,
-				logger: d.logger.With(
-					zap.Int("heat_round", activation.Key.Round),
-					zap.Int("heat_number", activation.Key.Number),
-				),
+				source:        d.source,
+				logger:        logger,
+				observer:      d.observer,
 				activation:    activation,
+				replay:        newReplay(false),
 				Competition:   d.Competition,
 				Distance:      d.Distance,
 				Heat:          &activation.Heat.Heat,
+				Round:         round,
 				RawActivation: activation.Raw,
 				RawEvents:     make(chan []byte),
 			}
-			d.HeatEvents <- ev
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case d.HeatEvents <- ev:
+			}
+			d.observer.observe(logger, with(base, HeatFollowed))
 			go func() {
+				defer close(h.done)
+				defer h.cancel()
 				defer close(ev.RawEvents)
-				if ev.follow(ctx); err != nil && err != context.Canceled {
-					d.logger.Error("failed to follow heat", zap.Error(err))
-				}
+				d.observer.supervise(heatCtx, logger, base, ev.follow)
 			}()
 		}
 	}
@@ -205,32 +440,58 @@ func (d *DistanceEvents) follow(ctx context.Context) error {
 type HeatEvents struct {
 	source     events.Source
 	logger     *zap.Logger
+	observer   *observer
 	activation *events.HeatActivated
+	replay     *replay
+	// after is the sequence of the last delivered event, to resume failed subscriptions.
+	after uint64
 
 	Competition *entities.Competition
 	Distance    *entities.Distance
 	Heat        *entities.Heat
+	// Round is the name of the round of the heat, i.e. Final, or an empty string if the distance has no rounds.
+	Round string
 
 	RawActivation []byte
 	RawEvents     chan []byte
 }
 
+// Live is closed when the replayed heat events have been delivered on RawEvents.
+func (h *HeatEvents) Live() <-chan struct{} {
+	return h.replay.live
+}
+
+// follow follows the heat until it is deactivated.
 func (h *HeatEvents) follow(ctx context.Context) error {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	rawEvents, err := h.source.HeatEvents(ctx, h.activation)
+	sub, err := h.source.HeatEvents(ctx, h.activation, h.after)
 	if err != nil {
 		return err
 	}
+	h.replay.subscribed(sub, h.after)
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case rawEvent := <-rawEvents:
+		case rawEvent, ok := <-sub.Events:
+			if !ok {
+				return events.ErrClosed
+			}
 			h.logger.With(zap.String("type", rawEvent.Type)).Debug("received heat event")
-			h.RawEvents <- rawEvent.Bytes
+			h.replay.received(sub, rawEvent)
+			if !send(ctx, h.RawEvents, rawEvent.Bytes) {
+				return ctx.Err()
+			}
+			h.after = rawEvent.Sequence
+			h.replay.delivered(sub, rawEvent)
 			if rawEvent.TypeName() == events.HeatDeactivatedType {
-				cancel()
+				h.observer.observe(h.logger, Lifecycle{
+					Type:          HeatEnded,
+					CompetitionID: h.Competition.ID,
+					DistanceID:    h.Distance.ID,
+					HeatRound:     h.Heat.Key.Round,
+					HeatNumber:    h.Heat.Key.Number,
+				})
+				return nil
 			}
 		}
 	}
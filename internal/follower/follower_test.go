@@ -0,0 +1,371 @@
+// Copyright © 2020 Emando B.V.
+
+package follower
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+	"go.uber.org/zap"
+)
+
+// failingSource is a source of one competition with one distance, unless distance is nil. The first subscription to
+// competition events fails after delivering failAfter events, unless failAfter is negative. The last replayed event
+// has sequence last.
+type failingSource struct {
+	competition *events.CompetitionActivated
+	distance    *events.DistanceActivated
+	events      []*events.Raw
+	last        uint64
+	failAfter   int
+
+	// replayedDistanceEvents is the number of replayed distance events.
+	replayedDistanceEvents int
+
+	mu             sync.Mutex
+	after          []uint64
+	distanceEvents int
+}
+
+func (s *failingSource) CompetitionActivations(ctx context.Context, history time.Duration) (<-chan *events.CompetitionActivated, error) {
+	ch := make(chan *events.CompetitionActivated, 1)
+	ch <- s.competition
+	return ch, nil
+}
+
+func (s *failingSource) CompetitionEvents(ctx context.Context, since *events.CompetitionActivated, after uint64) (*events.Replay, error) {
+	s.mu.Lock()
+	s.after = append(s.after, after)
+	fail := len(s.after) == 1
+	s.mu.Unlock()
+	ch := make(chan *events.Raw)
+	go func() {
+		var n int
+		for _, event := range s.events {
+			if event.Sequence <= after {
+				continue
+			}
+			if fail && n == s.failAfter {
+				close(ch)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- event:
+				n++
+			}
+		}
+	}()
+	return &events.Replay{Events: ch, Last: s.last}, nil
+}
+
+func (s *failingSource) DistanceActivations(ctx context.Context, competitionID string) (*events.DistanceActivations, error) {
+	// The last activation is delivered on each subscription.
+	ch, replayed := make(chan *events.DistanceActivated), make(chan struct{})
+	if s.distance == nil {
+		close(replayed)
+		return &events.DistanceActivations{Activations: ch, Replayed: replayed}, nil
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+		case ch <- s.distance:
+			close(replayed)
+		}
+	}()
+	return &events.DistanceActivations{Activations: ch, Replayed: replayed}, nil
+}
+
+func (s *failingSource) DistanceEvents(ctx context.Context, since *events.DistanceActivated, after uint64) (*events.Replay, error) {
+	s.mu.Lock()
+	s.distanceEvents++
+	s.mu.Unlock()
+	// The distance replays replayedDistanceEvents events.
+	ch := make(chan *events.Raw)
+	go func() {
+		for i := 1; i <= s.replayedDistanceEvents; i++ {
+			event := &events.Raw{
+				Bytes:    []byte(fmt.Sprintf(`{"typeName":"DistanceUpdatedEvent","competitionId":"c1","distanceId":"d1","number":%d}`, i)),
+				Sequence: uint64(i),
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- event:
+			}
+		}
+	}()
+	return &events.Replay{Events: ch, Last: uint64(s.replayedDistanceEvents)}, nil
+}
+
+func (s *failingSource) HeatActivations(ctx context.Context, competitionID, distanceID string, groups ...int) (*events.HeatActivations, error) {
+	replayed := make(chan struct{})
+	close(replayed)
+	return &events.HeatActivations{Activations: make(chan *events.HeatActivated), Replayed: replayed}, nil
+}
+
+func (s *failingSource) HeatEvents(ctx context.Context, since *events.HeatActivated, after uint64) (*events.Replay, error) {
+	return &events.Replay{Events: make(chan *events.Raw)}, nil
+}
+
+// newFailingSource returns a source with n competition events.
+func newFailingSource(n int, last uint64, failAfter int) *failingSource {
+	activated := time.Now()
+	source := &failingSource{
+		competition: &events.CompetitionActivated{
+			Competition: events.Competition{CompetitionID: "c1"},
+			Value:       entities.Competition{ID: "c1"},
+			Time:        activated,
+			Raw:         []byte(`{"typeName":"CompetitionActivatedEvent","competitionId":"c1"}`),
+		},
+		distance: &events.DistanceActivated{
+			Distance: events.Distance{
+				Competition: events.Competition{CompetitionID: "c1"},
+				DistanceID:  "d1",
+			},
+			Value: entities.Distance{ID: "d1"},
+			Time:  activated,
+			Raw:   []byte(`{"typeName":"DistanceActivatedEvent","competitionId":"c1","distanceId":"d1"}`),
+		},
+		last:      last,
+		failAfter: failAfter,
+	}
+	for i := 1; i <= n; i++ {
+		source.events = append(source.events, &events.Raw{
+			Bytes:    []byte{byte(i)},
+			Time:     activated,
+			Sequence: uint64(i),
+		})
+	}
+	return source
+}
+
+// followCompetition follows the competition of the source and drains the distances.
+func followCompetition(ctx context.Context, t *testing.T, source events.Source) *CompetitionEvents {
+	t.Helper()
+	competition := <-run(ctx, t, source)
+	go func() {
+		for range competition.DistanceEvents {
+		}
+	}()
+	return competition
+}
+
+// run runs a follower of the source.
+func run(ctx context.Context, t *testing.T, source events.Source) <-chan *CompetitionEvents {
+	t.Helper()
+	f := Follower{
+		Logger:     zap.NewNop(),
+		Source:     source,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+	}
+	competitions, err := f.Run(ctx, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return competitions
+}
+
+// isLive returns whether the replay is delivered within a short time.
+func isLive(live <-chan struct{}) bool {
+	select {
+	case <-live:
+		return true
+	case <-time.After(50 * time.Millisecond):
+		return false
+	}
+}
+
+func TestFollowerResume(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		events    int
+		failAfter int
+	}{
+		{name: "FailImmediately", events: 3, failAfter: 0},
+		{name: "FailAfterOne", events: 3, failAfter: 1},
+		{name: "FailAfterTwo", events: 5, failAfter: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			source := newFailingSource(tc.events, uint64(tc.events), tc.failAfter)
+			competition := followCompetition(ctx, t, source)
+			for i := 1; i <= tc.events; i++ {
+				select {
+				case <-ctx.Done():
+					t.Fatalf("received %d of %d events", i-1, tc.events)
+				case buf := <-competition.RawEvents:
+					if len(buf) != 1 || int(buf[0]) != i {
+						t.Fatalf("event %d is %v", i, buf)
+					}
+				}
+			}
+			select {
+			case buf := <-competition.RawEvents:
+				t.Fatalf("received duplicate event %v", buf)
+			case <-time.After(50 * time.Millisecond):
+			}
+			if !isLive(competition.Live()) {
+				t.Error("replay not delivered")
+			}
+
+			source.mu.Lock()
+			defer source.mu.Unlock()
+			if len(source.after) != 2 || source.after[0] != 0 || source.after[1] != uint64(tc.failAfter) {
+				t.Errorf("subscriptions resumed after %v, want [0 %d]", source.after, tc.failAfter)
+			}
+			if source.distanceEvents != 1 {
+				t.Errorf("distance followed %d times, want 1", source.distanceEvents)
+			}
+		})
+	}
+}
+
+func TestFollowerLive(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		events    int
+		last      uint64
+		failAfter int
+	}{
+		{name: "NoReplay", events: 3, last: 0, failAfter: -1},
+		{name: "ReplayOne", events: 3, last: 1, failAfter: -1},
+		{name: "ReplayAll", events: 3, last: 3, failAfter: -1},
+		{name: "ReplayAfterFailure", events: 4, last: 3, failAfter: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			// Without distances, the end of the replay depends on the competition events only.
+			source := newFailingSource(tc.events, tc.last, tc.failAfter)
+			source.distance = nil
+			competition := followCompetition(ctx, t, source)
+			if live := isLive(competition.Live()); live != (tc.last == 0) {
+				t.Fatalf("live before events is %v", live)
+			}
+			for i := 1; i <= tc.events; i++ {
+				select {
+				case <-ctx.Done():
+					t.Fatalf("received %d of %d events", i-1, tc.events)
+				case <-competition.RawEvents:
+				}
+				if live := isLive(competition.Live()); live != (uint64(i) >= tc.last) {
+					t.Fatalf("live after event %d is %v", i, live)
+				}
+			}
+		})
+	}
+}
+
+func TestFollowerLiveAfterActivations(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		events int
+	}{
+		{name: "EmptyCompetition", events: 0},
+		{name: "ReplayedCompetition", events: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			competition := <-run(ctx, t, newFailingSource(tc.events, uint64(tc.events), -1))
+			var (
+				received int
+				distance *DistanceEvents
+			)
+			for received < tc.events || distance == nil {
+				if distance == nil && isLive(competition.Live()) {
+					t.Fatal("live before the replayed distance is delivered")
+				}
+				select {
+				case <-ctx.Done():
+					t.Fatalf("received %d events and distance %v", received, distance != nil)
+				case <-competition.RawEvents:
+					received++
+				case distance = <-competition.DistanceEvents:
+				}
+			}
+			if !isLive(competition.Live()) {
+				t.Error("replay not delivered")
+			}
+		})
+	}
+}
+
+func TestRawReplays(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		distanceEvents int
+	}{
+		// The competition has no events, so the end of the replay depends on the replayed distance activation.
+		{name: "EmptyCompetition"},
+		// The StreamLiveEvent of the distance follows the last replayed distance event.
+		{name: "DistanceEvents", distanceEvents: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			source := newFailingSource(0, 0, -1)
+			source.replayedDistanceEvents = tc.distanceEvents
+			raw := Raw(ctx, run(ctx, t, source))
+			var (
+				replays  Replays
+				received []string
+			)
+			for !replays.Delivered() {
+				select {
+				case <-ctx.Done():
+					t.Fatalf("replay not delivered, received %v", received)
+				case buf := <-raw:
+					if err := replays.Update(buf); err != nil {
+						t.Fatal(err)
+					}
+					var header struct {
+						events.Base
+						Level string `json:"level"`
+					}
+					if err := json.Unmarshal(buf, &header); err != nil {
+						t.Fatal(err)
+					}
+					received = append(received, header.TypeName()+header.Level)
+				}
+			}
+			// Events of the competition and of the distance are interleaved.
+			index := func(typeName string) int {
+				for i, r := range received {
+					if r == typeName {
+						return i
+					}
+				}
+				t.Fatalf("%s not received: %v", typeName, received)
+				return -1
+			}
+			if len(received) != 4+tc.distanceEvents {
+				t.Fatalf("received %v", received)
+			}
+			competitionLive := index(events.StreamLiveType + events.CompetitionLevel)
+			distanceLive := index(events.StreamLiveType + events.DistanceLevel)
+			if index(events.CompetitionActivatedType) != 0 || index(events.DistanceActivatedType) > competitionLive {
+				t.Errorf("activations do not precede the StreamLiveEvent of the competition: %v", received)
+			}
+			for i, r := range received {
+				if r == "DistanceUpdatedEvent" && i > distanceLive {
+					t.Errorf("replayed distance event follows the StreamLiveEvent of the distance: %v", received)
+				}
+			}
+		})
+	}
+}
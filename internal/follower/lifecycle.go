@@ -0,0 +1,119 @@
+// Copyright © 2020 Emando B.V.
+
+package follower
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/events"
+	"go.uber.org/zap"
+)
+
+// LifecycleType is the type of a lifecycle event.
+type LifecycleType string
+
+// Lifecycle event types.
+const (
+	CompetitionFollowed   LifecycleType = "CompetitionFollowed"
+	CompetitionSuperseded LifecycleType = "CompetitionSuperseded"
+	DistanceFollowed      LifecycleType = "DistanceFollowed"
+	DistanceSuperseded    LifecycleType = "DistanceSuperseded"
+	DistanceEnded         LifecycleType = "DistanceEnded"
+	HeatFollowed          LifecycleType = "HeatFollowed"
+	HeatSuperseded        LifecycleType = "HeatSuperseded"
+	HeatEnded             LifecycleType = "HeatEnded"
+	// SubscriptionFailed is a failed subscription that is retried after the backoff.
+	SubscriptionFailed LifecycleType = "SubscriptionFailed"
+	// SourceStatusChanged is a change of the connection status of the source.
+	SourceStatusChanged LifecycleType = "SourceStatusChanged"
+)
+
+// Lifecycle is a lifecycle event of the follower.
+type Lifecycle struct {
+	Time          time.Time     `json:"time"`
+	Type          LifecycleType `json:"type"`
+	CompetitionID string        `json:"competitionId"`
+	DistanceID    string        `json:"distanceId,omitempty"`
+	HeatRound     int           `json:"heatRound,omitempty"`
+	HeatNumber    int           `json:"heatNumber,omitempty"`
+	// Error, Attempt and Backoff are set for failed subscriptions.
+	Error   string        `json:"error,omitempty"`
+	Attempt int           `json:"attempt,omitempty"`
+	Backoff time.Duration `json:"backoff,omitempty"`
+	// Source is set for changes of the connection status of the source.
+	Source *events.SourceStatus `json:"source,omitempty"`
+}
+
+const (
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+// observer logs lifecycle events and notifies the callback of the follower.
+type observer struct {
+	logger     *zap.Logger
+	notify     func(Lifecycle)
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+func (o *observer) observe(logger *zap.Logger, l Lifecycle) {
+	l.Time = time.Now()
+	fields := []zap.Field{zap.String("type", string(l.Type))}
+	switch l.Type {
+	case SourceStatusChanged:
+		fields = append(fields,
+			zap.String("state", string(l.Source.State)),
+			zap.String("last_error", l.Source.LastError),
+		)
+		logger.Info("source status changed", fields...)
+	case SubscriptionFailed:
+		fields = append(fields,
+			zap.String("error", l.Error),
+			zap.Int("attempt", l.Attempt),
+			zap.Duration("backoff", l.Backoff),
+		)
+		logger.Warn("subscription failed", fields...)
+	default:
+		logger.Debug("lifecycle event", fields...)
+	}
+	if o.notify != nil {
+		o.notify(l)
+	}
+}
+
+// supervise calls follow until it returns without error or the context is done. Failed subscriptions are retried
+// with jittered exponential backoff. Each attempt has its own context, so that subscriptions of a failed attempt are
+// closed. An attempt that lasted at least the maximum backoff was healthy, so the backoff and attempts start over.
+func (o *observer) supervise(ctx context.Context, logger *zap.Logger, base Lifecycle, follow func(context.Context) error) {
+	backoff := o.minBackoff
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		started := time.Now()
+		err := follow(attemptCtx)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) >= o.maxBackoff {
+			backoff, attempt = o.minBackoff, 1
+		}
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		l := base
+		l.Type = SubscriptionFailed
+		l.Error = err.Error()
+		l.Attempt = attempt
+		l.Backoff = wait
+		o.observe(logger, l)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if backoff *= 2; backoff > o.maxBackoff {
+			backoff = o.maxBackoff
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright © 2020 Emando B.V.
+
+package follower
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSupervise(t *testing.T) {
+	const (
+		minBackoff = time.Millisecond
+		maxBackoff = 50 * time.Millisecond
+	)
+	for _, tc := range []struct {
+		name string
+		// durations are the durations of the failing attempts before the last attempt succeeds.
+		durations []time.Duration
+		attempts  []int
+	}{
+		{name: "Failing", durations: []time.Duration{0, 0, 0}, attempts: []int{1, 2, 3}},
+		{name: "Healthy", durations: []time.Duration{0, 0, maxBackoff, 0}, attempts: []int{1, 2, 1, 2}},
+		{name: "HealthyFirst", durations: []time.Duration{maxBackoff, 0}, attempts: []int{1, 2}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts []int
+			var backoffs []time.Duration
+			o := &observer{
+				notify: func(l Lifecycle) {
+					attempts = append(attempts, l.Attempt)
+					backoffs = append(backoffs, l.Backoff)
+				},
+				minBackoff: minBackoff,
+				maxBackoff: maxBackoff,
+			}
+			var i int
+			o.supervise(context.Background(), zap.NewNop(), Lifecycle{}, func(ctx context.Context) error {
+				if i == len(tc.durations) {
+					return nil
+				}
+				time.Sleep(tc.durations[i])
+				i++
+				return errors.New("failed")
+			})
+			if !reflect.DeepEqual(attempts, tc.attempts) {
+				t.Errorf("attempts are %v, want %v", attempts, tc.attempts)
+			}
+			for j, attempt := range attempts {
+				if attempt == 1 && backoffs[j] > minBackoff {
+					t.Errorf("backoff of attempt %d is %v, want at most %v", j+1, backoffs[j], minBackoff)
+				}
+			}
+		})
+	}
+}
+
+func TestSuperviseCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	o := &observer{minBackoff: time.Hour, maxBackoff: time.Hour}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		o.supervise(ctx, zap.NewNop(), Lifecycle{}, func(ctx context.Context) error {
+			return errors.New("failed")
+		})
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("supervise did not return after the context is done")
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright © 2020 Emando B.V.
+
+package follower
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/emando/vantage-events/pkg/events"
+)
+
+// Raw returns the raw events of the competitions, including the activations of the competitions, distances and heats.
+// A StreamLiveEvent follows the replayed events of each competition, distance and heat.
+func Raw(ctx context.Context, competitions <-chan *CompetitionEvents) <-chan []byte {
+	ch := make(chan []byte)
+	send := func(buf []byte) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case ch <- buf:
+			return true
+		}
+	}
+	// The activations of distances and heats are sent by the goroutine of the competition or distance, before its
+	// StreamLiveEvent, so that the replays of the distances and heats are tracked before the end of the replay.
+	followHeat := func(heat *HeatEvents) {
+		b := newBoundary(heat.replay, liveMarker(events.HeatLevel, heat.Competition.ID, heat.Distance.ID,
+			&events.StreamLiveHeat{Round: heat.Heat.Key.Round, Number: heat.Heat.Key.Number},
+		))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.live:
+				if !b.mark(send) {
+					return
+				}
+			case event, ok := <-heat.RawEvents:
+				if !ok || !b.deliver(send, event) {
+					return
+				}
+			}
+		}
+	}
+	followDistance := func(distance *DistanceEvents) {
+		b := newBoundary(distance.replay, liveMarker(events.DistanceLevel, distance.Competition.ID, distance.Distance.ID, nil))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.live:
+				if !b.mark(send) {
+					return
+				}
+			case event, ok := <-distance.RawEvents:
+				if !ok || !b.deliver(send, event) {
+					return
+				}
+			case heat, ok := <-distance.HeatEvents:
+				if !ok || !send(heat.RawActivation) {
+					return
+				}
+				go followHeat(heat)
+			}
+		}
+	}
+	followCompetition := func(competition *CompetitionEvents) {
+		if !send(competition.RawActivation) {
+			return
+		}
+		b := newBoundary(competition.replay, liveMarker(events.CompetitionLevel, competition.Competition.ID, "", nil))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.live:
+				if !b.mark(send) {
+					return
+				}
+			case event, ok := <-competition.RawEvents:
+				if !ok || !b.deliver(send, event) {
+					return
+				}
+			case distance, ok := <-competition.DistanceEvents:
+				if !ok || !send(distance.RawActivation) {
+					return
+				}
+				go followDistance(distance)
+			}
+		}
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case competition, ok := <-competitions:
+				if !ok {
+					return
+				}
+				go followCompetition(competition)
+			}
+		}
+	}()
+	return ch
+}
+
+// boundary sends the StreamLiveEvent of a replay between the replayed and the live events.
+type boundary struct {
+	replay *replay
+	// live is the channel of the replay until it is closed, and nil after.
+	live   <-chan struct{}
+	marker []byte
+	// delivered is the number of delivered events, and after the number of events that precede the marker.
+	delivered,
+	after int
+	sent bool
+}
+
+func newBoundary(r *replay, marker []byte) *boundary {
+	return &boundary{
+		replay: r,
+		live:   r.live,
+		marker: marker,
+	}
+}
+
+// mark sends the marker if the replay was delivered and the replayed events are sent. This function returns false if
+// sending fails.
+func (b *boundary) mark(send func([]byte) bool) bool {
+	if b.live != nil {
+		select {
+		case <-b.live:
+			b.live = nil
+			b.after = b.replay.liveAfter()
+		default:
+			return true
+		}
+	}
+	if b.sent || b.delivered < b.after {
+		return true
+	}
+	b.sent = true
+	return send(b.marker)
+}
+
+// deliver sends the event, preceded or followed by the marker if the event is the first live or the last replayed
+// event. This function returns false if sending fails.
+func (b *boundary) deliver(send func([]byte) bool, event []byte) bool {
+	if !b.mark(send) || !send(event) {
+		return false
+	}
+	b.delivered++
+	return b.mark(send)
+}
+
+// liveMarker returns the JSON encoded StreamLiveEvent.
+func liveMarker(level, competitionID, distanceID string, heat *events.StreamLiveHeat) []byte {
+	event := events.StreamLive{
+		Level:      level,
+		DistanceID: distanceID,
+		Heat:       heat,
+	}
+	event.Type = events.StreamLiveType
+	event.CompetitionID = competitionID
+	buf, _ := json.Marshal(event)
+	return buf
+}
+
+// Replays tracks the replays in the raw events of a competition. The replay of the raw events is delivered when the
+// replays of the competition and of all distances and heats that were activated during the replay are delivered.
+type Replays struct {
+	competitionID string
+	pending       map[string]struct{}
+}
+
+// Update tracks the replays with the raw event.
+func (r *Replays) Update(buf []byte) error {
+	var header struct {
+		events.Heat
+		Level string `json:"level"`
+	}
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return err
+	}
+	if r.pending == nil {
+		r.pending = make(map[string]struct{})
+	}
+	key := func(level string) string {
+		switch level {
+		case events.CompetitionLevel:
+			return header.CompetitionID
+		case events.DistanceLevel:
+			return fmt.Sprintf("%s/%s", header.CompetitionID, header.DistanceID)
+		default:
+			return fmt.Sprintf("%s/%s/%d/%d", header.CompetitionID, header.DistanceID, header.Key.Round, header.Key.Number)
+		}
+	}
+	switch header.TypeName() {
+	case events.CompetitionActivatedType:
+		r.competitionID = header.CompetitionID
+		r.pending[key(events.CompetitionLevel)] = struct{}{}
+	case events.DistanceActivatedType:
+		r.pending[key(events.DistanceLevel)] = struct{}{}
+	case events.HeatActivatedType:
+		r.pending[key(events.HeatLevel)] = struct{}{}
+	case events.DistanceDeactivatedType:
+		delete(r.pending, key(events.DistanceLevel))
+	case events.HeatDeactivatedType:
+		delete(r.pending, key(events.HeatLevel))
+	case events.StreamLiveType:
+		delete(r.pending, key(header.Level))
+	}
+	return nil
+}
+
+// CompetitionID returns the ID of the competition, or an empty string if the competition activation was not tracked.
+func (r *Replays) CompetitionID() string {
+	return r.competitionID
+}
+
+// Delivered returns whether the replay of the raw events is delivered.
+func (r *Replays) Delivered() bool {
+	return r.competitionID != "" && len(r.pending) == 0
+}
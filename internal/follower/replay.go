@@ -0,0 +1,86 @@
+// Copyright © 2020 Emando B.V.
+
+package follower
+
+import (
+	"sync"
+
+	"github.com/emando/vantage-events/pkg/events"
+)
+
+// replay detects the end of the replay of a subscription. The replay ends with the last event that was published
+// before subscribing, which is known by the sequence at subscribe time, or immediately if there are no events to
+// replay. Competitions and distances also replay the last activations of their distances and heats: their replay ends
+// when both the events and the activations are replayed, so that the replays of the activated distances and heats are
+// tracked before the end of the replay.
+type replay struct {
+	live chan struct{}
+	once sync.Once
+	// events and activations are whether the events and the activations are replayed, and sent the number of events
+	// that are delivered. The methods that set them are called by the goroutine that follows the subscription.
+	events, activations bool
+	sent                int
+	// after is the number of delivered events that precede the end of the replay. It is set when live is closed.
+	after int
+}
+
+// newReplay returns a replay of events, and of activations if withActivations is true.
+func newReplay(withActivations bool) *replay {
+	return &replay{
+		live:        make(chan struct{}),
+		activations: !withActivations,
+	}
+}
+
+// done marks the replay of the events as delivered.
+func (r *replay) done() {
+	r.events = true
+	r.check()
+}
+
+// activated marks the replay of the activations as delivered. This must be called after the replayed activations are
+// delivered.
+func (r *replay) activated() {
+	r.activations = true
+	r.check()
+}
+
+func (r *replay) check() {
+	if !r.events || !r.activations {
+		return
+	}
+	r.once.Do(func() {
+		r.after = r.sent
+		close(r.live)
+	})
+}
+
+// liveAfter returns the number of delivered events that precede the end of the replay. Live is closed after the last
+// replayed event is delivered, so consumers may observe live before they handle that event. This must be called after
+// live is closed.
+func (r *replay) liveAfter() int {
+	return r.after
+}
+
+// subscribed marks the replay as delivered if the subscription has no events to replay after the sequence of the last
+// delivered event.
+func (r *replay) subscribed(sub *events.Replay, after uint64) {
+	if sub.Last <= after {
+		r.done()
+	}
+}
+
+// received marks the replay as delivered if the event is live. This must be called before the event is delivered.
+func (r *replay) received(sub *events.Replay, event *events.Raw) {
+	if event.Sequence > sub.Last {
+		r.done()
+	}
+}
+
+// delivered counts the delivered event and marks the replay as delivered if the event is the last replayed event.
+func (r *replay) delivered(sub *events.Replay, event *events.Raw) {
+	r.sent++
+	if event.Sequence >= sub.Last {
+		r.done()
+	}
+}
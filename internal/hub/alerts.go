@@ -0,0 +1,68 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/emando/vantage-events/internal/quality"
+	"go.uber.org/zap"
+)
+
+// WithMonitor configures the Hub to serve timing data quality alerts of the monitor.
+func WithMonitor(m *quality.Monitor) Option {
+	return func(h *Hub) {
+		h.monitor = m
+	}
+}
+
+func (h *Hub) getAlerts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.monitor.Recent(r.URL.Query().Get("competition"))); err != nil {
+		h.logger.Debug("failed to write alerts", zap.Error(err))
+	}
+}
+
+func (h *Hub) getAlertStream(w http.ResponseWriter, r *http.Request) {
+	competitionID := r.URL.Query().Get("competition")
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	logger := h.logger.With(zap.String("remote_address", r.RemoteAddr))
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Debug("failed to upgrade websocket", zap.Error(err))
+		return
+	}
+	defer c.Close()
+
+	go writePings(ctx, logger, c)
+
+	ch := h.monitor.Subscribe()
+	defer h.monitor.Unsubscribe(ch)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case a := <-ch:
+				if competitionID != "" && a.CompetitionID != competitionID {
+					continue
+				}
+				if err := c.WriteJSON(a); err != nil {
+					logger.Debug("failed to write message", zap.Error(err))
+					return
+				}
+			}
+		}
+	}()
+
+	if err := readPongs(ctx, logger, c); err != nil {
+		cancel()
+		return
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/emando/vantage-events/internal/archive"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// WithArchive configures the Hub to serve archived competitions from the archive.
+func WithArchive(a *archive.Archive) Option {
+	return func(h *Hub) {
+		h.archive = a
+	}
+}
+
+func (h *Hub) getArchivedCompetitions(w http.ResponseWriter, r *http.Request) {
+	competitions, err := h.archive.Competitions()
+	if err != nil {
+		h.logger.Warn("failed to list archived competitions", zap.Error(err))
+		http.Error(w, "failed to list archived competitions", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(competitions); err != nil {
+		h.logger.Debug("failed to write archived competitions", zap.Error(err))
+	}
+}
+
+func (h *Hub) getArchivedCompetition(w http.ResponseWriter, r *http.Request) {
+	competition, err := h.archive.Competition(mux.Vars(r)["id"])
+	if err == archive.ErrNotFound {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		h.logger.Warn("failed to get archived competition", zap.Error(err))
+		http.Error(w, "failed to get archived competition", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(competition); err != nil {
+		h.logger.Debug("failed to write archived competition", zap.Error(err))
+	}
+}
+
+// archiveQuery returns the query of the request. The time range is given by the since and until query parameters in
+// RFC 3339 format.
+func archiveQuery(r *http.Request) (archive.Query, error) {
+	q := archive.Query{
+		DistanceID: mux.Vars(r)["distanceID"],
+	}
+	for name, t := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		s := r.URL.Query().Get(name)
+		if s == "" {
+			continue
+		}
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return q, fmt.Errorf("invalid %s", name)
+		}
+		*t = v
+	}
+	return q, nil
+}
+
+// withTime returns the JSON encoded event with the time it was received in the _time field, as in recordings of the
+// Event Recorder.
+func withTime(event []byte, t time.Time) []byte {
+	if len(event) < 2 || event[0] != '{' {
+		return event
+	}
+	buf, _ := t.MarshalJSON()
+	res := make([]byte, 0, len(event)+len(buf)+10)
+	res = append(res, `{"_time":`...)
+	res = append(res, buf...)
+	if event[1] != '}' {
+		res = append(res, ',')
+	}
+	return append(res, event[1:]...)
+}
+
+func (h *Hub) getArchivedEvents(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	q, err := archiveQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := h.archive.Competition(id); err == archive.ErrNotFound {
+		http.NotFound(w, r)
+		return
+	}
+	name := id
+	if q.DistanceID != "" {
+		name += "-" + q.DistanceID
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, name))
+	if err := h.archive.Events(id, q, func(rec archive.Record) error {
+		buf := append(withTime(rec.Event, rec.Time), '\n')
+		_, err := w.Write(buf)
+		return err
+	}); err != nil {
+		h.logger.Debug("failed to write archived events", zap.Error(err))
+	}
+}
+
+func (h *Hub) getArchivedEventStream(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	q, err := archiveQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := h.archive.Competition(id); err == archive.ErrNotFound {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	logger := h.logger.With(zap.String("remote_address", r.RemoteAddr))
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Debug("failed to upgrade websocket", zap.Error(err))
+		return
+	}
+	defer c.Close()
+
+	go writePings(ctx, logger, c)
+
+	go func() {
+		if err := h.archive.Events(id, q, func(rec archive.Record) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return c.WriteMessage(websocket.TextMessage, rec.Event)
+		}); err != nil {
+			logger.Debug("failed to write archived events", zap.Error(err))
+		}
+	}()
+
+	if err := readPongs(ctx, logger, c); err != nil {
+		cancel()
+		return
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/events"
+	"go.uber.org/zap"
+)
+
+// maxRate is the maximum rate of batches per heat in Hz.
+const maxRate = 20
+
+// rateOf returns the batch interval of the rate selected in the request, i.e. ?rate=4. The interval is zero if
+// batching is disabled.
+func rateOf(r *http.Request) (time.Duration, error) {
+	s := r.URL.Query().Get("rate")
+	if s == "" {
+		return 0, nil
+	}
+	rate, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	return batchInterval(rate)
+}
+
+// batchInterval returns the batch interval of the rate in Hz. The interval is zero if the rate is zero.
+func batchInterval(rate float64) (time.Duration, error) {
+	switch {
+	case rate == 0:
+		return 0, nil
+	case rate < 0, rate > maxRate:
+		return 0, fmt.Errorf("rate must be between 0 and %d Hz", maxRate)
+	default:
+		return time.Duration(float64(time.Second) / rate), nil
+	}
+}
+
+// batchedEvent is a speed change or passing that is batched.
+type batchedEvent struct {
+	streamHeader
+	RaceID  string          `json:"raceId"`
+	Passing json.RawMessage `json:"passing"`
+}
+
+// batchedHeat contains the pending changes of the races in a heat.
+type batchedHeat struct {
+	heat  events.Heat
+	races []*batchedRace
+}
+
+// race returns the pending changes of the race, in order of the first change.
+func (h *batchedHeat) race(id string) *batchedRace {
+	for _, r := range h.races {
+		if r.id == id {
+			return r
+		}
+	}
+	r := &batchedRace{id: id}
+	h.races = append(h.races, r)
+	return r
+}
+
+// batchedRace contains the last speed change and the passing deltas of a race.
+type batchedRace struct {
+	id       string
+	speed    json.RawMessage
+	passings []json.RawMessage
+}
+
+// sentFields contains the fields of the last speed change and passing of a race that are sent.
+type sentFields struct {
+	speed, passing map[string]json.RawMessage
+}
+
+// delta returns the fields of the JSON object that differ from the previous fields, and the fields of the object.
+func delta(prev map[string]json.RawMessage, buf []byte) (map[string]json.RawMessage, map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return nil, nil, err
+	}
+	res := make(map[string]json.RawMessage, len(fields))
+	for k, v := range fields {
+		if p, ok := prev[k]; !ok || !bytes.Equal(p, v) {
+			res[k] = v
+		}
+	}
+	return res, fields, nil
+}
+
+// batch coalesces the LastRaceSpeedChangedEvents and RacePassingAddedEvents into a StreamBatchEvent per heat each
+// interval. A batch contains the last speed change and the passings of each race, with the fields that changed. The
+// pending batch of a heat is sent before other events of the heat, to keep the order. Other events are passed through.
+func batch(ctx context.Context, logger *zap.Logger, in <-chan []byte, interval time.Duration) <-chan []byte {
+	out := make(chan []byte)
+	send := func(buf []byte) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- buf:
+			return true
+		}
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var (
+			pending = make(map[string]*batchedHeat)
+			keys    []string
+			sent    = make(map[string]*sentFields)
+		)
+		flush := func(key string) bool {
+			heat, ok := pending[key]
+			if !ok {
+				return true
+			}
+			delete(pending, key)
+			for i, k := range keys {
+				if k == key {
+					keys = append(keys[:i], keys[i+1:]...)
+					break
+				}
+			}
+			event := events.StreamBatch{Heat: heat.heat}
+			event.Type = events.StreamBatchType
+			for _, r := range heat.races {
+				race := events.StreamBatchRace{
+					RaceID:   r.id,
+					Passings: r.passings,
+				}
+				if r.speed != nil {
+					last := sent[r.id]
+					d, fields, err := delta(last.speed, r.speed)
+					if err != nil {
+						logger.Debug("failed to unmarshal speed", zap.Error(err))
+					} else if len(d) > 0 {
+						race.Speed, _ = json.Marshal(d)
+						last.speed = fields
+					}
+				}
+				if race.Speed == nil && len(race.Passings) == 0 {
+					continue
+				}
+				event.Races = append(event.Races, race)
+			}
+			if len(event.Races) == 0 {
+				return true
+			}
+			buf, err := json.Marshal(event)
+			if err != nil {
+				logger.Warn("failed to marshal batch", zap.Error(err))
+				return true
+			}
+			return send(buf)
+		}
+
+		for {
+			var buf []byte
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for len(keys) > 0 {
+					if !flush(keys[0]) {
+						return
+					}
+				}
+				continue
+			case buf = <-in:
+			}
+			var event batchedEvent
+			if err := json.Unmarshal(buf, &event); err != nil {
+				logger.Debug("failed to unmarshal event", zap.Error(err))
+				if !send(buf) {
+					return
+				}
+				continue
+			}
+			key := event.replayKey(events.HeatLevel)
+			switch event.TypeName() {
+			case events.LastRaceSpeedChangedType, events.RacePassingAddedType:
+			default:
+				if !flush(key) || !send(buf) {
+					return
+				}
+				continue
+			}
+
+			heat, ok := pending[key]
+			if !ok {
+				heat = &batchedHeat{heat: event.Heat}
+				pending[key] = heat
+				keys = append(keys, key)
+			}
+			race := heat.race(event.RaceID)
+			last, ok := sent[event.RaceID]
+			if !ok {
+				last = &sentFields{}
+				sent[event.RaceID] = last
+			}
+			if event.TypeName() == events.LastRaceSpeedChangedType {
+				race.speed = event.Passing
+				continue
+			}
+			d, fields, err := delta(last.passing, event.Passing)
+			if err != nil {
+				logger.Debug("failed to unmarshal passing", zap.Error(err))
+				continue
+			}
+			passing, err := json.Marshal(d)
+			if err != nil {
+				continue
+			}
+			race.passings = append(race.passings, passing)
+			last.passing = fields
+		}
+	}()
+	return out
+}
@@ -0,0 +1,158 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/events"
+	"go.uber.org/zap"
+)
+
+func TestRateOf(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		expected time.Duration
+		err      bool
+	}{
+		{query: "", expected: 0},
+		{query: "?rate=0", expected: 0},
+		{query: "?rate=4", expected: 250 * time.Millisecond},
+		{query: "?rate=0.5", expected: 2 * time.Second},
+		{query: "?rate=20", expected: 50 * time.Millisecond},
+		{query: "?rate=21", err: true},
+		{query: "?rate=-1", err: true},
+		{query: "?rate=fast", err: true},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			interval, err := rateOf(httptest.NewRequest(http.MethodGet, "/v1/stream"+tc.query, nil))
+			if (err != nil) != tc.err {
+				t.Fatalf("error is %v", err)
+			}
+			if interval != tc.expected {
+				t.Errorf("interval is %v, want %v", interval, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDelta(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		prev     map[string]json.RawMessage
+		buf      string
+		expected string
+	}{
+		{name: "NoPrevious", buf: `{"length":100,"time":1}`, expected: `{"length":100,"time":1}`},
+		{name: "Changed", prev: map[string]json.RawMessage{"length": json.RawMessage("100"), "time": json.RawMessage("1")}, buf: `{"length":200,"time":1}`, expected: `{"length":200}`},
+		{name: "Unchanged", prev: map[string]json.RawMessage{"length": json.RawMessage("100")}, buf: `{"length":100}`, expected: `{}`},
+		{name: "Added", prev: map[string]json.RawMessage{"length": json.RawMessage("100")}, buf: `{"length":100,"time":2}`, expected: `{"time":2}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, fields, err := delta(tc.prev, []byte(tc.buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf, _ := json.Marshal(d); string(buf) != tc.expected {
+				t.Errorf("delta is %s, want %s", buf, tc.expected)
+			}
+			if buf, _ := json.Marshal(fields); string(buf) != tc.buf {
+				t.Errorf("fields are %s, want %s", buf, tc.buf)
+			}
+		})
+	}
+	if _, _, err := delta(nil, []byte(`[1]`)); err == nil {
+		t.Error("delta of array succeeded")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	raceEvent := func(typeName, raceID, passing string) string {
+		return fmt.Sprintf(`{"typeName":%q,"competitionId":"c1","distanceId":"d1","heat":{"round":1,"number":2},"raceId":%q,"passing":%s}`, typeName, raceID, passing)
+	}
+	var (
+		speed1   = raceEvent(events.LastRaceSpeedChangedType, "r1", `{"lap":1,"speed":10}`)
+		speed2   = raceEvent(events.LastRaceSpeedChangedType, "r1", `{"lap":1,"speed":11}`)
+		passing1 = raceEvent(events.RacePassingAddedType, "r1", `{"lap":1,"length":100}`)
+		passing2 = raceEvent(events.RacePassingAddedType, "r1", `{"lap":1,"length":200}`)
+		passing3 = raceEvent(events.RacePassingAddedType, "r2", `{"lap":1,"length":100}`)
+	)
+	for _, tc := range []struct {
+		name     string
+		interval time.Duration
+		in       []string
+		expected []interface{}
+	}{
+		{
+			name:     "FlushBeforeHeatEvent",
+			interval: time.Hour,
+			in:       []string{speed1, passing1, speed2, passing2, passing3, heatEvent},
+			expected: []interface{}{
+				[]events.StreamBatchRace{
+					{RaceID: "r1", Speed: json.RawMessage(`{"lap":1,"speed":11}`), Passings: []json.RawMessage{
+						json.RawMessage(`{"lap":1,"length":100}`), json.RawMessage(`{"length":200}`),
+					}},
+					{RaceID: "r2", Passings: []json.RawMessage{json.RawMessage(`{"lap":1,"length":100}`)}},
+				},
+				heatEvent,
+			},
+		},
+		{
+			name:     "PassThrough",
+			interval: time.Hour,
+			in:       []string{competitionEvent, `{`, heatEvent},
+			expected: []interface{}{competitionEvent, `{`, heatEvent},
+		},
+		{
+			name:     "Interval",
+			interval: 10 * time.Millisecond,
+			in:       []string{speed1},
+			expected: []interface{}{
+				[]events.StreamBatchRace{{RaceID: "r1", Speed: json.RawMessage(`{"lap":1,"speed":10}`)}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := pipe(t, func(ctx context.Context, in <-chan []byte) <-chan []byte {
+				return batch(ctx, zap.NewNop(), in, tc.interval)
+			}, tc.in)
+			if len(res) != len(tc.expected) {
+				t.Fatalf("received %d events, want %d: %v", len(res), len(tc.expected), res)
+			}
+			for i, expected := range tc.expected {
+				races, ok := expected.([]events.StreamBatchRace)
+				if !ok {
+					if res[i] != expected {
+						t.Errorf("event %d is %s, want %s", i, res[i], expected)
+					}
+					continue
+				}
+				var event events.StreamBatch
+				if err := json.Unmarshal([]byte(res[i]), &event); err != nil {
+					t.Fatal(err)
+				}
+				if event.TypeName() != events.StreamBatchType || event.CompetitionID != "c1" || event.DistanceID != "d1" {
+					t.Errorf("event %d is %s, want batch of heat", i, res[i])
+				}
+				if buf, expected := mustMarshal(t, event.Races), mustMarshal(t, races); buf != expected {
+					t.Errorf("races of batch %d are %s, want %s", i, buf, expected)
+				}
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	t.Helper()
+	buf, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf)
+}
@@ -0,0 +1,108 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/emando/vantage-events/pkg/analysis"
+	"github.com/emando/vantage-events/pkg/state"
+	"go.uber.org/zap"
+)
+
+// deriver returns the JSON encoded event derived from the JSON encoded event, or nil. The event is applied to the
+// store first.
+type deriver func(store *state.Store, buf []byte) ([]byte, error)
+
+// derivers return the derived events that clients select with the derived query parameter, configured by the
+// query parameters of the request.
+var derivers = map[string]func(query url.Values) (deriver, error){
+	"splits": func(url.Values) (deriver, error) {
+		return analysis.DeriveSplit, nil
+	},
+	"predictions": func(query url.Values) (deriver, error) {
+		model, err := analysis.ParseModel(query.Get("model"))
+		if err != nil {
+			return nil, err
+		}
+		return analysis.FinishPredictor(model), nil
+	},
+	"teams": func(url.Values) (deriver, error) {
+		return analysis.DeriveTeam, nil
+	},
+}
+
+// derivedOf returns the derivers selected in the request, i.e. ?derived=splits,predictions.
+func derivedOf(r *http.Request) ([]deriver, error) {
+	var names []string
+	query := r.URL.Query()
+	for _, value := range query["derived"] {
+		names = append(names, strings.Split(value, ",")...)
+	}
+	return derivedByName(names, query)
+}
+
+// derivedByName returns the derivers with the names, configured by the query parameters.
+func derivedByName(names []string, query url.Values) ([]deriver, error) {
+	var res []deriver
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		f, ok := derivers[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid derived events %q", name)
+		}
+		d, err := f(query)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+// derive returns the events with the derived events following the event they are derived from.
+func derive(ctx context.Context, logger *zap.Logger, in <-chan []byte, derivers []deriver) <-chan []byte {
+	out := make(chan []byte)
+	store := state.NewStore()
+	send := func(buf []byte) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- buf:
+			return true
+		}
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case buf := <-in:
+				if !send(buf) {
+					return
+				}
+				if err := store.Apply(buf); err != nil {
+					logger.Debug("failed to apply event", zap.Error(err))
+					continue
+				}
+				for _, d := range derivers {
+					derived, err := d(store, buf)
+					if err != nil {
+						logger.Debug("failed to derive event", zap.Error(err))
+						continue
+					}
+					if derived != nil && !send(derived) {
+						return
+					}
+				}
+			}
+		}
+	}()
+	return out
+}
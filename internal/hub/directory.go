@@ -0,0 +1,175 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// directoryTopic is the topic of directory updates.
+const directoryTopic = "directory"
+
+// liveWindow is the time since the last activity in which a competition with an active distance is live.
+const liveWindow = 15 * time.Minute
+
+// Entry is a competition in the directory.
+type Entry struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Discipline     string          `json:"discipline"`
+	Class          int             `json:"class"`
+	Venue          *entities.Venue `json:"venue"`
+	LastActivity   *time.Time      `json:"lastActivity"`
+	ActiveDistance *EntryDistance  `json:"activeDistance"`
+	ActiveHeats    []EntryHeat     `json:"activeHeats"`
+	Live           bool            `json:"live"`
+}
+
+// EntryDistance is the active distance of a competition in the directory.
+type EntryDistance struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Number int    `json:"number"`
+}
+
+// EntryHeat is an active heat of a competition in the directory.
+type EntryHeat struct {
+	Round  int `json:"round"`
+	Number int `json:"number"`
+}
+
+func entryOf(c *state.Competition, now time.Time) Entry {
+	e := Entry{
+		ID:          c.ID,
+		Name:        c.Name,
+		Discipline:  c.Discipline,
+		Class:       c.Class,
+		Venue:       c.Venue,
+		ActiveHeats: []EntryHeat{},
+	}
+	if !c.LastActivity.IsZero() {
+		t := c.LastActivity
+		e.LastActivity = &t
+	}
+	if d, ok := c.Distances[c.ActiveDistanceID]; ok {
+		e.ActiveDistance = &EntryDistance{
+			ID:     d.ID,
+			Name:   d.Name,
+			Number: d.Number,
+		}
+		for _, h := range d.Heats {
+			if h.Active {
+				e.ActiveHeats = append(e.ActiveHeats, EntryHeat{Round: h.Key.Round, Number: h.Key.Number})
+			}
+		}
+		e.Live = now.Sub(c.LastActivity) < liveWindow
+	}
+	return e
+}
+
+// listed returns whether a competition with the last activity is listed in the window until now. Competitions without
+// activity are always listed, and all competitions are listed if the window is zero.
+func listed(lastActivity *time.Time, window time.Duration, now time.Time) bool {
+	return window == 0 || lastActivity == nil || lastActivity.IsZero() || !lastActivity.Before(now.Add(-window))
+}
+
+// entries returns the directory entries in the window, most recently active first.
+func (h *Hub) entries(window time.Duration) []Entry {
+	now := time.Now()
+	res := []Entry{}
+	h.store.Competitions(func(c *state.Competition) {
+		if e := entryOf(c, now); listed(e.LastActivity, window, now) {
+			res = append(res, e)
+		}
+	})
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i].LastActivity, res[j].LastActivity
+		switch {
+		case a == nil || b == nil:
+			return a != nil
+		case !a.Equal(*b):
+			return a.After(*b)
+		default:
+			return res[i].Name < res[j].Name
+		}
+	})
+	return res
+}
+
+func (h *Hub) getDirectory(w http.ResponseWriter, r *http.Request) {
+	window, err := windowOf(r, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.entries(window)); err != nil {
+		h.logger.Debug("failed to write directory", zap.Error(err))
+	}
+}
+
+// getDirectoryStream streams the directory entries in the window, followed by the updates of competitions that are
+// listed in the window at the time of the update.
+func (h *Hub) getDirectoryStream(w http.ResponseWriter, r *http.Request) {
+	window, err := windowOf(r, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	logger := h.logger.With(zap.String("remote_address", r.RemoteAddr))
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Debug("failed to upgrade websocket", zap.Error(err))
+		return
+	}
+	defer c.Close()
+
+	go writePings(ctx, logger, c)
+
+	ch := h.topics.subscribe(directoryTopic)
+	defer h.topics.unsubscribe(ch)
+
+	go func() {
+		for _, e := range h.entries(window) {
+			if err := c.WriteJSON(e); err != nil {
+				logger.Debug("failed to write message", zap.Error(err))
+				return
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case buf := <-ch:
+				var e struct {
+					LastActivity *time.Time `json:"lastActivity"`
+				}
+				if err := json.Unmarshal(buf, &e); err != nil || !listed(e.LastActivity, window, time.Now()) {
+					continue
+				}
+				if err := c.WriteMessage(websocket.TextMessage, buf); err != nil {
+					logger.Debug("failed to write message", zap.Error(err))
+					return
+				}
+			}
+		}
+	}()
+
+	if err := readPongs(ctx, logger, c); err != nil {
+		cancel()
+		return
+	}
+}
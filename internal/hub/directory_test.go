@@ -0,0 +1,130 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+func TestGetDirectory(t *testing.T) {
+	store := state.NewStore()
+	file, err := os.Open("../../examples/20200112-ec-single-distances-recover-11-4.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	s := bufio.NewScanner(file)
+	s.Buffer(nil, 1<<22)
+	for s.Scan() {
+		if err := store.Apply(append([]byte(nil), s.Bytes()...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The competition without activity is listed regardless of the window.
+	if err := store.Apply([]byte(competitionActivated)); err != nil {
+		t.Fatal(err)
+	}
+	h := &Hub{logger: zap.NewNop(), store: store}
+
+	for _, tc := range []struct {
+		name     string
+		query    string
+		status   int
+		expected []string
+	}{
+		{name: "All", status: http.StatusOK, expected: []string{"30913459-50b1-4df7-8e1b-a078472f9e9b", "c1"}},
+		{name: "Window", query: "?window=24h", status: http.StatusOK, expected: []string{"c1"}},
+		{name: "InvalidWindow", query: "?window=1y", status: http.StatusBadRequest},
+		{name: "NegativeWindow", query: "?window=-1h", status: http.StatusBadRequest},
+		{name: "WindowTooLarge", query: "?window=1000h", status: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.getDirectory(w, httptest.NewRequest(http.MethodGet, "/v1/directory"+tc.query, nil))
+			if w.Code != tc.status {
+				t.Fatalf("status is %d, want %d", w.Code, tc.status)
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+			var entries []Entry
+			if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tc.expected) {
+				t.Fatalf("listed %d competitions, want %d", len(entries), len(tc.expected))
+			}
+			for i, e := range entries {
+				if e.ID != tc.expected[i] {
+					t.Errorf("competition %d is %q, want %q", i, e.ID, tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetDirectoryStream(t *testing.T) {
+	recent, old := time.Now().Add(-time.Hour), time.Date(2020, 1, 12, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "All", expected: []string{"c1", "old", "recent", "none"}},
+		{name: "Window", query: "?window=24h", expected: []string{"c1", "recent", "none"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := state.NewStore()
+			if err := store.Apply([]byte(competitionActivated)); err != nil {
+				t.Fatal(err)
+			}
+			h := &Hub{logger: zap.NewNop(), store: store}
+			s := httptest.NewServer(http.HandlerFunc(h.getDirectoryStream))
+			defer s.Close()
+			c, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http")+tc.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			var e Entry
+			if err := c.ReadJSON(&e); err != nil {
+				t.Fatal(err)
+			}
+			ids := []string{e.ID}
+			for !h.topics.subscribed(directoryTopic) {
+				time.Sleep(time.Millisecond)
+			}
+			for _, update := range []Entry{
+				{ID: "old", LastActivity: &old},
+				{ID: "recent", LastActivity: &recent},
+				{ID: "none"},
+			} {
+				if err := h.topics.publish(directoryTopic, update); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c.SetReadDeadline(time.Now().Add(time.Second))
+			for len(ids) < len(tc.expected) {
+				if err := c.ReadJSON(&e); err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, e.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.expected) {
+				t.Errorf("streamed %v, want %v", ids, tc.expected)
+			}
+		})
+	}
+}
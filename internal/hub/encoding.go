@@ -0,0 +1,141 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/emando/vantage-events/internal/wire"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/gorilla/websocket"
+)
+
+// Encoding formats that clients select with the encoding query parameter or the websocket subprotocol.
+const (
+	jsonFormat    = "json"
+	msgpackFormat = "msgpack"
+	cborFormat    = "cbor"
+)
+
+// subprotocols are the websocket subprotocols of the encoding formats.
+var subprotocols = map[string]string{
+	"vantage.json":    jsonFormat,
+	"vantage.msgpack": msgpackFormat,
+	"vantage.cbor":    cborFormat,
+}
+
+// encoding is the encoding of the messages of an event stream.
+type encoding struct {
+	format string
+	// slim strips large static fields that are sent in activation events.
+	slim bool
+}
+
+// encodingOf returns the encoding selected in the request, i.e. ?encoding=msgpack&slim=true, and the response header
+// with the selected subprotocol, if any. The query parameter takes precedence over the subprotocol.
+func encodingOf(r *http.Request) (encoding, http.Header, error) {
+	var (
+		res    = encoding{format: r.URL.Query().Get("encoding")}
+		header http.Header
+	)
+	if res.format == "" {
+		for _, protocol := range websocket.Subprotocols(r) {
+			if format, ok := subprotocols[protocol]; ok {
+				res.format = format
+				header = http.Header{"Sec-Websocket-Protocol": {protocol}}
+				break
+			}
+		}
+	}
+	switch res.format {
+	case "":
+		res.format = jsonFormat
+	case jsonFormat, msgpackFormat, cborFormat:
+	default:
+		return encoding{}, nil, fmt.Errorf("invalid encoding %q", res.format)
+	}
+	if s := r.URL.Query().Get("slim"); s != "" {
+		slim, err := strconv.ParseBool(s)
+		if err != nil {
+			return encoding{}, nil, fmt.Errorf("invalid slim %q", s)
+		}
+		res.slim = slim
+	}
+	return res, header, nil
+}
+
+// project returns the JSON encoded event, slimmed if configured.
+func (e encoding) project(buf []byte) []byte {
+	if !e.slim {
+		return buf
+	}
+	if res, err := slim(buf); err == nil {
+		return res
+	}
+	return buf
+}
+
+// frame returns the websocket message type and data of the JSON encoded message.
+func (e encoding) frame(buf []byte) (int, []byte, error) {
+	switch e.format {
+	case msgpackFormat:
+		res, err := wire.MessagePack(buf)
+		return websocket.BinaryMessage, res, err
+	case cborFormat:
+		res, err := wire.CBOR(buf)
+		return websocket.BinaryMessage, res, err
+	default:
+		return websocket.TextMessage, buf, nil
+	}
+}
+
+// message returns the websocket message type and data of the JSON encoded event.
+func (e encoding) message(buf []byte) (int, []byte, error) {
+	return e.frame(e.project(buf))
+}
+
+// Static race fields that are sent in activation events.
+var (
+	staticRaceFields     = []string{"estimatedLaps"}
+	staticCompetitorKeys = []string{"competitor", "transponders"}
+)
+
+// slim strips the estimated laps, competitors and transponders of races from events other than activations.
+func slim(buf []byte) ([]byte, error) {
+	if !bytes.Contains(buf, []byte(`"race`)) {
+		return buf, nil
+	}
+	d := json.NewDecoder(bytes.NewReader(buf))
+	d.UseNumber()
+	var event map[string]interface{}
+	if err := d.Decode(&event); err != nil {
+		return nil, err
+	}
+	switch event["typeName"] {
+	case events.CompetitionActivatedType, events.DistanceActivatedType, events.HeatActivatedType:
+		return buf, nil
+	}
+	stripRace := func(v interface{}) {
+		if race, ok := v.(map[string]interface{}); ok {
+			for _, key := range staticCompetitorKeys {
+				delete(race, key)
+			}
+		}
+	}
+	if races, ok := event["races"].([]interface{}); ok {
+		for _, v := range races {
+			if heatRace, ok := v.(map[string]interface{}); ok {
+				for _, key := range staticRaceFields {
+					delete(heatRace, key)
+				}
+				stripRace(heatRace["race"])
+			}
+		}
+	}
+	stripRace(event["race"])
+	return json.Marshal(event)
+}
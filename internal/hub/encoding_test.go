@@ -0,0 +1,135 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestEncodingOf(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		query       string
+		protocols   string
+		expected    encoding
+		subprotocol string
+		invalid     bool
+	}{
+		{name: "Default", expected: encoding{format: jsonFormat}},
+		{name: "Query", query: "?encoding=msgpack&slim=true", expected: encoding{format: msgpackFormat, slim: true}},
+		{name: "Subprotocol", protocols: "other, vantage.cbor", expected: encoding{format: cborFormat}, subprotocol: "vantage.cbor"},
+		{name: "QueryPrecedence", query: "?encoding=json", protocols: "vantage.cbor", expected: encoding{format: jsonFormat}},
+		{name: "UnknownSubprotocol", protocols: "other", expected: encoding{format: jsonFormat}},
+		{name: "InvalidEncoding", query: "?encoding=xml", invalid: true},
+		{name: "InvalidSlim", query: "?slim=yes", invalid: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/competitions/c1"+tc.query, nil)
+			if tc.protocols != "" {
+				r.Header.Set("Sec-Websocket-Protocol", tc.protocols)
+			}
+			res, header, err := encodingOf(r)
+			if tc.invalid {
+				if err == nil {
+					t.Errorf("encoding is %+v, want error", res)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != tc.expected {
+				t.Errorf("encoding is %+v, want %+v", res, tc.expected)
+			}
+			if subprotocol := header.Get("Sec-Websocket-Protocol"); subprotocol != tc.subprotocol {
+				t.Errorf("subprotocol is %q, want %q", subprotocol, tc.subprotocol)
+			}
+		})
+	}
+}
+
+func TestSlim(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		event    string
+		expected string
+	}{
+		{
+			name:     "NoRaces",
+			event:    heatEvent,
+			expected: heatEvent,
+		},
+		{
+			name:     "Activation",
+			event:    `{"typeName":"HeatActivatedEvent","races":[{"estimatedLaps":[],"race":{"id":"r1","competitor":{}}}]}`,
+			expected: `{"typeName":"HeatActivatedEvent","races":[{"estimatedLaps":[],"race":{"id":"r1","competitor":{}}}]}`,
+		},
+		{
+			name:     "Races",
+			event:    `{"typeName":"HeatUpdatedEvent","races":[{"estimatedLaps":[],"race":{"id":"r1","competitor":{},"transponders":[]}}]}`,
+			expected: `{"races":[{"race":{"id":"r1"}}],"typeName":"HeatUpdatedEvent"}`,
+		},
+		{
+			name:     "Race",
+			event:    `{"typeName":"RaceUpdatedEvent","race":{"id":"r1","lane":1,"competitor":{},"transponders":[]},"time":3528000000}`,
+			expected: `{"race":{"id":"r1","lane":1},"time":3528000000,"typeName":"RaceUpdatedEvent"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := slim([]byte(tc.event))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(res) != tc.expected {
+				t.Errorf("slim event is %s, want %s", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	const event = `{"typeName":"RaceUpdatedEvent","race":{"id":"r1","competitor":{}}}`
+	for _, tc := range []struct {
+		name        string
+		encoding    encoding
+		messageType int
+		expected    string
+	}{
+		{name: "JSON", encoding: encoding{format: jsonFormat}, messageType: websocket.TextMessage, expected: event},
+		{
+			name:        "SlimJSON",
+			encoding:    encoding{format: jsonFormat, slim: true},
+			messageType: websocket.TextMessage,
+			expected:    `{"race":{"id":"r1"},"typeName":"RaceUpdatedEvent"}`,
+		},
+		{
+			name:        "SlimMessagePack",
+			encoding:    encoding{format: msgpackFormat, slim: true},
+			messageType: websocket.BinaryMessage,
+			expected:    "\x82\xa4race\x81\xa2id\xa2r1\xa8typeName\xb0RaceUpdatedEvent",
+		},
+		{
+			name:        "CBOR",
+			encoding:    encoding{format: cborFormat},
+			messageType: websocket.BinaryMessage,
+			expected:    "\xa2\x64race\xa2\x6acompetitor\xa0\x62id\x62r1\x68typeName\x70RaceUpdatedEvent",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			messageType, data, err := tc.encoding.message([]byte(event))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if messageType != tc.messageType || string(data) != tc.expected {
+				t.Errorf("message is %d %q, want %d %q", messageType, data, tc.messageType, tc.expected)
+			}
+		})
+	}
+	if _, _, err := (encoding{format: cborFormat}).message([]byte(`{`)); err == nil {
+		t.Error("invalid event is encoded")
+	}
+}
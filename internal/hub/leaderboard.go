@@ -0,0 +1,83 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/emando/vantage-events/pkg/leaderboard"
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// leaderboard returns the leaderboard of the distance, or nil if the distance is unknown.
+func (h *Hub) leaderboard(competitionID, distanceID string) *leaderboard.Leaderboard {
+	var res *leaderboard.Leaderboard
+	h.store.Competition(competitionID, func(c *state.Competition) {
+		if d, ok := c.Distances[distanceID]; ok {
+			res = leaderboard.FromDistance(c, d)
+		}
+	})
+	return res
+}
+
+func (h *Hub) getLeaderboard(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	lb := h.leaderboard(vars["id"], vars["distanceID"])
+	if lb == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(lb); err != nil {
+		h.logger.Debug("failed to write leaderboard", zap.Error(err))
+	}
+}
+
+func (h *Hub) getLeaderboardStream(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	logger := h.logger.With(zap.String("remote_address", r.RemoteAddr))
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Debug("failed to upgrade websocket", zap.Error(err))
+		return
+	}
+	defer c.Close()
+
+	go writePings(ctx, logger, c)
+
+	ch := h.topics.subscribe(leaderboardTopic(vars["id"], vars["distanceID"]))
+	defer h.topics.unsubscribe(ch)
+
+	go func() {
+		if lb := h.leaderboard(vars["id"], vars["distanceID"]); lb != nil {
+			if err := c.WriteJSON(lb); err != nil {
+				logger.Debug("failed to write message", zap.Error(err))
+				return
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case buf := <-ch:
+				if err := c.WriteMessage(websocket.TextMessage, buf); err != nil {
+					logger.Debug("failed to write message", zap.Error(err))
+					return
+				}
+			}
+		}
+	}()
+
+	if err := readPongs(ctx, logger, c); err != nil {
+		cancel()
+		return
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/emando/vantage-events/internal/follower"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// lifecycleTopic is the topic of follower lifecycle events.
+	lifecycleTopic = "lifecycle"
+	// recentLifecycle is the number of recent follower lifecycle events that the Hub keeps.
+	recentLifecycle = 1000
+)
+
+// HandleLifecycle keeps and publishes the follower lifecycle event.
+func (h *Hub) HandleLifecycle(l follower.Lifecycle) {
+	h.activity.track(l)
+	h.lifecycleMu.Lock()
+	h.lifecycle = append(h.lifecycle, l)
+	if n := len(h.lifecycle); n > recentLifecycle {
+		h.lifecycle = append(h.lifecycle[:0], h.lifecycle[n-recentLifecycle:]...)
+	}
+	h.lifecycleMu.Unlock()
+	if err := h.topics.publish(lifecycleTopic, l); err != nil {
+		h.logger.Warn("failed to publish lifecycle event", zap.Error(err))
+	}
+}
+
+func (h *Hub) getLifecycle(w http.ResponseWriter, r *http.Request) {
+	h.lifecycleMu.Lock()
+	res := append([]follower.Lifecycle{}, h.lifecycle...)
+	h.lifecycleMu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.logger.Debug("failed to write lifecycle events", zap.Error(err))
+	}
+}
+
+func (h *Hub) getLifecycleStream(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	logger := h.logger.With(zap.String("remote_address", r.RemoteAddr))
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Debug("failed to upgrade websocket", zap.Error(err))
+		return
+	}
+	defer c.Close()
+
+	go writePings(ctx, logger, c)
+
+	ch := h.topics.subscribe(lifecycleTopic)
+	defer h.topics.unsubscribe(ch)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case buf := <-ch:
+				if err := c.WriteMessage(websocket.TextMessage, buf); err != nil {
+					logger.Debug("failed to write message", zap.Error(err))
+					return
+				}
+			}
+		}
+	}()
+
+	if err := readPongs(ctx, logger, c); err != nil {
+		cancel()
+		return
+	}
+}
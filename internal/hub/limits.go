@@ -0,0 +1,308 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Limits configures the limits of websocket connections. Zero values are unlimited.
+type Limits struct {
+	// MaxConnections is the maximum number of concurrent websocket connections.
+	MaxConnections int
+	// MaxConnectionsPerIP is the maximum number of concurrent websocket connections per remote IP address.
+	MaxConnectionsPerIP int
+	// MaxConnectionsPerToken is the maximum number of concurrent websocket connections per API token.
+	MaxConnectionsPerToken int
+	// Rate is the number of new websocket connections per second per remote IP address, with bursts of Burst.
+	Rate  float64
+	Burst int
+	// AllowedOrigins are the allowed origins of websocket connections, i.e. https://example.com, or * to allow all
+	// origins. If empty, only same-origin requests are allowed. Requests without Origin are always allowed.
+	AllowedOrigins []string
+	// TrustedProxies is the number of trusted proxies in front of the hub. The remote IP address is the address in
+	// X-Forwarded-For that was added by the outermost trusted proxy, counting from the right, as clients can set
+	// addresses on the left. If zero, X-Forwarded-For is ignored. With fewer addresses than trusted proxies, the remote
+	// address of the connection is used.
+	TrustedProxies int
+}
+
+// WithLimits configures the limits of websocket connections.
+func WithLimits(limits Limits) Option {
+	return func(h *Hub) {
+		h.limiter.Limits = limits
+	}
+}
+
+// Reasons to reject websocket connections.
+const (
+	originRejected           = "origin"
+	connectionsRejected      = "connections"
+	ipConnectionsRejected    = "ip_connections"
+	tokenConnectionsRejected = "token_connections"
+	rateRejected             = "rate"
+)
+
+// bucketTTL is the time after which unused rate limit buckets are removed.
+const bucketTTL = 10 * time.Minute
+
+// limiter enforces the limits of websocket connections.
+type limiter struct {
+	Limits
+	logger *zap.Logger
+
+	mu          sync.Mutex
+	connections int
+	ips         map[string]int
+	tokens      map[string]int
+	buckets     map[string]*bucket
+	pruned      time.Time
+	rejected    map[string]int
+}
+
+// bucket is a token bucket of new connections.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// remoteIP returns the remote IP address of the request.
+func (l *limiter) remoteIP(r *http.Request) string {
+	if l.TrustedProxies > 0 {
+		var addrs []string
+		for _, header := range r.Header["X-Forwarded-For"] {
+			for _, addr := range strings.Split(header, ",") {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					addrs = append(addrs, addr)
+				}
+			}
+		}
+		// With fewer addresses than trusted proxies, the request did not pass all proxies and every address may be set
+		// by the client.
+		if i := len(addrs) - l.TrustedProxies; i >= 0 && len(addrs) > 0 {
+			return addrs[i]
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// tokenOf returns the API token of the request from the Authorization bearer header or the token query parameter.
+func tokenOf(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
+
+// checkOrigin returns whether the origin of the request is allowed.
+func (l *limiter) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(l.AllowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	for _, allowed := range l.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowRate returns whether a new connection from the IP address is allowed by the rate limit, and otherwise the time
+// until the next connection is allowed. The lock must be held.
+func (l *limiter) allowRate(ip string, now time.Time) (bool, time.Duration) {
+	if l.Rate <= 0 {
+		return true, 0
+	}
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	if l.buckets == nil {
+		l.buckets = make(map[string]*bucket)
+	}
+	if now.Sub(l.pruned) > bucketTTL {
+		for key, b := range l.buckets {
+			if now.Sub(b.last) > bucketTTL {
+				delete(l.buckets, key)
+			}
+		}
+		l.pruned = now
+	}
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[ip] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// acquire reserves a connection of the IP address and token. This method returns a function that releases the
+// connection, or nil with the reason if the connection is rejected.
+func (l *limiter) acquire(ip, token string) (release func(), reason string, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ips == nil {
+		l.ips = make(map[string]int)
+		l.tokens = make(map[string]int)
+	}
+	switch {
+	case l.MaxConnections > 0 && l.connections >= l.MaxConnections:
+		return nil, connectionsRejected, 0
+	case l.MaxConnectionsPerIP > 0 && l.ips[ip] >= l.MaxConnectionsPerIP:
+		return nil, ipConnectionsRejected, 0
+	case token != "" && l.MaxConnectionsPerToken > 0 && l.tokens[token] >= l.MaxConnectionsPerToken:
+		return nil, tokenConnectionsRejected, 0
+	}
+	if ok, wait := l.allowRate(ip, time.Now()); !ok {
+		return nil, rateRejected, wait
+	}
+	l.connections++
+	l.ips[ip]++
+	if token != "" {
+		l.tokens[token]++
+	}
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.connections--
+		if l.ips[ip]--; l.ips[ip] == 0 {
+			delete(l.ips, ip)
+		}
+		if token != "" {
+			if l.tokens[token]--; l.tokens[token] == 0 {
+				delete(l.tokens, token)
+			}
+		}
+	}, "", 0
+}
+
+// reject counts and responds to the rejected connection.
+func (l *limiter) reject(w http.ResponseWriter, reason string, retryAfter time.Duration) {
+	l.mu.Lock()
+	if l.rejected == nil {
+		l.rejected = make(map[string]int)
+	}
+	l.rejected[reason]++
+	l.mu.Unlock()
+
+	status := http.StatusTooManyRequests
+	var msg string
+	switch reason {
+	case originRejected:
+		status, msg = http.StatusForbidden, "origin not allowed"
+	case connectionsRejected:
+		status, msg = http.StatusServiceUnavailable, "too many connections"
+	case ipConnectionsRejected:
+		msg = "too many connections from IP address"
+	case tokenConnectionsRejected:
+		msg = "too many connections with token"
+	case rateRejected:
+		msg = "too many new connections from IP address"
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	http.Error(w, msg, status)
+}
+
+// limit rejects websocket connections that exceed the limits.
+func (l *limiter) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.IsWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ip := l.remoteIP(r)
+		if !l.checkOrigin(r) {
+			l.logger.Debug("rejected websocket", zap.String("remote_ip", ip), zap.String("reason", originRejected),
+				zap.String("origin", r.Header.Get("Origin")),
+			)
+			l.reject(w, originRejected, 0)
+			return
+		}
+		release, reason, retryAfter := l.acquire(ip, tokenOf(r))
+		if release == nil {
+			l.logger.Debug("rejected websocket", zap.String("remote_ip", ip), zap.String("reason", reason))
+			l.reject(w, reason, retryAfter)
+			return
+		}
+		defer release()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitsStatus is the status of the websocket limits.
+type LimitsStatus struct {
+	Connections int `json:"connections"`
+	// Rejected is the number of rejected websocket connections by reason.
+	Rejected map[string]int `json:"rejected"`
+}
+
+// status returns the status of the limits.
+func (l *limiter) status() LimitsStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	res := LimitsStatus{
+		Connections: l.connections,
+		Rejected:    make(map[string]int, len(l.rejected)),
+	}
+	for reason, n := range l.rejected {
+		res.Rejected[reason] = n
+	}
+	return res
+}
+
+// getMetrics writes the metrics in the Prometheus text format.
+func (h *Hub) getMetrics(w http.ResponseWriter, r *http.Request) {
+	status := h.Status()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP vantage_hub_competitions Number of followed competitions.")
+	fmt.Fprintln(w, "# TYPE vantage_hub_competitions gauge")
+	fmt.Fprintf(w, "vantage_hub_competitions %d\n", len(status.Competitions))
+	fmt.Fprintln(w, "# HELP vantage_hub_websocket_connections Number of websocket connections by route.")
+	fmt.Fprintln(w, "# TYPE vantage_hub_websocket_connections gauge")
+	for _, route := range sortedRoutes(status.Clients) {
+		fmt.Fprintf(w, "vantage_hub_websocket_connections{route=%q} %d\n", route, status.Clients[route])
+	}
+	fmt.Fprintln(w, "# HELP vantage_hub_websocket_rejections_total Number of rejected websocket connections by reason.")
+	fmt.Fprintln(w, "# TYPE vantage_hub_websocket_rejections_total counter")
+	for _, reason := range []string{
+		originRejected, connectionsRejected, ipConnectionsRejected, tokenConnectionsRejected, rateRejected,
+	} {
+		fmt.Fprintf(w, "vantage_hub_websocket_rejections_total{reason=%q} %d\n", reason, status.Limits.Rejected[reason])
+	}
+}
+
+func sortedRoutes(clients map[string]int) []string {
+	routes := make([]string, 0, len(clients))
+	for route := range clients {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
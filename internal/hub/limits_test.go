@@ -0,0 +1,208 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRemoteIP(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		trustedProxies int
+		forwarded      []string
+		expected       string
+	}{
+		{name: "NoProxy", expected: "192.0.2.1"},
+		{name: "IgnoreForwarded", forwarded: []string{"198.51.100.1"}, expected: "192.0.2.1"},
+		{name: "OneProxy", trustedProxies: 1, forwarded: []string{"198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "OneProxySpoofed", trustedProxies: 1, forwarded: []string{"203.0.113.1, 198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "TwoProxies", trustedProxies: 2, forwarded: []string{"203.0.113.1, 198.51.100.1, 198.51.100.2"}, expected: "198.51.100.1"},
+		{name: "MultipleHeaders", trustedProxies: 1, forwarded: []string{"203.0.113.1", "198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "FewerAddresses", trustedProxies: 3, forwarded: []string{"198.51.100.1"}, expected: "192.0.2.1"},
+		{name: "FewerAddressesSpoofed", trustedProxies: 3, forwarded: []string{"203.0.113.1, 198.51.100.1"}, expected: "192.0.2.1"},
+		{name: "EmptyForwarded", trustedProxies: 1, forwarded: []string{" , "}, expected: "192.0.2.1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &limiter{Limits: Limits{TrustedProxies: tc.trustedProxies}}
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			for _, forwarded := range tc.forwarded {
+				r.Header.Add("X-Forwarded-For", forwarded)
+			}
+			if ip := l.remoteIP(r); ip != tc.expected {
+				t.Errorf("remote IP is %q, want %q", ip, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		allowed  []string
+		origin   string
+		expected bool
+	}{
+		{name: "NoOrigin", expected: true},
+		{name: "SameOrigin", origin: "https://hub.example.com", expected: true},
+		{name: "OtherOrigin", origin: "https://example.com", expected: false},
+		{name: "Allowed", allowed: []string{"https://example.com"}, origin: "https://EXAMPLE.com", expected: true},
+		{name: "NotAllowed", allowed: []string{"https://example.com"}, origin: "https://example.org", expected: false},
+		{name: "AllowAll", allowed: []string{"*"}, origin: "https://example.org", expected: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &limiter{Limits: Limits{AllowedOrigins: tc.allowed}}
+			r := httptest.NewRequest(http.MethodGet, "https://hub.example.com/v1/stream", nil)
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+			if ok := l.checkOrigin(r); ok != tc.expected {
+				t.Errorf("origin %q allowed is %v, want %v", tc.origin, ok, tc.expected)
+			}
+		})
+	}
+}
+
+func TestAllowRate(t *testing.T) {
+	l := &limiter{Limits: Limits{Rate: 2, Burst: 2}}
+	now := time.Now()
+	for i, tc := range []struct {
+		after    time.Duration
+		expected bool
+	}{
+		{0, true},
+		{0, true},
+		{0, false},
+		{250 * time.Millisecond, false},
+		{500 * time.Millisecond, true},
+		{500 * time.Millisecond, false},
+		{2 * time.Second, true},
+		{2 * time.Second, true},
+		{2 * time.Second, false},
+	} {
+		ok, wait := l.allowRate("192.0.2.1", now.Add(tc.after))
+		if ok != tc.expected {
+			t.Fatalf("connection %d allowed is %v, want %v", i, ok, tc.expected)
+		}
+		if !ok && (wait <= 0 || wait > 500*time.Millisecond) {
+			t.Errorf("connection %d retry after %v", i, wait)
+		}
+	}
+	if ok, _ := l.allowRate("192.0.2.2", now); !ok {
+		t.Error("connection of other IP address is not allowed")
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		limits   Limits
+		acquire  [][2]string
+		expected []string
+	}{
+		{
+			name:     "Unlimited",
+			acquire:  [][2]string{{"a", ""}, {"a", ""}, {"b", "t"}},
+			expected: []string{"", "", ""},
+		},
+		{
+			name:     "MaxConnections",
+			limits:   Limits{MaxConnections: 2},
+			acquire:  [][2]string{{"a", ""}, {"b", ""}, {"c", ""}},
+			expected: []string{"", "", connectionsRejected},
+		},
+		{
+			name:     "MaxConnectionsPerIP",
+			limits:   Limits{MaxConnectionsPerIP: 1},
+			acquire:  [][2]string{{"a", ""}, {"a", ""}, {"b", ""}},
+			expected: []string{"", ipConnectionsRejected, ""},
+		},
+		{
+			name:     "MaxConnectionsPerToken",
+			limits:   Limits{MaxConnectionsPerToken: 1},
+			acquire:  [][2]string{{"a", "t"}, {"b", "t"}, {"b", ""}, {"c", ""}},
+			expected: []string{"", tokenConnectionsRejected, "", ""},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &limiter{Limits: tc.limits}
+			var releases []func()
+			for i, a := range tc.acquire {
+				release, reason, _ := l.acquire(a[0], a[1])
+				if reason != tc.expected[i] {
+					t.Fatalf("connection %d rejected for %q, want %q", i, reason, tc.expected[i])
+				}
+				if release != nil {
+					releases = append(releases, release)
+				}
+			}
+			for _, release := range releases {
+				release()
+			}
+			if l.connections != 0 || len(l.ips) != 0 || len(l.tokens) != 0 {
+				t.Errorf("connections not released: %d, %v, %v", l.connections, l.ips, l.tokens)
+			}
+			if release, reason, _ := l.acquire(tc.acquire[0][0], tc.acquire[0][1]); release == nil {
+				t.Errorf("connection rejected for %q after release", reason)
+			}
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	l := &limiter{
+		Limits: Limits{MaxConnectionsPerIP: 1, AllowedOrigins: []string{"https://example.com"}},
+		logger: zap.NewNop(),
+	}
+	block := make(chan struct{})
+	handler := l.limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	request := func(origin string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/v1/stream", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r.Header.Set("Connection", "upgrade")
+		r.Header.Set("Upgrade", "websocket")
+		r.Header.Set("Origin", origin)
+		return r
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request("https://example.org"))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status of disallowed origin is %d", w.Code)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), request("https://example.com"))
+		close(done)
+	}()
+	for {
+		l.mu.Lock()
+		n := l.connections
+		l.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request("https://example.com"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("status of second connection is %d", w.Code)
+	}
+	close(block)
+	<-done
+
+	status := l.status()
+	if status.Connections != 0 || status.Rejected[originRejected] != 1 || status.Rejected[ipConnectionsRejected] != 1 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/emando/vantage-events/pkg/results"
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// massStartHeat is the standings of a mass start heat.
+type massStartHeat struct {
+	Round     int                 `json:"round"`
+	Heat      int                 `json:"heat"`
+	Active    bool                `json:"active"`
+	Committed bool                `json:"committed"`
+	Standings []massStartStanding `json:"standings"`
+}
+
+// massStartStanding is the standing of a race in a mass start heat.
+type massStartStanding struct {
+	Ranking     int          `json:"ranking"`
+	RaceID      string       `json:"raceId"`
+	Lane        int          `json:"lane"`
+	Competitor  string       `json:"competitor"`
+	StartNumber int          `json:"startNumber"`
+	Nationality string       `json:"nationality"`
+	Rounds      float64      `json:"rounds"`
+	RoundsToGo  float64      `json:"roundsToGo"`
+	Points      int          `json:"points"`
+	Time        results.Time `json:"time"`
+	Finished    bool         `json:"finished"`
+}
+
+func (h *Hub) getMassStart(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var res []massStartHeat
+	h.store.Competition(vars["id"], func(c *state.Competition) {
+		d, ok := c.Distances[vars["distanceID"]]
+		if !ok || !d.MassStart() {
+			return
+		}
+		res = []massStartHeat{}
+		for _, heat := range d.Heats {
+			mh := massStartHeat{
+				Round:     heat.Key.Round,
+				Heat:      heat.Key.Number,
+				Active:    heat.Active,
+				Committed: heat.Committed,
+				Standings: []massStartStanding{},
+			}
+			for _, s := range heat.MassStartStandings(d.ClassificationPrecision) {
+				mh.Standings = append(mh.Standings, massStartStanding{
+					Ranking:     s.Ranking,
+					RaceID:      s.Race.ID,
+					Lane:        s.Race.Lane,
+					Competitor:  s.Race.Competitor.FullName,
+					StartNumber: s.Race.Competitor.StartNumber,
+					Nationality: s.Race.Competitor.NationalityCode,
+					Rounds:      s.Rounds,
+					RoundsToGo:  s.RoundsToGo,
+					Points:      s.Points,
+					Time:        results.Time{Ticks: s.Time, Text: results.FormatTime(s.Time, d.ClassificationPrecision)},
+					Finished:    s.Finished,
+				})
+			}
+			res = append(res, mh)
+		}
+	})
+	if res == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.logger.Debug("failed to write mass start standings", zap.Error(err))
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/odf"
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func (h *Hub) writeODF(w http.ResponseWriter, r *http.Request, doc *odf.Document) {
+	if doc == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	if err := doc.Write(w); err != nil {
+		h.logger.Debug("failed to write document", zap.Error(err))
+	}
+}
+
+func (h *Hub) getDistanceODF(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var doc *odf.Document
+	h.store.Competition(vars["id"], func(c *state.Competition) {
+		if d, ok := c.Distances[vars["distanceID"]]; ok {
+			doc = odf.FromDistance(c, d, time.Now())
+		}
+	})
+	h.writeODF(w, r, doc)
+}
+
+func (h *Hub) getHeatODF(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	round, _ := strconv.Atoi(vars["round"])
+	number, _ := strconv.Atoi(vars["number"])
+	var doc *odf.Document
+	h.store.Heat(vars["id"], vars["distanceID"], state.HeatKey{Round: round, Number: number}, func(c *state.Competition, d *state.Distance, heat *state.Heat) {
+		doc = odf.FromHeat(c, d, heat, time.Now())
+	})
+	h.writeODF(w, r, doc)
+}
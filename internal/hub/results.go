@@ -0,0 +1,56 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/emando/vantage-events/pkg/results"
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func (h *Hub) getCompetitionResults(w http.ResponseWriter, r *http.Request) {
+	var tables []*results.Table
+	if !h.store.Competition(mux.Vars(r)["id"], func(c *state.Competition) {
+		tables = results.FromCompetition(c)
+	}) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tables); err != nil {
+		h.logger.Debug("failed to write results", zap.Error(err))
+	}
+}
+
+func (h *Hub) getDistanceResults(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var table *results.Table
+	h.store.Competition(vars["id"], func(c *state.Competition) {
+		if d, ok := c.Distances[vars["distanceID"]]; ok {
+			table = results.FromDistance(c, d)
+		}
+	})
+	if table == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var err error
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		err = table.WriteJSON(w)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		err = table.WriteCSV(w)
+	default:
+		http.Error(w, "invalid format", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.Debug("failed to write results", zap.Error(err))
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/emando/vantage-events/internal/follower"
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+	"go.uber.org/zap"
+)
+
+// emptyCompetitionSource is a source of a competition without competition events, with a distance with two replayed
+// events.
+type emptyCompetitionSource struct {
+	t *testing.T
+}
+
+// rawEvents returns a replay of the events, which are all replayed.
+func rawEvents(ctx context.Context, t *testing.T, in ...string) *events.Replay {
+	ch := make(chan *events.Raw)
+	go func() {
+		for i, buf := range in {
+			event := &events.Raw{Bytes: []byte(buf), Sequence: uint64(i + 1)}
+			if err := json.Unmarshal(event.Bytes, event); err != nil {
+				t.Error(err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- event:
+			}
+		}
+	}()
+	return &events.Replay{Events: ch, Last: uint64(len(in))}
+}
+
+func (emptyCompetitionSource) CompetitionActivations(ctx context.Context, history time.Duration) (<-chan *events.CompetitionActivated, error) {
+	ch := make(chan *events.CompetitionActivated, 1)
+	ch <- &events.CompetitionActivated{
+		Competition: events.Competition{CompetitionID: "c1"},
+		Value:       entities.Competition{ID: "c1"},
+		Time:        time.Now(),
+		Raw:         []byte(competitionActivated),
+	}
+	return ch, nil
+}
+
+func (emptyCompetitionSource) CompetitionEvents(ctx context.Context, since *events.CompetitionActivated, after uint64) (*events.Replay, error) {
+	return &events.Replay{Events: make(chan *events.Raw)}, nil
+}
+
+func (emptyCompetitionSource) DistanceActivations(ctx context.Context, competitionID string) (*events.DistanceActivations, error) {
+	ch, replayed := make(chan *events.DistanceActivated), make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case ch <- &events.DistanceActivated{
+			Distance: events.Distance{Competition: events.Competition{CompetitionID: "c1"}, DistanceID: "d1"},
+			Value:    entities.Distance{ID: "d1"},
+			Time:     time.Now(),
+			Raw:      []byte(distanceActivated),
+		}:
+			close(replayed)
+		}
+	}()
+	return &events.DistanceActivations{Activations: ch, Replayed: replayed}, nil
+}
+
+func (s emptyCompetitionSource) DistanceEvents(ctx context.Context, since *events.DistanceActivated, after uint64) (*events.Replay, error) {
+	return rawEvents(ctx, s.t, distanceEvent, secondDistanceEvent), nil
+}
+
+func (emptyCompetitionSource) HeatActivations(ctx context.Context, competitionID, distanceID string, groups ...int) (*events.HeatActivations, error) {
+	replayed := make(chan struct{})
+	close(replayed)
+	return &events.HeatActivations{Activations: make(chan *events.HeatActivated), Replayed: replayed}, nil
+}
+
+func (emptyCompetitionSource) HeatEvents(ctx context.Context, since *events.HeatActivated, after uint64) (*events.Replay, error) {
+	return &events.Replay{Events: make(chan *events.Raw)}, nil
+}
+
+// secondDistanceEvent is the second replayed distance event of the emptyCompetitionSource.
+const secondDistanceEvent = `{"typeName":"DistanceUpdatedEvent","competitionId":"c1","distanceId":"d1","_cursor":"competition.c1.distances.d1:2"}`
+
+func TestResumeFollowed(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cursor   string
+		expected []string
+	}{
+		{
+			name:     "FirstDistanceEvent",
+			cursor:   "competition.c1.distances.d1:1",
+			expected: []string{events.StreamLiveType, secondDistanceEvent, events.StreamLiveType},
+		},
+		{
+			name:     "LastDistanceEvent",
+			cursor:   "competition.c1.distances.d1:2",
+			expected: []string{events.StreamLiveType, events.StreamLiveType},
+		},
+		{
+			name:   "NotFound",
+			cursor: "competition.c1.distances.d1:3",
+			expected: []string{
+				events.StreamResetType, competitionActivated, distanceActivated, events.StreamLiveType,
+				distanceEvent, secondDistanceEvent, events.StreamLiveType,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			f := follower.Follower{Logger: zap.NewNop(), Source: emptyCompetitionSource{t: t}}
+			competitions, err := f.Run(ctx, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := resume(ctx, zap.NewNop(), follower.Raw(ctx, competitions), tc.cursor)
+			for i, expected := range tc.expected {
+				var buf []byte
+				select {
+				case buf = <-out:
+				case <-time.After(time.Second):
+					t.Fatalf("event %d not received, want %s", i, expected)
+				}
+				var header events.Base
+				if err := json.Unmarshal(buf, &header); err != nil {
+					t.Fatal(err)
+				}
+				switch header.TypeName() {
+				case events.StreamLiveType, events.StreamResetType:
+					if header.TypeName() != expected {
+						t.Errorf("event %d is %s, want %s", i, buf, expected)
+					}
+				default:
+					if string(buf) != expected {
+						t.Errorf("event %d is %s, want %s", i, buf, expected)
+					}
+				}
+			}
+		})
+	}
+}
@@ -4,17 +4,28 @@ package hub
 
 import (
 	"context"
+	"errors"
+	"net"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/emando/vantage-events/internal/archive"
 	"github.com/emando/vantage-events/internal/follower"
+	"github.com/emando/vantage-events/internal/quality"
 	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
-var upgrader = websocket.Upgrader{}
+// upgrader negotiates permessage-deflate compression with clients that support it. Origins are checked by the limiter.
+var upgrader = websocket.Upgrader{
+	EnableCompression: true,
+	CheckOrigin:       func(*http.Request) bool { return true },
+}
 
 // Hub is a websocket hub to distribute events to subscribers.
 type Hub struct {
@@ -23,17 +34,69 @@ type Hub struct {
 	address,
 	certFile,
 	keyFile string
+	store   *state.Store
+	topics  topics
+	archive *archive.Archive
+	monitor *quality.Monitor
+	version string
+	checks  []namedCheck
+	started time.Time
+	// instance and leader are set in cluster mode.
+	instance string
+	leader   func() bool
+	// listening is set to 1 when the Hub listens.
+	listening int32
+	activity  activity
+	limiter   limiter
+
+	lifecycleMu sync.Mutex
+	lifecycle   []follower.Lifecycle
+}
+
+// Option configures the Hub.
+type Option func(*Hub)
+
+// WithStore configures the Hub to serve the state of competitions from the store.
+func WithStore(store *state.Store) Option {
+	return func(h *Hub) {
+		h.store = store
+	}
 }
 
 // NewServer instantiates a new Hub.
-func NewServer(logger *zap.Logger, source events.Source, address, certFile, keyFile string) *Hub {
-	return &Hub{
+func NewServer(logger *zap.Logger, source events.Source, address, certFile, keyFile string, opts ...Option) *Hub {
+	h := &Hub{
 		logger:   logger,
 		source:   source,
 		address:  address,
 		certFile: certFile,
 		keyFile:  keyFile,
+		version:  "dev",
+		started:  time.Now(),
+	}
+	h.limiter.logger = logger
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+const (
+	defaultWindow = 24 * time.Hour
+	maxWindow     = 31 * 24 * time.Hour
+)
+
+// windowOf returns the time window of the request, or def if the request has no window.
+func windowOf(r *http.Request, def time.Duration) (time.Duration, error) {
+	s := r.URL.Query().Get("window")
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 || d > maxWindow {
+		return 0, errors.New("invalid window")
 	}
+	return d, nil
 }
 
 const (
@@ -83,11 +146,22 @@ func readPongs(ctx context.Context, logger *zap.Logger, ws *websocket.Conn) erro
 func (h *Hub) getCompetitions(w http.ResponseWriter, r *http.Request) {
 	// TODO: Authenticate via Vantage API.
 
+	window, err := windowOf(r, defaultWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	enc, header, err := encodingOf(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
 	logger := h.logger.With(zap.String("remote_address", r.RemoteAddr))
-	c, err := upgrader.Upgrade(w, r, nil)
+	c, err := upgrader.Upgrade(w, r, header)
 	if err != nil {
 		logger.Debug("failed to upgrade websocket", zap.Error(err))
 		return
@@ -96,7 +170,7 @@ func (h *Hub) getCompetitions(w http.ResponseWriter, r *http.Request) {
 
 	go writePings(ctx, logger, c)
 
-	ch, err := h.source.CompetitionActivations(ctx, 24*time.Hour)
+	ch, err := h.source.CompetitionActivations(ctx, window)
 	if err != nil {
 		logger.Debug("failed to follow competition activations", zap.Error(err))
 		return
@@ -108,11 +182,20 @@ func (h *Hub) getCompetitions(w http.ResponseWriter, r *http.Request) {
 			select {
 			case <-ctx.Done():
 				return
-			case activation := <-ch:
+			case activation, ok := <-ch:
+				if !ok {
+					c.Close()
+					return
+				}
 				if _, ok := sent[activation.CompetitionID]; ok {
 					continue
 				}
-				if err := c.WriteMessage(websocket.TextMessage, activation.Raw); err != nil {
+				messageType, data, err := enc.message(activation.Raw)
+				if err != nil {
+					logger.Warn("failed to encode message", zap.Error(err))
+					continue
+				}
+				if err := c.WriteMessage(messageType, data); err != nil {
 					logger.Debug("failed to write message", zap.Error(err))
 					return
 				}
@@ -130,11 +213,37 @@ func (h *Hub) getCompetitions(w http.ResponseWriter, r *http.Request) {
 func (h *Hub) getCompetition(w http.ResponseWriter, r *http.Request) {
 	// TODO: Authenticate via Vantage API.
 
+	derivers, err := derivedOf(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mode, err := modeOf(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	interval, err := rateOf(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" && mode == snapshotMode {
+		http.Error(w, "cursor is not supported in snapshot mode", http.StatusBadRequest)
+		return
+	}
+	enc, header, err := encodingOf(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
 	logger := h.logger.With(zap.String("remote_address", r.RemoteAddr))
-	c, err := upgrader.Upgrade(w, r, nil)
+	c, err := upgrader.Upgrade(w, r, header)
 	if err != nil {
 		logger.Debug("failed to upgrade websocket", zap.Error(err))
 		return
@@ -143,18 +252,29 @@ func (h *Hub) getCompetition(w http.ResponseWriter, r *http.Request) {
 
 	go writePings(ctx, logger, c)
 
-	follower := &follower.Follower{
+	f := &follower.Follower{
 		Logger: logger,
 		Source: h.source,
 	}
-	eventsCh, err := follower.Run(ctx, 24*time.Hour, mux.Vars(r)["id"])
+	eventsCh, err := f.Run(ctx, 24*time.Hour, mux.Vars(r)["id"])
 	if err != nil {
 		logger.Debug("failed to run follower", zap.Error(err))
 		return
 	}
 
-	outCh := make(chan []byte)
-	go followRawEvents(ctx, eventsCh, outCh)
+	outCh := follower.Raw(ctx, eventsCh)
+	if len(derivers) > 0 {
+		outCh = derive(ctx, logger, outCh, derivers)
+	}
+	if cursor != "" {
+		outCh = resume(ctx, logger, outCh, cursor)
+	}
+	if mode == snapshotMode {
+		outCh = snapshot(ctx, logger, outCh)
+	}
+	if interval > 0 {
+		outCh = batch(ctx, logger, outCh, interval)
+	}
 
 	go func() {
 		for {
@@ -162,7 +282,12 @@ func (h *Hub) getCompetition(w http.ResponseWriter, r *http.Request) {
 			case <-ctx.Done():
 				return
 			case buf := <-outCh:
-				if err := c.WriteMessage(websocket.TextMessage, buf); err != nil {
+				messageType, data, err := enc.message(buf)
+				if err != nil {
+					logger.Warn("failed to encode message", zap.Error(err))
+					continue
+				}
+				if err := c.WriteMessage(messageType, data); err != nil {
 					logger.Debug("failed to write message", zap.Error(err))
 					return
 				}
@@ -176,73 +301,54 @@ func (h *Hub) getCompetition(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func followRawEvents(ctx context.Context, inCh <-chan *follower.CompetitionEvents, outCh chan<- []byte) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case competition, ok := <-inCh:
-			if !ok {
-				return
-			}
-			outCh <- competition.RawActivation
-			go func() {
-				for {
-					select {
-					case <-ctx.Done():
-						return
-					case event, ok := <-competition.RawEvents:
-						if !ok {
-							return
-						}
-						outCh <- event
-					case distance, ok := <-competition.DistanceEvents:
-						if !ok {
-							return
-						}
-						outCh <- distance.RawActivation
-						go func() {
-							for {
-								select {
-								case <-ctx.Done():
-									return
-								case event, ok := <-competition.RawEvents:
-									if !ok {
-										return
-									}
-									outCh <- event
-								case heat, ok := <-distance.HeatEvents:
-									if !ok {
-										return
-									}
-									outCh <- heat.RawActivation
-									go func() {
-										for {
-											select {
-											case <-ctx.Done():
-												return
-											case event, ok := <-heat.RawEvents:
-												if !ok {
-													return
-												}
-												outCh <- event
-											}
-										}
-									}()
-								}
-							}
-						}()
-					}
-				}
-			}()
-		}
-	}
-}
-
 // ListenAndServeTLS starts the websocket hub.
 func (h *Hub) ListenAndServeTLS() error {
 	r := mux.NewRouter()
+	r.Use(h.limiter.limit)
+	r.Use(h.activity.countClients)
+	r.HandleFunc("/healthz", h.getHealth).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", h.getReadiness).Methods(http.MethodGet)
+	r.HandleFunc("/status", h.getStatus).Methods(http.MethodGet)
+	r.HandleFunc("/metrics", h.getMetrics).Methods(http.MethodGet)
 	r.HandleFunc("/v1/competitions", h.getCompetitions)
 	r.HandleFunc("/v1/competitions/{id}", h.getCompetition)
-	return http.ListenAndServeTLS(h.address, h.certFile, h.keyFile, r)
+	r.HandleFunc("/v2/stream", h.getStream)
+	r.HandleFunc("/v1/follower/lifecycle", h.getLifecycle).Methods(http.MethodGet)
+	r.HandleFunc("/v1/follower/lifecycle/stream", h.getLifecycleStream)
+	if _, ok := h.source.(events.StatusReporter); ok {
+		r.HandleFunc("/v1/source/status", h.getSourceStatus).Methods(http.MethodGet)
+	}
+	if h.store != nil {
+		r.HandleFunc("/v1/directory", h.getDirectory).Methods(http.MethodGet)
+		r.HandleFunc("/v1/directory/stream", h.getDirectoryStream)
+		r.HandleFunc("/v1/competitions/{id}/results", h.getCompetitionResults).Methods(http.MethodGet)
+		r.HandleFunc("/v1/competitions/{id}/distances/{distanceID}/results", h.getDistanceResults).Methods(http.MethodGet)
+		r.HandleFunc("/v1/competitions/{id}/distances/{distanceID}/splits", h.getDistanceSplits).Methods(http.MethodGet)
+		r.HandleFunc("/v1/competitions/{id}/distances/{distanceID}/massstart", h.getMassStart).Methods(http.MethodGet)
+		r.HandleFunc("/v1/competitions/{id}/distances/{distanceID}/leaderboard", h.getLeaderboard).Methods(http.MethodGet)
+		r.HandleFunc("/v1/competitions/{id}/distances/{distanceID}/leaderboard/stream", h.getLeaderboardStream)
+		r.HandleFunc("/v1/competitions/{id}/distances/{distanceID}/odf", h.getDistanceODF).Methods(http.MethodGet)
+		r.HandleFunc("/v1/competitions/{id}/distances/{distanceID}/heats/{round:[0-9]+}/{number:[0-9]+}/odf", h.getHeatODF).Methods(http.MethodGet)
+	}
+	if h.archive != nil {
+		r.HandleFunc("/v1/archive/competitions", h.getArchivedCompetitions).Methods(http.MethodGet)
+		r.HandleFunc("/v1/archive/competitions/{id}", h.getArchivedCompetition).Methods(http.MethodGet)
+		r.HandleFunc("/v1/archive/competitions/{id}/events", h.getArchivedEvents).Methods(http.MethodGet)
+		r.HandleFunc("/v1/archive/competitions/{id}/stream", h.getArchivedEventStream)
+		r.HandleFunc("/v1/archive/competitions/{id}/distances/{distanceID}/events", h.getArchivedEvents).Methods(http.MethodGet)
+		r.HandleFunc("/v1/archive/competitions/{id}/distances/{distanceID}/stream", h.getArchivedEventStream)
+	}
+	if h.monitor != nil {
+		r.HandleFunc("/v1/alerts", h.getAlerts).Methods(http.MethodGet)
+		r.HandleFunc("/v1/alerts/stream", h.getAlertStream)
+	}
+	ln, err := net.Listen("tcp", h.address)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	atomic.StoreInt32(&h.listening, 1)
+	defer atomic.StoreInt32(&h.listening, 0)
+	server := &http.Server{Handler: r}
+	return server.ServeTLS(ln, h.certFile, h.keyFile)
 }
@@ -0,0 +1,208 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/emando/vantage-events/internal/follower"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
+	"go.uber.org/zap"
+)
+
+// Stream modes that clients select with the mode query parameter.
+const (
+	// replayMode replays the events of the competition, distances and heats, each followed by a StreamLiveEvent.
+	replayMode = "replay"
+	// snapshotMode collapses the replayed events into a StreamSnapshotEvent.
+	snapshotMode = "snapshot"
+)
+
+// modeOf returns the stream mode selected in the request, i.e. ?mode=snapshot.
+func modeOf(r *http.Request) (string, error) {
+	return parseMode(r.URL.Query().Get("mode"))
+}
+
+// parseMode parses the stream mode. The default is replay.
+func parseMode(mode string) (string, error) {
+	switch mode {
+	case "", replayMode:
+		return replayMode, nil
+	case snapshotMode:
+		return snapshotMode, nil
+	default:
+		return "", fmt.Errorf("invalid mode %q", mode)
+	}
+}
+
+// streamHeader is the header of events and StreamLiveEvents.
+type streamHeader struct {
+	events.Heat
+	Level string `json:"level"`
+}
+
+// replayKey returns the key of the replay of the competition, distance or heat.
+func (h streamHeader) replayKey(level string) string {
+	switch level {
+	case events.CompetitionLevel:
+		return h.CompetitionID
+	case events.DistanceLevel:
+		return fmt.Sprintf("%s/%s", h.CompetitionID, h.DistanceID)
+	default:
+		return fmt.Sprintf("%s/%s/%d/%d", h.CompetitionID, h.DistanceID, h.Key.Round, h.Key.Number)
+	}
+}
+
+// resume skips the replayed events up to and including the event with the cursor. StreamLiveEvents are kept. If the
+// replay is delivered without the cursor, a StreamResetEvent is sent followed by the full replay.
+func resume(ctx context.Context, logger *zap.Logger, in <-chan []byte, cursor string) <-chan []byte {
+	out := make(chan []byte)
+	send := func(buf []byte) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- buf:
+			return true
+		}
+	}
+	go func() {
+		var (
+			replays follower.Replays
+			pending [][]byte
+			resumed bool
+		)
+		for {
+			var buf []byte
+			select {
+			case <-ctx.Done():
+				return
+			case buf = <-in:
+			}
+			if resumed {
+				if !send(buf) {
+					return
+				}
+				continue
+			}
+			if err := replays.Update(buf); err != nil {
+				logger.Debug("failed to unmarshal event", zap.Error(err))
+			}
+			pending = append(pending, buf)
+			if events.Cursor(buf) == cursor {
+				resumed = true
+				for _, buf := range pending {
+					var header events.Base
+					if err := json.Unmarshal(buf, &header); err == nil && header.TypeName() == events.StreamLiveType {
+						if !send(buf) {
+							return
+						}
+					}
+				}
+				pending = nil
+				continue
+			}
+			if !replays.Delivered() {
+				continue
+			}
+			logger.Debug("cursor not found", zap.String("cursor", cursor))
+			resumed = true
+			reset := events.StreamReset{Cursor: cursor}
+			reset.Type = events.StreamResetType
+			reset.CompetitionID = replays.CompetitionID()
+			buf, err := json.Marshal(reset)
+			if err != nil || !send(buf) {
+				return
+			}
+			for _, buf := range pending {
+				if !send(buf) {
+					return
+				}
+			}
+			pending = nil
+		}
+	}()
+	return out
+}
+
+// snapshot collapses the replayed events into a StreamSnapshotEvent with the state of the competition, followed by a
+// StreamLiveEvent of the competition. The snapshot is sent when the replays of the competition and of all distances and
+// heats activated during the replay are delivered. Live events are passed through.
+func snapshot(ctx context.Context, logger *zap.Logger, in <-chan []byte) <-chan []byte {
+	out := make(chan []byte)
+	send := func(buf []byte) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- buf:
+			return true
+		}
+	}
+	go func() {
+		store := state.NewStore()
+		var (
+			replays   follower.Replays
+			cursor    string
+			replaying = true
+		)
+		for {
+			var buf []byte
+			select {
+			case <-ctx.Done():
+				return
+			case buf = <-in:
+			}
+			if !replaying {
+				if !send(buf) {
+					return
+				}
+				continue
+			}
+			var header events.Base
+			if err := json.Unmarshal(buf, &header); err != nil {
+				logger.Debug("failed to unmarshal event", zap.Error(err))
+				continue
+			}
+			if err := replays.Update(buf); err != nil {
+				logger.Debug("failed to unmarshal event", zap.Error(err))
+				continue
+			}
+			if header.TypeName() != events.StreamLiveType {
+				if err := store.Apply(buf); err != nil {
+					logger.Debug("failed to apply event", zap.Error(err))
+				}
+				cursor = events.Cursor(buf)
+			}
+			if !replays.Delivered() {
+				continue
+			}
+			competitionID := replays.CompetitionID()
+			replaying = false
+			event := state.StreamSnapshot{}
+			event.Type = state.StreamSnapshotType
+			event.CompetitionID = competitionID
+			event.Cursor = cursor
+			store.Competition(competitionID, func(c *state.Competition) {
+				event.State = c.Snapshot()
+			})
+			buf, err := json.Marshal(event)
+			if err != nil {
+				logger.Warn("failed to marshal snapshot", zap.Error(err))
+				return
+			}
+			if !send(buf) {
+				return
+			}
+			live := events.StreamLive{Level: events.CompetitionLevel}
+			live.Type = events.StreamLiveType
+			live.CompetitionID = competitionID
+			if buf, err = json.Marshal(live); err != nil || !send(buf) {
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,168 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
+	"go.uber.org/zap"
+)
+
+// Events of a stream with the replay of a competition with a distance and a heat, followed by a live event.
+var (
+	competitionActivated = `{"typeName":"CompetitionActivatedEvent","competitionId":"c1","competition":{"id":"c1"},"_cursor":"competition.activations:1"}`
+	competitionEvent     = `{"typeName":"CompetitionUpdatedEvent","competitionId":"c1","_cursor":"competition.c1:1"}`
+	competitionLive      = `{"typeName":"StreamLiveEvent","competitionId":"c1","level":"competition"}`
+	distanceActivated    = `{"typeName":"DistanceActivatedEvent","competitionId":"c1","distanceId":"d1","distance":{"id":"d1"},"_cursor":"competition.c1.distances.activations:1"}`
+	distanceEvent        = `{"typeName":"DistanceUpdatedEvent","competitionId":"c1","distanceId":"d1","_cursor":"competition.c1.distances.d1:1"}`
+	distanceLive         = `{"typeName":"StreamLiveEvent","competitionId":"c1","distanceId":"d1","level":"distance"}`
+	heatActivated        = `{"typeName":"HeatActivatedEvent","competitionId":"c1","distanceId":"d1","heat":{"round":1,"number":2},"_cursor":"competition.c1.distances.d1.heats.activations.1:1"}`
+	heatEvent            = `{"typeName":"HeatUpdatedEvent","competitionId":"c1","distanceId":"d1","heat":{"round":1,"number":2},"_cursor":"competition.c1.distances.d1.heats.1.2:1"}`
+	heatLive             = `{"typeName":"StreamLiveEvent","competitionId":"c1","distanceId":"d1","heat":{"round":1,"number":2},"level":"heat"}`
+	liveEvent            = `{"typeName":"HeatUpdatedEvent","competitionId":"c1","distanceId":"d1","heat":{"round":1,"number":2},"_cursor":"competition.c1.distances.d1.heats.1.2:2"}`
+)
+
+// replayed is the stream of the events.
+var replayed = []string{
+	competitionActivated, competitionEvent, distanceActivated, competitionLive,
+	distanceEvent, heatActivated, distanceLive, heatEvent, heatLive, liveEvent,
+}
+
+// pipe sends the events on a channel and returns the events that the stage sends within a short time.
+func pipe(t *testing.T, stage func(ctx context.Context, in <-chan []byte) <-chan []byte, in []string) []string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inCh := make(chan []byte)
+	outCh := stage(ctx, inCh)
+	go func() {
+		for _, event := range in {
+			select {
+			case <-ctx.Done():
+				return
+			case inCh <- []byte(event):
+			}
+		}
+	}()
+	var res []string
+	for {
+		select {
+		case buf := <-outCh:
+			res = append(res, string(buf))
+		case <-time.After(100 * time.Millisecond):
+			return res
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	reset := func(cursor string) string {
+		return fmt.Sprintf(`{"typeName":"StreamResetEvent","competitionId":"c1","cursor":%q}`, cursor)
+	}
+	for _, tc := range []struct {
+		name     string
+		cursor   string
+		expected []string
+	}{
+		{
+			name:     "Activation",
+			cursor:   "competition.activations:1",
+			expected: replayed[1:],
+		},
+		{
+			name:     "Distance",
+			cursor:   "competition.c1.distances.d1:1",
+			expected: []string{competitionLive, heatActivated, distanceLive, heatEvent, heatLive, liveEvent},
+		},
+		{
+			name:     "LastReplayed",
+			cursor:   "competition.c1.distances.d1.heats.1.2:1",
+			expected: []string{competitionLive, distanceLive, heatLive, liveEvent},
+		},
+		{
+			name:     "NotFound",
+			cursor:   "competition.c1:42",
+			expected: append([]string{reset("competition.c1:42")}, replayed...),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := pipe(t, func(ctx context.Context, in <-chan []byte) <-chan []byte {
+				return resume(ctx, zap.NewNop(), in, tc.cursor)
+			}, replayed)
+			if len(res) != len(tc.expected) {
+				t.Fatalf("received %d events, want %d: %v", len(res), len(tc.expected), res)
+			}
+			for i, event := range res {
+				if event != tc.expected[i] {
+					t.Errorf("event %d is %s, want %s", i, event, tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		in        []string
+		snapshots int
+		cursor    string
+		live      []string
+	}{
+		{
+			name:      "Replay",
+			in:        replayed,
+			snapshots: 1,
+			cursor:    "competition.c1.distances.d1.heats.1.2:1",
+			live:      []string{liveEvent},
+		},
+		{
+			name:      "PendingHeat",
+			in:        replayed[:len(replayed)-2],
+			snapshots: 0,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := pipe(t, func(ctx context.Context, in <-chan []byte) <-chan []byte {
+				return snapshot(ctx, zap.NewNop(), in)
+			}, tc.in)
+			var snapshots int
+			var live []string
+			for i, buf := range res {
+				var header events.Base
+				if err := json.Unmarshal([]byte(buf), &header); err != nil {
+					t.Fatal(err)
+				}
+				switch header.TypeName() {
+				case state.StreamSnapshotType:
+					snapshots++
+					var event state.StreamSnapshot
+					if err := json.Unmarshal([]byte(buf), &event); err != nil {
+						t.Fatal(err)
+					}
+					if event.CompetitionID != "c1" || event.Cursor != tc.cursor {
+						t.Errorf("snapshot of %q with cursor %q", event.CompetitionID, event.Cursor)
+					}
+					if i+1 >= len(res) || res[i+1] != competitionLive {
+						t.Error("snapshot is not followed by StreamLiveEvent of the competition")
+					}
+				case events.StreamLiveType:
+				default:
+					live = append(live, buf)
+				}
+			}
+			if snapshots != tc.snapshots {
+				t.Errorf("received %d snapshots, want %d", snapshots, tc.snapshots)
+			}
+			if len(live) != len(tc.live) {
+				t.Errorf("received live events %v, want %v", live, tc.live)
+			}
+		})
+	}
+}
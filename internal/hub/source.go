@@ -0,0 +1,22 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/emando/vantage-events/pkg/events"
+	"go.uber.org/zap"
+)
+
+func (h *Hub) getSourceStatus(w http.ResponseWriter, r *http.Request) {
+	status := h.source.(events.StatusReporter).Status()
+	w.Header().Set("Content-Type", "application/json")
+	if status.State != events.Connected {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		h.logger.Debug("failed to write source status", zap.Error(err))
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/emando/vantage-events/pkg/analysis"
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// raceSplits are the split analyses of a race.
+type raceSplits struct {
+	RaceID     string           `json:"raceId"`
+	Round      int              `json:"round"`
+	Heat       int              `json:"heat"`
+	Lane       int              `json:"lane"`
+	Competitor string           `json:"competitor"`
+	Splits     []analysis.Split `json:"splits"`
+}
+
+func (h *Hub) getDistanceSplits(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var res []raceSplits
+	h.store.Competition(vars["id"], func(c *state.Competition) {
+		d, ok := c.Distances[vars["distanceID"]]
+		if !ok {
+			return
+		}
+		res = []raceSplits{}
+		for _, heat := range d.Heats {
+			for _, race := range heat.Races {
+				res = append(res, raceSplits{
+					RaceID:     race.ID,
+					Round:      heat.Key.Round,
+					Heat:       heat.Key.Number,
+					Lane:       race.Lane,
+					Competitor: race.Competitor.FullName,
+					Splits:     analysis.Splits(d, heat, race),
+				})
+			}
+		}
+	})
+	if res == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.logger.Debug("failed to write splits", zap.Error(err))
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/emando/vantage-events/internal/follower"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// WithVersion configures the version that the Hub reports in its status.
+func WithVersion(version string) Option {
+	return func(h *Hub) {
+		h.version = version
+	}
+}
+
+// WithCheck configures the Hub to report not ready while the check fails.
+func WithCheck(name string, check func() error) Option {
+	return func(h *Hub) {
+		h.checks = append(h.checks, namedCheck{name, check})
+	}
+}
+
+// WithCluster configures the name of the instance in the cluster and whether it is the leader, that the Hub reports in
+// its status.
+func WithCluster(instance string, leader func() bool) Option {
+	return func(h *Hub) {
+		h.instance = instance
+		h.leader = leader
+	}
+}
+
+type namedCheck struct {
+	name  string
+	check func() error
+}
+
+// activity keeps track of the followed competitions, distances and heats, and the connected clients.
+type activity struct {
+	mu           sync.Mutex
+	competitions map[string]*competitionActivity
+	clients      map[string]int
+}
+
+type competitionActivity struct {
+	distances map[string]struct{}
+	heats     map[heatActivity]struct{}
+	lastEvent time.Time
+}
+
+type heatActivity struct {
+	distanceID    string
+	round, number int
+}
+
+// competition returns the activity of the competition. The lock must be held.
+func (a *activity) competition(id string) *competitionActivity {
+	if a.competitions == nil {
+		a.competitions = make(map[string]*competitionActivity)
+	}
+	c, ok := a.competitions[id]
+	if !ok {
+		c = &competitionActivity{
+			distances: make(map[string]struct{}),
+			heats:     make(map[heatActivity]struct{}),
+		}
+		a.competitions[id] = c
+	}
+	return c
+}
+
+// track tracks the followed competitions, distances and heats by the follower lifecycle event.
+func (a *activity) track(l follower.Lifecycle) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	heat := heatActivity{l.DistanceID, l.HeatRound, l.HeatNumber}
+	switch l.Type {
+	case follower.CompetitionFollowed:
+		a.competition(l.CompetitionID)
+	case follower.CompetitionSuperseded:
+		c := a.competition(l.CompetitionID)
+		c.distances = make(map[string]struct{})
+		c.heats = make(map[heatActivity]struct{})
+	case follower.DistanceFollowed:
+		a.competition(l.CompetitionID).distances[l.DistanceID] = struct{}{}
+	case follower.DistanceSuperseded, follower.DistanceEnded:
+		c := a.competition(l.CompetitionID)
+		delete(c.distances, l.DistanceID)
+		for h := range c.heats {
+			if h.distanceID == l.DistanceID {
+				delete(c.heats, h)
+			}
+		}
+	case follower.HeatFollowed:
+		a.competition(l.CompetitionID).heats[heat] = struct{}{}
+	case follower.HeatSuperseded, follower.HeatEnded:
+		delete(a.competition(l.CompetitionID).heats, heat)
+	}
+}
+
+// received sets the time of the last event of the competition.
+func (a *activity) received(competitionID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.competition(competitionID).lastEvent = time.Now()
+}
+
+// countClients counts the websocket clients by route.
+func (a *activity) countClients(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.IsWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var route string
+		if current := mux.CurrentRoute(r); current != nil {
+			route, _ = current.GetPathTemplate()
+		}
+		a.mu.Lock()
+		if a.clients == nil {
+			a.clients = make(map[string]int)
+		}
+		a.clients[route]++
+		a.mu.Unlock()
+		defer func() {
+			a.mu.Lock()
+			if a.clients[route]--; a.clients[route] == 0 {
+				delete(a.clients, route)
+			}
+			a.mu.Unlock()
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Status is the status of the Hub.
+type Status struct {
+	Version      string               `json:"version"`
+	Started      time.Time            `json:"started"`
+	Uptime       string               `json:"uptime"`
+	Source       *events.SourceStatus `json:"source,omitempty"`
+	Cluster      *ClusterStatus       `json:"cluster,omitempty"`
+	Competitions []CompetitionStatus  `json:"competitions"`
+	Distances    int                  `json:"distances"`
+	Heats        int                  `json:"heats"`
+	// Clients is the number of connected websocket clients by route.
+	Clients map[string]int `json:"clients"`
+	Limits  LimitsStatus   `json:"limits"`
+}
+
+// ClusterStatus is the status of the instance in the cluster.
+type ClusterStatus struct {
+	Instance string `json:"instance"`
+	Leader   bool   `json:"leader"`
+}
+
+// CompetitionStatus is the status of a followed competition.
+type CompetitionStatus struct {
+	ID        string     `json:"id"`
+	Distances int        `json:"distances"`
+	Heats     int        `json:"heats"`
+	LastEvent *time.Time `json:"lastEvent,omitempty"`
+}
+
+// Status returns the status of the Hub.
+func (h *Hub) Status() Status {
+	status := Status{
+		Version:      h.version,
+		Started:      h.started,
+		Uptime:       time.Since(h.started).Round(time.Second).String(),
+		Competitions: []CompetitionStatus{},
+		Clients:      make(map[string]int),
+	}
+	if r, ok := h.source.(events.StatusReporter); ok {
+		s := r.Status()
+		status.Source = &s
+	}
+	status.Limits = h.limiter.status()
+	if h.leader != nil {
+		status.Cluster = &ClusterStatus{
+			Instance: h.instance,
+			Leader:   h.leader(),
+		}
+	}
+	h.activity.mu.Lock()
+	defer h.activity.mu.Unlock()
+	for id, c := range h.activity.competitions {
+		cs := CompetitionStatus{
+			ID:        id,
+			Distances: len(c.distances),
+			Heats:     len(c.heats),
+		}
+		if !c.lastEvent.IsZero() {
+			lastEvent := c.lastEvent
+			cs.LastEvent = &lastEvent
+		}
+		status.Competitions = append(status.Competitions, cs)
+		status.Distances += cs.Distances
+		status.Heats += cs.Heats
+	}
+	sort.Slice(status.Competitions, func(i, j int) bool {
+		return status.Competitions[i].ID < status.Competitions[j].ID
+	})
+	for route, n := range h.activity.clients {
+		status.Clients[route] = n
+	}
+	return status
+}
+
+func (h *Hub) getHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok\n"))
+}
+
+func (h *Hub) getReadiness(w http.ResponseWriter, r *http.Request) {
+	res := map[string]string{"hub": "ok"}
+	ready := true
+	if atomic.LoadInt32(&h.listening) == 0 {
+		res["hub"] = "not listening"
+		ready = false
+	}
+	for _, c := range h.checks {
+		if err := c.check(); err != nil {
+			res[c.name] = err.Error()
+			ready = false
+		} else {
+			res[c.name] = "ok"
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.logger.Debug("failed to write readiness", zap.Error(err))
+	}
+}
+
+func (h *Hub) getStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.Status()); err != nil {
+		h.logger.Debug("failed to write status", zap.Error(err))
+	}
+}
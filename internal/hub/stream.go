@@ -0,0 +1,352 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/emando/vantage-events/internal/follower"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
+	"go.uber.org/zap"
+)
+
+// maxSubscriptions is the maximum number of subscriptions per stream connection.
+const maxSubscriptions = 32
+
+// Stream commands.
+const (
+	subscribeCommand   = "subscribe"
+	unsubscribeCommand = "unsubscribe"
+)
+
+// Stream message types.
+const (
+	ackMessage   = "ack"
+	errorMessage = "error"
+	eventMessage = "event"
+)
+
+// streamCommand is a command of a stream client.
+type streamCommand struct {
+	// ID is the ID of the command that is returned in the ack or error.
+	ID           string `json:"id"`
+	Command      string `json:"command"`
+	Subscription string `json:"subscription"`
+
+	CompetitionID string                 `json:"competitionId"`
+	DistanceID    string                 `json:"distanceId"`
+	Heat          *events.StreamLiveHeat `json:"heat"`
+	Types         []string               `json:"types"`
+	Derived       []string               `json:"derived"`
+	Model         string                 `json:"model"`
+	Mode          string                 `json:"mode"`
+	Rate          float64                `json:"rate"`
+	Cursor        string                 `json:"cursor"`
+}
+
+// streamMessage is a message to a stream client.
+type streamMessage struct {
+	Type         string          `json:"type"`
+	ID           string          `json:"id,omitempty"`
+	Subscription string          `json:"subscription,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	Event        json.RawMessage `json:"event,omitempty"`
+}
+
+// streamFilter filters the events of a subscription by distance, heat and type.
+type streamFilter struct {
+	distanceID string
+	heat       *events.StreamLiveHeat
+	types      []string
+}
+
+// match returns whether the JSON encoded event matches the filter. Control messages match if they concern the
+// distance or heat of the filter, or the competition.
+func (f streamFilter) match(buf []byte) bool {
+	var header streamHeader
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return false
+	}
+	switch header.TypeName() {
+	case state.StreamSnapshotType, events.StreamResetType:
+		return true
+	case events.StreamLiveType:
+		switch header.Level {
+		case events.CompetitionLevel:
+			return true
+		case events.DistanceLevel:
+			return f.heat == nil && f.matchDistance(header)
+		default:
+			return f.matchDistance(header) && f.matchHeat(header)
+		}
+	}
+	if len(f.types) > 0 {
+		var found bool
+		for _, t := range f.types {
+			if strings.EqualFold(t, header.TypeName()) || header.TypeName() == events.StreamBatchType && batched(t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.distanceID == "" {
+		return true
+	}
+	if !f.matchDistance(header) {
+		return false
+	}
+	return f.heat == nil || f.matchHeat(header)
+}
+
+// batched returns whether events of the type are batched in StreamBatchEvents.
+func batched(typeName string) bool {
+	return strings.EqualFold(typeName, events.LastRaceSpeedChangedType) ||
+		strings.EqualFold(typeName, events.RacePassingAddedType)
+}
+
+func (f streamFilter) matchDistance(header streamHeader) bool {
+	return f.distanceID == "" || strings.EqualFold(f.distanceID, header.DistanceID)
+}
+
+func (f streamFilter) matchHeat(header streamHeader) bool {
+	return f.heat == nil || header.Key.Round == f.heat.Round && header.Key.Number == f.heat.Number
+}
+
+// stream is a stream connection with its subscriptions.
+type stream struct {
+	hub           *Hub
+	logger        *zap.Logger
+	out           chan streamMessage
+	subscriptions map[string]context.CancelFunc
+}
+
+// send sends the message to the client. This method returns false if the context is done.
+func (s *stream) send(ctx context.Context, m streamMessage) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case s.out <- m:
+		return true
+	}
+}
+
+// handle handles the command. Errors are sent to the client. Events of a new subscription follow the ack.
+func (s *stream) handle(ctx context.Context, cmd streamCommand) {
+	var (
+		start func()
+		err   error
+	)
+	switch cmd.Command {
+	case subscribeCommand:
+		start, err = s.subscribe(ctx, cmd)
+	case unsubscribeCommand:
+		err = s.unsubscribe(cmd)
+	default:
+		err = errors.New("invalid command")
+	}
+	if err != nil {
+		s.send(ctx, streamMessage{
+			Type:         errorMessage,
+			ID:           cmd.ID,
+			Subscription: cmd.Subscription,
+			Error:        err.Error(),
+		})
+		return
+	}
+	s.send(ctx, streamMessage{
+		Type:         ackMessage,
+		ID:           cmd.ID,
+		Subscription: cmd.Subscription,
+	})
+	if start != nil {
+		start()
+	}
+}
+
+// subscribe follows the competition of the command. The returned function starts sending the matching events.
+func (s *stream) subscribe(ctx context.Context, cmd streamCommand) (func(), error) {
+	switch {
+	case cmd.Subscription == "":
+		return nil, errors.New("subscription is required")
+	case cmd.CompetitionID == "":
+		return nil, errors.New("competition ID is required")
+	case cmd.Heat != nil && cmd.DistanceID == "":
+		return nil, errors.New("distance ID is required to filter by heat")
+	case len(s.subscriptions) >= maxSubscriptions:
+		return nil, errors.New("too many subscriptions")
+	}
+	if _, ok := s.subscriptions[cmd.Subscription]; ok {
+		return nil, errors.New("duplicate subscription")
+	}
+	derivers, err := derivedByName(cmd.Derived, url.Values{"model": {cmd.Model}})
+	if err != nil {
+		return nil, err
+	}
+	mode, err := parseMode(cmd.Mode)
+	if err != nil {
+		return nil, err
+	}
+	interval, err := batchInterval(cmd.Rate)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Cursor != "" && mode == snapshotMode {
+		return nil, errors.New("cursor is not supported in snapshot mode")
+	}
+
+	logger := s.logger.With(
+		zap.String("subscription", cmd.Subscription),
+		zap.String("competition_id", cmd.CompetitionID),
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	f := &follower.Follower{
+		Logger: logger,
+		Source: s.hub.source,
+	}
+	eventsCh, err := f.Run(ctx, 24*time.Hour, cmd.CompetitionID)
+	if err != nil {
+		cancel()
+		logger.Debug("failed to run follower", zap.Error(err))
+		return nil, errors.New("failed to follow competition")
+	}
+	s.subscriptions[cmd.Subscription] = cancel
+
+	outCh := follower.Raw(ctx, eventsCh)
+	if len(derivers) > 0 {
+		outCh = derive(ctx, logger, outCh, derivers)
+	}
+	if cmd.Cursor != "" {
+		outCh = resume(ctx, logger, outCh, cmd.Cursor)
+	}
+	if mode == snapshotMode {
+		outCh = snapshot(ctx, logger, outCh)
+	}
+	if interval > 0 {
+		outCh = batch(ctx, logger, outCh, interval)
+	}
+	filter := streamFilter{
+		distanceID: cmd.DistanceID,
+		heat:       cmd.Heat,
+		types:      cmd.Types,
+	}
+	return func() {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case buf := <-outCh:
+					if !filter.match(buf) {
+						continue
+					}
+					if !s.send(ctx, streamMessage{
+						Type:         eventMessage,
+						Subscription: cmd.Subscription,
+						Event:        buf,
+					}) {
+						return
+					}
+				}
+			}
+		}()
+	}, nil
+}
+
+// unsubscribe stops the subscription of the command.
+func (s *stream) unsubscribe(cmd streamCommand) error {
+	cancel, ok := s.subscriptions[cmd.Subscription]
+	if !ok {
+		return errors.New("unknown subscription")
+	}
+	cancel()
+	delete(s.subscriptions, cmd.Subscription)
+	return nil
+}
+
+func (h *Hub) getStream(w http.ResponseWriter, r *http.Request) {
+	// TODO: Authenticate via Vantage API.
+
+	enc, header, err := encodingOf(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	logger := h.logger.With(zap.String("remote_address", r.RemoteAddr))
+	c, err := upgrader.Upgrade(w, r, header)
+	if err != nil {
+		logger.Debug("failed to upgrade websocket", zap.Error(err))
+		return
+	}
+	defer c.Close()
+
+	go writePings(ctx, logger, c)
+
+	s := &stream{
+		hub:           h,
+		logger:        logger,
+		out:           make(chan streamMessage),
+		subscriptions: make(map[string]context.CancelFunc),
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m := <-s.out:
+				if m.Event != nil {
+					m.Event = enc.project(m.Event)
+				}
+				buf, err := json.Marshal(m)
+				if err != nil {
+					logger.Warn("failed to marshal message", zap.Error(err))
+					continue
+				}
+				messageType, data, err := enc.frame(buf)
+				if err != nil {
+					logger.Warn("failed to encode message", zap.Error(err))
+					continue
+				}
+				if err := c.WriteMessage(messageType, data); err != nil {
+					logger.Debug("failed to write message", zap.Error(err))
+					c.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	c.SetReadDeadline(time.Now().Add(pongWait))
+	c.SetPongHandler(func(string) error {
+		c.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+	for {
+		_, buf, err := c.ReadMessage()
+		if err != nil {
+			logger.Debug("read failed", zap.Error(err))
+			return
+		}
+		c.SetReadDeadline(time.Now().Add(pongWait))
+		var cmd streamCommand
+		if err := json.Unmarshal(buf, &cmd); err != nil {
+			s.send(ctx, streamMessage{Type: errorMessage, Error: "invalid command"})
+			continue
+		}
+		s.handle(ctx, cmd)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright © 2020 Emando B.V.
+
+package hub
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/leaderboard"
+	"github.com/emando/vantage-events/pkg/state"
+	"go.uber.org/zap"
+)
+
+// topics broadcasts JSON encoded updates to subscribers of a topic.
+type topics struct {
+	mu          sync.Mutex
+	subscribers map[chan []byte]string
+	counts      map[string]int
+}
+
+func (t *topics) subscribe(topic string) chan []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.subscribers == nil {
+		t.subscribers = make(map[chan []byte]string)
+		t.counts = make(map[string]int)
+	}
+	ch := make(chan []byte, 16)
+	t.subscribers[ch] = topic
+	t.counts[topic]++
+	return ch
+}
+
+func (t *topics) unsubscribe(ch chan []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	topic, ok := t.subscribers[ch]
+	if !ok {
+		return
+	}
+	delete(t.subscribers, ch)
+	if t.counts[topic]--; t.counts[topic] == 0 {
+		delete(t.counts, topic)
+	}
+}
+
+// subscribed returns whether the topic has subscribers.
+func (t *topics) subscribed(topic string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts[topic] > 0
+}
+
+// publish sends the JSON encoded value to the subscribers of the topic. Subscribers that do not keep up miss updates.
+func (t *topics) publish(topic string, v interface{}) error {
+	if !t.subscribed(topic) {
+		return nil
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for ch, chTopic := range t.subscribers {
+		if chTopic != topic {
+			continue
+		}
+		select {
+		case ch <- buf:
+		default:
+		}
+	}
+	return nil
+}
+
+// leaderboardTopic returns the topic of leaderboard updates of the distance.
+func leaderboardTopic(competitionID, distanceID string) string {
+	return fmt.Sprintf("leaderboard/%s/%s", competitionID, distanceID)
+}
+
+// Handle publishes updates of the directory and leaderboards on the JSON encoded event. The event must be applied to
+// the store first. Handle also sets the time of the last event of the competition in the status.
+func (h *Hub) Handle(buf []byte) {
+	var event events.Distance
+	if err := json.Unmarshal(buf, &event); err != nil {
+		return
+	}
+	if event.CompetitionID != "" {
+		h.activity.received(event.CompetitionID)
+	}
+	if h.store == nil {
+		return
+	}
+	var directory, leaderboards bool
+	switch event.TypeName() {
+	case events.CompetitionActivatedType,
+		events.DistanceActivatedType,
+		events.DistanceDeactivatedType,
+		events.HeatStartedType:
+		directory = true
+	case events.HeatActivatedType,
+		events.HeatDeactivatedType,
+		events.HeatCommittedType:
+		directory, leaderboards = true, true
+	case events.HeatClearedType,
+		events.LastPresentedRaceLapChangedType:
+		leaderboards = true
+	}
+	if !directory && !leaderboards {
+		return
+	}
+	h.store.Competition(event.CompetitionID, func(c *state.Competition) {
+		if directory {
+			if err := h.topics.publish(directoryTopic, entryOf(c, time.Now())); err != nil {
+				h.logger.Warn("failed to publish directory update", zap.Error(err))
+			}
+		}
+		if d, ok := c.Distances[event.DistanceID]; ok && leaderboards {
+			topic := leaderboardTopic(c.ID, d.ID)
+			if !h.topics.subscribed(topic) {
+				return
+			}
+			if err := h.topics.publish(topic, leaderboard.FromDistance(c, d)); err != nil {
+				h.logger.Warn("failed to publish leaderboard update", zap.Error(err))
+			}
+		}
+	})
+}
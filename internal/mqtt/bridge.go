@@ -0,0 +1,225 @@
+// Copyright © 2020 Emando B.V.
+
+// Package mqtt republishes events to an MQTT broker.
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/emando/vantage-events/pkg/events"
+	"go.uber.org/zap"
+)
+
+// Options contains options for the MQTT bridge.
+type Options struct {
+	URL,
+	Username,
+	Password,
+	ClientID,
+	TopicPrefix string
+	QoS byte
+}
+
+// Bridge republishes events to an MQTT broker.
+// Events are published under topics that mirror the NATS subject hierarchy. Activations are retained, so that
+// devices get the current competition, distance and heats immediately when they subscribe. Retained activations that
+// were published before the bridge started are adopted, so that they are cleared like the activations of the bridge.
+type Bridge struct {
+	logger *zap.Logger
+	client paho.Client
+	prefix string
+	qos    byte
+
+	mu       sync.Mutex
+	retained map[string]*retained
+}
+
+// retained contains the retained activation topics of a competition. Competitions without retained distance and heat
+// activations are pruned.
+type retained struct {
+	distance string
+	heats    map[string]struct{}
+}
+
+const (
+	competitionActivation = "competition/%v/activation"
+	competitionEvents     = "competition/%v"
+	distanceActivation    = "competition/%v/distances/%v/activation"
+	distanceEvents        = "competition/%v/distances/%v"
+	heatActivation        = "competition/%v/distances/%v/heats/%d/%d/activation"
+	heatEvents            = "competition/%v/distances/%v/heats/%d/%d"
+
+	// retainedDistances and retainedHeats are the filters of retained distance and heat activations.
+	retainedDistances = "competition/+/distances/+/activation"
+	retainedHeats     = "competition/+/distances/+/heats/+/+/activation"
+)
+
+// Connect connects to the MQTT broker.
+func Connect(logger *zap.Logger, opts Options) (*Bridge, error) {
+	b := &Bridge{
+		logger:   logger,
+		prefix:   opts.TopicPrefix,
+		qos:      opts.QoS,
+		retained: make(map[string]*retained),
+	}
+	clientOpts := paho.NewClientOptions().
+		AddBroker(opts.URL).
+		SetClientID(opts.ClientID).
+		SetUsername(opts.Username).
+		SetPassword(opts.Password).
+		SetAutoReconnect(true).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			logger.Warn("lost connection to MQTT broker", zap.Error(err))
+		}).
+		SetOnConnectHandler(b.subscribe)
+	b.client = paho.NewClient(clientOpts)
+	if token := b.client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	return b, nil
+}
+
+// Close disconnects from the MQTT broker.
+func (b *Bridge) Close() {
+	b.client.Disconnect(250)
+}
+
+func (b *Bridge) publish(topic string, retain bool, payload []byte) {
+	topic = b.prefix + topic
+	token := b.client.Publish(topic, b.qos, retain, payload)
+	go func() {
+		if token.WaitTimeout(10*time.Second) && token.Error() != nil {
+			b.logger.Warn("failed to publish", zap.String("topic", topic), zap.Error(token.Error()))
+		}
+	}()
+}
+
+// clear clears the retained message of the topic.
+func (b *Bridge) clear(topic string) {
+	b.publish(topic, true, []byte{})
+}
+
+// subscribe subscribes to the retained distance and heat activations on the broker.
+func (b *Bridge) subscribe(client paho.Client) {
+	token := client.SubscribeMultiple(map[string]byte{
+		b.prefix + retainedDistances: b.qos,
+		b.prefix + retainedHeats:     b.qos,
+	}, b.adopt)
+	go func() {
+		if token.WaitTimeout(10*time.Second) && token.Error() != nil {
+			b.logger.Warn("failed to subscribe to retained activations", zap.Error(token.Error()))
+		}
+	}()
+}
+
+// adopt tracks the retained activation of the message, so that it is cleared when the distance or heat is deactivated
+// or another distance gets activated. Activations of another distance than the tracked distance are stale and cleared
+// immediately.
+func (b *Bridge) adopt(_ paho.Client, msg paho.Message) {
+	if !msg.Retained() || len(msg.Payload()) == 0 || !strings.HasPrefix(msg.Topic(), b.prefix) {
+		return
+	}
+	topic := strings.TrimPrefix(msg.Topic(), b.prefix)
+	parts := strings.Split(topic, "/")
+	if len(parts) != 5 && len(parts) != 8 {
+		return
+	}
+	distance := fmt.Sprintf(distanceActivation, parts[1], parts[3])
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r := b.competition(parts[1])
+	switch {
+	case r.distance == "":
+		r.distance = distance
+	case r.distance != distance:
+		b.clear(topic)
+		return
+	}
+	if len(parts) == 8 {
+		r.heats[topic] = struct{}{}
+	}
+}
+
+// competition returns the retained activations of the competition. The lock must be held.
+func (b *Bridge) competition(id string) *retained {
+	r, ok := b.retained[id]
+	if !ok {
+		r = &retained{heats: make(map[string]struct{})}
+		b.retained[id] = r
+	}
+	return r
+}
+
+// Handle publishes the JSON encoded event.
+func (b *Bridge) Handle(buf []byte) {
+	var event events.Race
+	if err := json.Unmarshal(buf, &event); err != nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch event.TypeName() {
+	case events.CompetitionActivatedType:
+		b.publish(fmt.Sprintf(competitionActivation, event.CompetitionID), true, buf)
+		return
+	case events.DistanceActivatedType:
+		r := b.competition(event.CompetitionID)
+		topic := fmt.Sprintf(distanceActivation, event.CompetitionID, event.DistanceID)
+		if r.distance != "" && r.distance != topic {
+			b.clearDistance(r)
+		}
+		r.distance = topic
+		b.publish(topic, true, buf)
+		return
+	case events.HeatActivatedType:
+		r := b.competition(event.CompetitionID)
+		topic := fmt.Sprintf(heatActivation, event.CompetitionID, event.DistanceID, event.Key.Round, event.Key.Number)
+		r.heats[topic] = struct{}{}
+		b.publish(topic, true, buf)
+		return
+	}
+
+	switch {
+	case event.Key.Round != 0 || event.Key.Number != 0:
+		b.publish(fmt.Sprintf(heatEvents, event.CompetitionID, event.DistanceID, event.Key.Round, event.Key.Number), false, buf)
+	case event.DistanceID != "":
+		b.publish(fmt.Sprintf(distanceEvents, event.CompetitionID, event.DistanceID), false, buf)
+	default:
+		b.publish(fmt.Sprintf(competitionEvents, event.CompetitionID), false, buf)
+	}
+
+	r, ok := b.retained[event.CompetitionID]
+	if !ok {
+		return
+	}
+	switch event.TypeName() {
+	case events.HeatDeactivatedType:
+		topic := fmt.Sprintf(heatActivation, event.CompetitionID, event.DistanceID, event.Key.Round, event.Key.Number)
+		if _, ok := r.heats[topic]; ok {
+			delete(r.heats, topic)
+			b.clear(topic)
+		}
+	case events.DistanceDeactivatedType:
+		if r.distance == fmt.Sprintf(distanceActivation, event.CompetitionID, event.DistanceID) {
+			b.clearDistance(r)
+		}
+	}
+	if r.distance == "" && len(r.heats) == 0 {
+		delete(b.retained, event.CompetitionID)
+	}
+}
+
+// clearDistance clears the retained activations of the distance and its heats.
+func (b *Bridge) clearDistance(r *retained) {
+	for topic := range r.heats {
+		b.clear(topic)
+	}
+	r.heats = make(map[string]struct{})
+	b.clear(r.distance)
+	r.distance = ""
+}
@@ -0,0 +1,206 @@
+// Copyright © 2020 Emando B.V.
+
+package mqtt
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"go.uber.org/zap"
+)
+
+// token is a completed token.
+type token struct{}
+
+func (token) Wait() bool                     { return true }
+func (token) WaitTimeout(time.Duration) bool { return true }
+func (token) Error() error                   { return nil }
+
+// client is a client that records retained publications.
+type client struct {
+	paho.Client
+	// retained are the retained messages by topic.
+	retained map[string]string
+	// cleared are the cleared topics.
+	cleared []string
+}
+
+func (c *client) Publish(topic string, qos byte, retained bool, payload interface{}) paho.Token {
+	if !retained {
+		return token{}
+	}
+	if buf := payload.([]byte); len(buf) > 0 {
+		c.retained[topic] = string(buf)
+	} else {
+		delete(c.retained, topic)
+		c.cleared = append(c.cleared, topic)
+	}
+	return token{}
+}
+
+// message is a message that was retained before the bridge started.
+type message struct {
+	paho.Message
+	topic, payload string
+}
+
+func (m message) Retained() bool  { return true }
+func (m message) Topic() string   { return m.topic }
+func (m message) Payload() []byte { return []byte(m.payload) }
+
+func event(typeName, distanceID string, round, number int) []byte {
+	return []byte(fmt.Sprintf(`{"typeName":%q,"competitionId":"c","distanceId":%q,"heat":{"round":%d,"number":%d}}`,
+		typeName, distanceID, round, number))
+}
+
+func topics(m map[string]string) []string {
+	res := make([]string, 0, len(m))
+	for topic := range m {
+		res = append(res, topic)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestBridge(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		adopted  []string
+		events   [][]byte
+		retained []string
+		cleared  []string
+		pruned   bool
+	}{
+		{
+			name: "Activations",
+			events: [][]byte{
+				event("CompetitionActivatedEvent", "", 0, 0),
+				event("DistanceActivatedEvent", "d1", 0, 0),
+				event("HeatActivatedEvent", "d1", 1, 1),
+			},
+			retained: []string{
+				"p/competition/c/activation",
+				"p/competition/c/distances/d1/activation",
+				"p/competition/c/distances/d1/heats/1/1/activation",
+			},
+		},
+		{
+			name: "HeatDeactivated",
+			events: [][]byte{
+				event("DistanceActivatedEvent", "d1", 0, 0),
+				event("HeatActivatedEvent", "d1", 1, 1),
+				event("HeatDeactivatedEvent", "d1", 1, 1),
+			},
+			retained: []string{"p/competition/c/distances/d1/activation"},
+			cleared:  []string{"p/competition/c/distances/d1/heats/1/1/activation"},
+		},
+		{
+			name: "DistanceDeactivated",
+			events: [][]byte{
+				event("DistanceActivatedEvent", "d1", 0, 0),
+				event("HeatActivatedEvent", "d1", 1, 1),
+				event("DistanceDeactivatedEvent", "d1", 0, 0),
+			},
+			retained: []string{},
+			cleared: []string{
+				"p/competition/c/distances/d1/activation",
+				"p/competition/c/distances/d1/heats/1/1/activation",
+			},
+			pruned: true,
+		},
+		{
+			name: "OtherDistance",
+			events: [][]byte{
+				event("DistanceActivatedEvent", "d1", 0, 0),
+				event("HeatActivatedEvent", "d1", 1, 1),
+				event("DistanceActivatedEvent", "d2", 0, 0),
+			},
+			retained: []string{"p/competition/c/distances/d2/activation"},
+			cleared: []string{
+				"p/competition/c/distances/d1/activation",
+				"p/competition/c/distances/d1/heats/1/1/activation",
+			},
+		},
+		{
+			name:     "Events",
+			events:   [][]byte{event("RaceLapAddedEvent", "d1", 1, 1), event("DistanceUpdatedEvent", "d1", 0, 0)},
+			retained: []string{},
+			pruned:   true,
+		},
+		{
+			name: "AdoptedCleared",
+			adopted: []string{
+				"p/competition/c/distances/d1/activation",
+				"p/competition/c/distances/d1/heats/1/1/activation",
+			},
+			events:   [][]byte{event("DistanceActivatedEvent", "d2", 0, 0)},
+			retained: []string{"p/competition/c/distances/d2/activation"},
+			cleared: []string{
+				"p/competition/c/distances/d1/activation",
+				"p/competition/c/distances/d1/heats/1/1/activation",
+			},
+		},
+		{
+			name: "AdoptedActive",
+			adopted: []string{
+				"p/competition/c/distances/d1/activation",
+				"p/competition/c/distances/d1/heats/1/1/activation",
+				"p/competition/c/distances/d1/heats/1/2/activation",
+			},
+			events: [][]byte{
+				event("DistanceActivatedEvent", "d1", 0, 0),
+				event("HeatActivatedEvent", "d1", 1, 2),
+				event("HeatDeactivatedEvent", "d1", 1, 1),
+			},
+			retained: []string{
+				"p/competition/c/distances/d1/activation",
+				"p/competition/c/distances/d1/heats/1/2/activation",
+			},
+			cleared: []string{"p/competition/c/distances/d1/heats/1/1/activation"},
+		},
+		{
+			name: "AdoptedStale",
+			adopted: []string{
+				"p/competition/c/distances/d1/activation",
+				"p/competition/c/distances/d2/activation",
+				"p/competition/c/distances/d2/heats/1/1/activation",
+			},
+			retained: []string{"p/competition/c/distances/d1/activation"},
+			cleared: []string{
+				"p/competition/c/distances/d2/activation",
+				"p/competition/c/distances/d2/heats/1/1/activation",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &client{retained: make(map[string]string)}
+			b := &Bridge{
+				logger:   zap.NewNop(),
+				client:   c,
+				prefix:   "p/",
+				retained: make(map[string]*retained),
+			}
+			for _, topic := range tc.adopted {
+				c.retained[topic] = "{}"
+				b.adopt(c, message{topic: topic, payload: "{}"})
+			}
+			for _, buf := range tc.events {
+				b.Handle(buf)
+			}
+			if res := topics(c.retained); !reflect.DeepEqual(res, tc.retained) {
+				t.Errorf("retained topics are %v, want %v", res, tc.retained)
+			}
+			sort.Strings(c.cleared)
+			if !reflect.DeepEqual(c.cleared, tc.cleared) {
+				t.Errorf("cleared topics are %v, want %v", c.cleared, tc.cleared)
+			}
+			if _, ok := b.retained["c"]; ok == tc.pruned {
+				t.Errorf("competition is tracked: %v, want %v", ok, !tc.pruned)
+			}
+		})
+	}
+}
@@ -3,8 +3,21 @@
 package nats
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/events"
 	nats "github.com/nats-io/nats.go"
 	stan "github.com/nats-io/stan.go"
+	"go.uber.org/zap"
 )
 
 // Options contains options for NATS streaming.
@@ -15,12 +28,55 @@ type Options struct {
 	UseTLS bool
 	ClusterID,
 	ClientID string
+	// Buffer is the number of messages buffered per subscription between NATS Streaming Server and the unbuffered
+	// channels of the Source, so that a slow consumer does not block delivery of other subscriptions. The default is
+	// 64.
+	Buffer int
+	// Cursors adds the subject and sequence of each message to the events in the _cursor field, so that cursors are
+	// consistent across instances.
+	Cursors bool
+	// MonitorURL is the URL of the monitoring endpoint of NATS Streaming Server, i.e. http://localhost:8222. If set,
+	// the last sequence of a subject is looked up with the channelsz endpoint. Otherwise, the last message is probed
+	// with a subscription, which waits for lastTimeout on empty subjects.
+	MonitorURL string
+	// Logger logs connection state changes. If nil, nothing is logged.
+	Logger *zap.Logger
 }
 
-// Conn is a connection to NATS Streaming Server.
+const (
+	// pingInterval and pingMaxOut detect a lost connection to NATS Streaming Server after about 15 seconds.
+	pingInterval = 5
+	pingMaxOut   = 3
+
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+
+	defaultBuffer = 64
+
+	// lastTimeout is the time to wait for the last message of a subject, after which the subject is considered empty.
+	lastTimeout = time.Second
+	// monitorTimeout is the timeout of requests to the monitoring endpoint.
+	monitorTimeout = 5 * time.Second
+)
+
+// errNotConnected is returned when subscribing while the connection is lost.
+var errNotConnected = errors.New("nats: not connected to NATS Streaming Server")
+
+// Conn is a connection to NATS Streaming Server. When the connection is lost, Conn reconnects and resubscribes all
+// subscriptions from the last delivered message.
 type Conn struct {
-	nats *nats.Conn
-	stan stan.Conn
+	opts    Options
+	logger  *zap.Logger
+	nats    *nats.Conn
+	monitor *http.Client
+
+	mu            sync.Mutex
+	stan          stan.Conn
+	subscriptions map[*subscription]struct{}
+	status        events.SourceStatus
+	observers     map[int]func(events.SourceStatus)
+	nextObserver  int
+	closed        chan struct{}
 }
 
 // Connect connects to NATS Streaming Server.
@@ -38,21 +94,330 @@ func Connect(opts Options) (*Conn, error) {
 	if err != nil {
 		return nil, err
 	}
-	stanConn, err := stan.Connect(opts.ClusterID, opts.ClientID, stan.NatsConn(natsConn))
-	if err != nil {
+	c := &Conn{
+		opts:          opts,
+		logger:        opts.Logger,
+		nats:          natsConn,
+		monitor:       &http.Client{Timeout: monitorTimeout},
+		subscriptions: make(map[*subscription]struct{}),
+		observers:     make(map[int]func(events.SourceStatus)),
+		closed:        make(chan struct{}),
+	}
+	if c.logger == nil {
+		c.logger = zap.NewNop()
+	}
+	if c.opts.Buffer <= 0 {
+		c.opts.Buffer = defaultBuffer
+	}
+	if c.stan, err = c.connect(); err != nil {
 		natsConn.Close()
 		return nil, err
 	}
-	return &Conn{
-		nats: natsConn,
-		stan: stanConn,
-	}, nil
+	c.status = events.SourceStatus{
+		State: events.Connected,
+		Since: time.Now(),
+	}
+	return c, nil
+}
+
+func (c *Conn) connect() (stan.Conn, error) {
+	return stan.Connect(c.opts.ClusterID, c.opts.ClientID,
+		stan.NatsConn(c.nats),
+		stan.Pings(pingInterval, pingMaxOut),
+		stan.SetConnectionLostHandler(c.connectionLost),
+	)
+}
+
+// connectionLost reconnects to NATS Streaming Server and resubscribes all subscriptions.
+func (c *Conn) connectionLost(lost stan.Conn, err error) {
+	c.mu.Lock()
+	if c.stan != lost {
+		c.mu.Unlock()
+		return
+	}
+	c.stan = nil
+	notify := c.setStatus(events.Reconnecting, err)
+	c.mu.Unlock()
+	notify()
+	c.logger.Warn("lost connection to NATS Streaming Server, reconnecting", zap.Error(err))
+	go c.reconnect()
+}
+
+func (c *Conn) reconnect() {
+	backoff := minReconnectBackoff
+	for {
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		select {
+		case <-c.closed:
+			return
+		case <-time.After(wait):
+		}
+		conn, err := c.connect()
+		if err != nil {
+			c.logger.Warn("failed to reconnect to NATS Streaming Server", zap.Duration("wait", wait), zap.Error(err))
+			c.mu.Lock()
+			c.status.LastError = err.Error()
+			c.mu.Unlock()
+			if backoff *= 2; backoff > maxReconnectBackoff {
+				backoff = maxReconnectBackoff
+			}
+			continue
+		}
+		c.mu.Lock()
+		select {
+		case <-c.closed:
+			c.mu.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+		c.stan = conn
+		var failed int
+		for sub := range c.subscriptions {
+			if err := sub.subscribe(conn); err != nil {
+				c.logger.Warn("failed to resubscribe", zap.String("subject", sub.subject), zap.Error(err))
+				delete(c.subscriptions, sub)
+				sub.failed(err)
+				failed++
+			}
+		}
+		c.status.Reconnects++
+		notify := c.setStatus(events.Connected, nil)
+		c.mu.Unlock()
+		notify()
+		c.logger.Info("reconnected to NATS Streaming Server",
+			zap.Int("subscriptions", len(c.subscriptions)),
+			zap.Int("failed", failed),
+		)
+		return
+	}
+}
+
+// setStatus sets the state and returns a function that notifies the observers. The lock must be held, and must be
+// released before calling the function, so that observers can call methods of the connection.
+func (c *Conn) setStatus(state events.ConnState, err error) func() {
+	c.status.State = state
+	c.status.Since = time.Now()
+	if err != nil {
+		c.status.LastError = err.Error()
+	}
+	status := c.status
+	observers := make([]func(events.SourceStatus), 0, len(c.observers))
+	for _, f := range c.observers {
+		observers = append(observers, f)
+	}
+	return func() {
+		for _, f := range observers {
+			f(status)
+		}
+	}
+}
+
+// Status returns the connection status.
+func (c *Conn) Status() events.SourceStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// Check returns an error unless connected to both NATS Server and NATS Streaming Server.
+func (c *Conn) Check() error {
+	if !c.nats.IsConnected() {
+		return errors.New("nats: not connected to NATS Server")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stan == nil {
+		return errNotConnected
+	}
+	return nil
+}
+
+// NotifyStatus calls f on each change of the connection status until the returned function is called. f must not
+// block.
+func (c *Conn) NotifyStatus(f func(events.SourceStatus)) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.nextObserver
+	c.nextObserver++
+	c.observers[id] = f
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.observers, id)
+	}
+}
+
+// subscription is a subscription that is resubscribed after reconnecting.
+type subscription struct {
+	subject string
+	cb      stan.MsgHandler
+	start   stan.SubscriptionOption
+	// failed is called when resubscribing fails. It must not block.
+	failed func(error)
+	// lastSequence is the sequence of the last delivered message. Messages that are buffered by cb are not delivered
+	// yet.
+	lastSequence uint64
+	sub          stan.Subscription
+}
+
+// subscribe subscribes on the connection, starting after the last delivered message if any.
+func (s *subscription) subscribe(conn stan.Conn) error {
+	start := s.start
+	if seq := atomic.LoadUint64(&s.lastSequence); seq > 0 {
+		start = stan.StartAtSequence(seq + 1)
+	}
+	sub, err := conn.Subscribe(s.subject, s.cb, start)
+	if err != nil {
+		return err
+	}
+	s.sub = sub
+	return nil
+}
+
+// deliver records the delivery of the message with the sequence. This method returns false if the message was
+// delivered before: messages that were buffered but not delivered before resubscribing are received twice.
+func (s *subscription) deliver(seq uint64) bool {
+	if seq <= atomic.LoadUint64(&s.lastSequence) {
+		return false
+	}
+	atomic.StoreUint64(&s.lastSequence, seq)
+	return true
+}
+
+// subscribe subscribes to the subject, starting at the start option. failed is called if the subscription fails after
+// reconnecting.
+func (c *Conn) subscribe(subject string, cb stan.MsgHandler, start stan.SubscriptionOption, failed func(error)) (*subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stan == nil {
+		return nil, errNotConnected
+	}
+	s := &subscription{
+		subject: subject,
+		cb:      cb,
+		start:   start,
+		failed:  failed,
+	}
+	if err := s.subscribe(c.stan); err != nil {
+		return nil, err
+	}
+	c.subscriptions[s] = struct{}{}
+	return s, nil
+}
+
+// channel is the channel of the channelsz monitoring endpoint.
+type channel struct {
+	Msgs    uint64 `json:"msgs"`
+	LastSeq uint64 `json:"last_seq"`
+}
+
+// lastSequence returns the sequence of the last message of the subject from the monitoring endpoint, or zero if the
+// subject has no messages.
+func (c *Conn) lastSequence(ctx context.Context, subject string) (uint64, error) {
+	u, err := url.Parse(c.opts.MonitorURL)
+	if err != nil {
+		return 0, err
+	}
+	u.Path = "/streaming/channelsz"
+	u.RawQuery = url.Values{"channel": {subject}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := c.monitor.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// The channel is created on the first message or subscription.
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("nats: monitoring endpoint responded with %s", res.Status)
+	}
+	var ch channel
+	if err := json.NewDecoder(res.Body).Decode(&ch); err != nil {
+		return 0, err
+	}
+	if ch.Msgs == 0 {
+		return 0, nil
+	}
+	return ch.LastSeq, nil
+}
+
+// last returns the sequence and time of the last message of the subject, or zero if the subject has no messages. With
+// a monitoring endpoint, empty subjects are not subscribed and the last message is received without waiting for
+// lastTimeout, unless it expired after looking up the sequence.
+func (c *Conn) last(ctx context.Context, subject string) (uint64, time.Time, error) {
+	start := stan.StartWithLastReceived()
+	if c.opts.MonitorURL != "" {
+		seq, err := c.lastSequence(ctx, subject)
+		if err != nil || seq == 0 {
+			return 0, time.Time{}, err
+		}
+		start = stan.StartAtSequence(seq)
+	}
+	c.mu.Lock()
+	conn := c.stan
+	c.mu.Unlock()
+	if conn == nil {
+		return 0, time.Time{}, errNotConnected
+	}
+	msgs := make(chan *stan.Msg, 1)
+	sub, err := conn.Subscribe(subject, func(msg *stan.Msg) {
+		select {
+		case msgs <- msg:
+		default:
+		}
+	}, start)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer sub.Close()
+	select {
+	case <-ctx.Done():
+		return 0, time.Time{}, ctx.Err()
+	case msg := <-msgs:
+		return msg.Sequence, time.Unix(0, msg.Timestamp), nil
+	case <-time.After(lastTimeout):
+		return 0, time.Time{}, nil
+	}
+}
+
+// unsubscribe closes the subscription.
+func (c *Conn) unsubscribe(s *subscription) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.subscriptions[s]
+	delete(c.subscriptions, s)
+	if !ok || c.stan == nil {
+		// The subscription failed or the connection is lost.
+		return nil
+	}
+	return s.sub.Close()
 }
 
 // Close closes the connection.
 func (c *Conn) Close() error {
-	if err := c.stan.Close(); err != nil {
-		return err
+	c.mu.Lock()
+	select {
+	case <-c.closed:
+		c.mu.Unlock()
+		return nil
+	default:
+	}
+	close(c.closed)
+	notify := c.setStatus(events.Closed, nil)
+	conn := c.stan
+	c.mu.Unlock()
+	notify()
+	if conn != nil {
+		if err := conn.Close(); err != nil {
+			return err
+		}
 	}
 	c.nats.Close()
 	return nil
@@ -0,0 +1,128 @@
+// Copyright © 2020 Emando B.V.
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emando/vantage-events/pkg/events"
+)
+
+func TestSubscriptionDeliver(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		sequences []uint64
+		expected  []bool
+	}{
+		{name: "InOrder", sequences: []uint64{1, 2, 3}, expected: []bool{true, true, true}},
+		{name: "Resubscribed", sequences: []uint64{4, 5, 6, 5, 6, 7}, expected: []bool{true, true, true, false, false, true}},
+		{name: "Duplicate", sequences: []uint64{1, 1}, expected: []bool{true, false}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var s subscription
+			for i, seq := range tc.sequences {
+				if ok := s.deliver(seq); ok != tc.expected[i] {
+					t.Errorf("delivery of %d is %v, want %v", seq, ok, tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	c := &Conn{
+		observers: make(map[int]func(events.SourceStatus)),
+		closed:    make(chan struct{}),
+	}
+	var notified []events.SourceStatus
+	stop := c.NotifyStatus(func(status events.SourceStatus) {
+		// Observers may call methods of the connection.
+		if c.Status() != status {
+			t.Errorf("status is %+v, want %+v", c.Status(), status)
+		}
+		notified = append(notified, status)
+	})
+
+	c.mu.Lock()
+	notify := c.setStatus(events.Reconnecting, errors.New("lost"))
+	c.mu.Unlock()
+	notify()
+	if len(notified) != 1 || notified[0].State != events.Reconnecting || notified[0].LastError != "lost" {
+		t.Fatalf("notified %+v", notified)
+	}
+
+	stop()
+	c.mu.Lock()
+	notify = c.setStatus(events.Connected, nil)
+	c.mu.Unlock()
+	notify()
+	if len(notified) != 1 {
+		t.Errorf("notified after stop: %+v", notified)
+	}
+}
+
+func TestWithCursor(t *testing.T) {
+	for _, tc := range []struct {
+		name, event, expected string
+	}{
+		{name: "Event", event: `{"typeName":"X"}`, expected: `{"_cursor":"s:1","typeName":"X"}`},
+		{name: "Empty", event: `{}`, expected: `{"_cursor":"s:1"}`},
+		{name: "NotObject", event: `[1]`, expected: `[1]`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if res := string(withCursor([]byte(tc.event), "s:1")); res != tc.expected {
+				t.Errorf("event is %s, want %s", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLastSequence(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/streaming/channelsz" {
+			http.NotFound(w, r)
+			return
+		}
+		switch channel := r.URL.Query().Get("channel"); channel {
+		case "competition.1":
+			fmt.Fprint(w, `{"name":"competition.1","msgs":3,"bytes":60,"first_seq":5,"last_seq":7}`)
+		case "competition.2":
+			fmt.Fprint(w, `{"name":"competition.2","msgs":0,"bytes":0,"first_seq":0,"last_seq":0}`)
+		case "competition.3":
+			http.Error(w, "Channel competition.3 not found", http.StatusNotFound)
+		default:
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+	}))
+	defer s.Close()
+
+	c := &Conn{
+		opts:    Options{MonitorURL: s.URL},
+		monitor: s.Client(),
+	}
+	for _, tc := range []struct {
+		name, subject string
+		expected      uint64
+		err           bool
+	}{
+		{name: "Messages", subject: "competition.1", expected: 7},
+		{name: "Empty", subject: "competition.2", expected: 0},
+		{name: "NotFound", subject: "competition.3", expected: 0},
+		{name: "Error", subject: "competition.4", err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			seq, err := c.lastSequence(context.Background(), tc.subject)
+			if (err != nil) != tc.err {
+				t.Fatalf("error is %v, want error %v", err, tc.err)
+			}
+			if seq != tc.expected {
+				t.Errorf("last sequence is %d, want %d", seq, tc.expected)
+			}
+		})
+	}
+}
@@ -27,6 +27,17 @@ func NewSource(logger *zap.Logger, conn *Conn) *Source {
 	}
 }
 
+// Status returns the status of the connection to NATS Streaming Server.
+func (s *Source) Status() events.SourceStatus {
+	return s.conn.Status()
+}
+
+// NotifyStatus calls f on each change of the status of the connection to NATS Streaming Server until the returned
+// function is called. f must not block.
+func (s *Source) NotifyStatus(f func(events.SourceStatus)) func() {
+	return s.conn.NotifyStatus(f)
+}
+
 const (
 	competitionActivations = "competition.activations"
 	competitionEvents      = "competition.%v"
@@ -36,194 +47,314 @@ const (
 	heatEvents             = "competition.%v.distances.%v.heats.%d.%d"
 )
 
+// subscribe subscribes to the subjects and calls deliver for each message, in order, until the context is done, the
+// subscription fails or deliver returns false. Messages are buffered, so that STAN delivery does not block on slow
+// consumers, and the callback is guarded against the end of delivery, so that it never blocks once delivery ends.
+// Messages that are received twice after resubscribing are delivered once. closed is called when delivery ends.
+func (s *Source) subscribe(ctx context.Context, logger *zap.Logger, subjects []string, start stan.SubscriptionOption,
+	deliver func(msg *stan.Msg) bool, closed func(),
+) error {
+	msgs := make(chan *stan.Msg, s.conn.opts.Buffer)
+	failed := make(chan error, len(subjects))
+	done := make(chan struct{})
+	cb := func(msg *stan.Msg) {
+		select {
+		case <-done:
+		case msgs <- msg:
+		}
+	}
+	onFailure := func(err error) {
+		failed <- err
+	}
+	subs := make([]*subscription, 0, len(subjects))
+	bySubject := make(map[string]*subscription, len(subjects))
+	unsubscribe := func() {
+		for _, sub := range subs {
+			if err := s.conn.unsubscribe(sub); err != nil {
+				logger.Debug("failed to unsubscribe", zap.String("subject", sub.subject), zap.Error(err))
+			}
+		}
+	}
+	for _, subject := range subjects {
+		sub, err := s.conn.subscribe(subject, cb, start, onFailure)
+		if err != nil {
+			unsubscribe()
+			return err
+		}
+		subs = append(subs, sub)
+		bySubject[subject] = sub
+	}
+	go func() {
+		defer closed()
+		defer unsubscribe()
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Debug("unsubscribe", zap.Strings("subjects", subjects))
+				return
+			case err := <-failed:
+				logger.Warn("subscription failed", zap.Strings("subjects", subjects), zap.Error(err))
+				return
+			case msg := <-msgs:
+				if sub, ok := bySubject[msg.Subject]; ok && !sub.deliver(msg.Sequence) {
+					continue
+				}
+				if !deliver(msg) {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
 // CompetitionActivations returns the competition activations.
 func (s *Source) CompetitionActivations(ctx context.Context, history time.Duration) (<-chan *events.CompetitionActivated, error) {
 	ch := make(chan *events.CompetitionActivated)
-	cb := func(msg *stan.Msg) {
+	deliver := func(msg *stan.Msg) bool {
 		event := &events.CompetitionActivated{
 			Time: time.Unix(0, msg.Timestamp),
-			Raw:  append(msg.Data[:0:0], msg.Data...),
+			Raw:  s.data(msg),
 		}
 		if err := events.Unmarshal(msg.Data, events.CompetitionActivatedType, event); err != nil {
 			s.logger.Warn("failed to unmarshal data", zap.Error(err))
-			return
+			return true
 		}
 		s.logger.Debug("received competition activation",
 			zap.String("competition_id", event.CompetitionID),
 		)
-		ch <- event
+		select {
+		case <-ctx.Done():
+			return false
+		case ch <- event:
+			return true
+		}
 	}
-	sub, err := s.conn.stan.Subscribe(competitionActivations, cb, stan.StartAtTimeDelta(history))
-	if err != nil {
+	subjects := []string{competitionActivations}
+	if err := s.subscribe(ctx, s.logger, subjects, stan.StartAtTimeDelta(history), deliver, func() { close(ch) }); err != nil {
 		return nil, err
 	}
-	go func() {
-		<-ctx.Done()
-		s.logger.Debug("unsubscribe from competition activations")
-		sub.Close()
-	}()
 	return ch, nil
 }
 
-// CompetitionEvents returns the competition events.
-func (s *Source) CompetitionEvents(ctx context.Context, since *events.CompetitionActivated) (<-chan *events.Raw, error) {
-	logger := s.logger.With(zap.String("competition_id", since.CompetitionID))
+// replay subscribes to the events of the subject since the activation time, or after the sequence if it is not zero.
+// The last message of the subject is looked up before subscribing, as the boundary of the replay.
+func (s *Source) replay(ctx context.Context, logger *zap.Logger, subject string, since time.Time, after uint64) (*events.Replay, error) {
+	last, published, err := s.conn.last(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	start := stan.StartAtTime(since)
+	if after > 0 {
+		start = stan.StartAtSequence(after + 1)
+	}
+	if last <= after || (after == 0 && published.Before(since)) {
+		last = 0
+	}
 	ch := make(chan *events.Raw)
-	cb := func(msg *stan.Msg) {
-		logger.Debug("received competition event")
+	if err := s.subscribe(ctx, logger, []string{subject}, start, s.raw(ctx, logger, ch), func() { close(ch) }); err != nil {
+		return nil, err
+	}
+	return &events.Replay{
+		Events: ch,
+		Last:   last,
+	}, nil
+}
+
+// activationReplay tracks the replay of activation subjects that are subscribed with the last received message. The
+// replay is delivered when the last message of each subject that was published before subscribing is delivered.
+type activationReplay struct {
+	pending  map[string]uint64
+	replayed chan struct{}
+}
+
+// activationReplay looks up the last message of the subjects before subscribing.
+func (s *Source) activationReplay(ctx context.Context, subjects []string) (*activationReplay, error) {
+	r := &activationReplay{
+		pending:  make(map[string]uint64, len(subjects)),
+		replayed: make(chan struct{}),
+	}
+	for _, subject := range subjects {
+		last, _, err := s.conn.last(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		if last > 0 {
+			r.pending[subject] = last
+		}
+	}
+	if len(r.pending) == 0 {
+		close(r.replayed)
+	}
+	return r, nil
+}
+
+// delivered marks the replay of the subject of the message as delivered if the message is the last replayed message
+// or a later one, i.e. when a message was published between looking up the last message and subscribing.
+func (r *activationReplay) delivered(msg *stan.Msg) {
+	last, ok := r.pending[msg.Subject]
+	if !ok || msg.Sequence < last {
+		return
+	}
+	delete(r.pending, msg.Subject)
+	if len(r.pending) == 0 {
+		close(r.replayed)
+	}
+}
+
+// data returns a copy of the message data, with the cursor of the message if enabled.
+func (s *Source) data(msg *stan.Msg) []byte {
+	if s.conn.opts.Cursors {
+		return withCursor(msg.Data, fmt.Sprintf("%s:%d", msg.Subject, msg.Sequence))
+	}
+	return append(msg.Data[:0:0], msg.Data...)
+}
+
+// withCursor returns a copy of the JSON encoded event with the cursor in the _cursor field.
+func withCursor(event []byte, cursor string) []byte {
+	if len(event) < 2 || event[0] != '{' {
+		return append(event[:0:0], event...)
+	}
+	buf, _ := json.Marshal(cursor)
+	res := make([]byte, 0, len(event)+len(buf)+12)
+	res = append(res, `{"_cursor":`...)
+	res = append(res, buf...)
+	if event[1] != '}' {
+		res = append(res, ',')
+	}
+	return append(res, event[1:]...)
+}
+
+// raw returns a function that delivers messages as raw events to the channel.
+func (s *Source) raw(ctx context.Context, logger *zap.Logger, ch chan<- *events.Raw) func(*stan.Msg) bool {
+	return func(msg *stan.Msg) bool {
 		event := &events.Raw{
-			Bytes: append(msg.Data[:0:0], msg.Data...),
+			Bytes:    s.data(msg),
+			Time:     time.Unix(0, msg.Timestamp),
+			Sequence: msg.Sequence,
 		}
 		if err := json.Unmarshal(msg.Data, &event); err != nil {
 			logger.Warn("failed to unmarshal data", zap.Error(err))
-			return
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case ch <- event:
+			return true
 		}
-		ch <- event
-	}
-	subject := fmt.Sprintf(competitionEvents, since.CompetitionID)
-	sub, err := s.conn.stan.Subscribe(subject, cb, stan.StartAtTime(since.Time))
-	if err != nil {
-		return nil, err
 	}
-	go func() {
-		<-ctx.Done()
-		logger.Debug("unsubscribe from competition events")
-		sub.Close()
-	}()
-	return ch, nil
+}
+
+// CompetitionEvents returns the competition events.
+func (s *Source) CompetitionEvents(ctx context.Context, since *events.CompetitionActivated, after uint64) (*events.Replay, error) {
+	logger := s.logger.With(zap.String("competition_id", since.CompetitionID))
+	return s.replay(ctx, logger, fmt.Sprintf(competitionEvents, since.CompetitionID), since.Time, after)
 }
 
 // DistanceActivations returns the distance activations. The last activated distance is always returned.
-func (s *Source) DistanceActivations(ctx context.Context, competitionID string) (<-chan *events.DistanceActivated, error) {
+func (s *Source) DistanceActivations(ctx context.Context, competitionID string) (*events.DistanceActivations, error) {
 	logger := s.logger.With(
 		zap.String("competition_id", competitionID),
 	)
+	subjects := []string{fmt.Sprintf(distanceActivations, competitionID)}
+	replay, err := s.activationReplay(ctx, subjects)
+	if err != nil {
+		return nil, err
+	}
 	ch := make(chan *events.DistanceActivated)
-	cb := func(msg *stan.Msg) {
+	deliver := func(msg *stan.Msg) bool {
+		defer replay.delivered(msg)
 		event := &events.DistanceActivated{
 			Time: time.Unix(0, msg.Timestamp),
-			Raw:  append(msg.Data[:0:0], msg.Data...),
+			Raw:  s.data(msg),
 		}
 		if err := events.Unmarshal(msg.Data, events.DistanceActivatedType, event); err != nil {
 			logger.Warn("failed to unmarshal data", zap.Error(err))
-			return
+			return true
 		}
 		logger.Debug("received distance activation",
 			zap.String("distance_id", event.DistanceID),
 		)
-		ch <- event
+		select {
+		case <-ctx.Done():
+			return false
+		case ch <- event:
+			return true
+		}
 	}
-	subject := fmt.Sprintf(distanceActivations, competitionID)
-	sub, err := s.conn.stan.Subscribe(subject, cb, stan.StartWithLastReceived())
-	if err != nil {
+	if err := s.subscribe(ctx, logger, subjects, stan.StartWithLastReceived(), deliver, func() { close(ch) }); err != nil {
 		return nil, err
 	}
-	go func() {
-		<-ctx.Done()
-		logger.Debug("unsubscribe from distance activations")
-		sub.Close()
-	}()
-	return ch, nil
+	return &events.DistanceActivations{
+		Activations: ch,
+		Replayed:    replay.replayed,
+	}, nil
 }
 
 // DistanceEvents returns the competition distance events.
-func (s *Source) DistanceEvents(ctx context.Context, since *events.DistanceActivated) (<-chan *events.Raw, error) {
+func (s *Source) DistanceEvents(ctx context.Context, since *events.DistanceActivated, after uint64) (*events.Replay, error) {
 	logger := s.logger.With(
 		zap.String("competition_id", since.CompetitionID),
 		zap.String("distance_id", since.DistanceID),
 	)
-	ch := make(chan *events.Raw)
-	cb := func(msg *stan.Msg) {
-		logger.Debug("received distance event")
-		event := &events.Raw{
-			Bytes: append(msg.Data[:0:0], msg.Data...),
-		}
-		if err := json.Unmarshal(msg.Data, &event); err != nil {
-			logger.Warn("failed to unmarshal data", zap.Error(err))
-			return
-		}
-		ch <- event
-	}
-	subject := fmt.Sprintf(distanceEvents, since.CompetitionID, since.DistanceID)
-	sub, err := s.conn.stan.Subscribe(subject, cb, stan.StartAtTime(since.Time))
-	if err != nil {
-		return nil, err
-	}
-	go func() {
-		<-ctx.Done()
-		logger.Debug("unsubscribe from distance events")
-		sub.Close()
-	}()
-	return ch, nil
+	return s.replay(ctx, logger, fmt.Sprintf(distanceEvents, since.CompetitionID, since.DistanceID), since.Time, after)
 }
 
-// HeatActivations returns the heat activations. The last activated heat is always returned.
-func (s *Source) HeatActivations(ctx context.Context, competitionID, distanceID string, groups ...int) (<-chan *events.HeatActivated, error) {
+// HeatActivations returns the heat activations. The last activated heat of each group is always returned.
+func (s *Source) HeatActivations(ctx context.Context, competitionID, distanceID string, groups ...int) (*events.HeatActivations, error) {
 	logger := s.logger.With(
 		zap.String("competition_id", competitionID),
 		zap.String("distance_id", distanceID),
 	)
+	subjects := make([]string, len(groups))
+	for i, group := range groups {
+		subjects[i] = fmt.Sprintf(heatActivations, competitionID, distanceID, group)
+	}
+	replay, err := s.activationReplay(ctx, subjects)
+	if err != nil {
+		return nil, err
+	}
 	ch := make(chan *events.HeatActivated)
-	cb := func(msg *stan.Msg) {
+	deliver := func(msg *stan.Msg) bool {
+		defer replay.delivered(msg)
 		event := &events.HeatActivated{
 			Time: time.Unix(0, msg.Timestamp),
-			Raw:  append(msg.Data[:0:0], msg.Data...),
+			Raw:  s.data(msg),
 		}
 		if err := events.Unmarshal(msg.Data, events.HeatActivatedType, event); err != nil {
 			logger.Warn("failed to unmarshal data", zap.Error(err))
-			return
+			return true
 		}
 		logger.Debug("received heat activation",
 			zap.Int("heat_round", event.Key.Round),
 			zap.Int("heat_number", event.Key.Number),
 		)
-		ch <- event
-	}
-	for _, group := range groups {
-		logger := logger.With(zap.Int("group", group))
-		subject := fmt.Sprintf(heatActivations, competitionID, distanceID, group)
-		sub, err := s.conn.stan.Subscribe(subject, cb, stan.StartWithLastReceived())
-		if err != nil {
-			return nil, err
+		select {
+		case <-ctx.Done():
+			return false
+		case ch <- event:
+			return true
 		}
-		go func() {
-			<-ctx.Done()
-			logger.Debug("unsubscribe from heat activations")
-			sub.Close()
-		}()
 	}
-	return ch, nil
+	if err := s.subscribe(ctx, logger, subjects, stan.StartWithLastReceived(), deliver, func() { close(ch) }); err != nil {
+		return nil, err
+	}
+	return &events.HeatActivations{
+		Activations: ch,
+		Replayed:    replay.replayed,
+	}, nil
 }
 
 // HeatEvents returns the competititon distance heat events.
-func (s *Source) HeatEvents(ctx context.Context, since *events.HeatActivated) (<-chan *events.Raw, error) {
+func (s *Source) HeatEvents(ctx context.Context, since *events.HeatActivated, after uint64) (*events.Replay, error) {
 	logger := s.logger.With(
 		zap.String("competition_id", since.CompetitionID),
 		zap.String("distance_id", since.DistanceID),
 		zap.Int("heat_round", since.Key.Round),
 		zap.Int("heat_number", since.Key.Number),
 	)
-	ch := make(chan *events.Raw)
-	cb := func(msg *stan.Msg) {
-		logger.Debug("received heat event")
-		event := &events.Raw{
-			Bytes: append(msg.Data[:0:0], msg.Data...),
-		}
-		if err := json.Unmarshal(msg.Data, &event); err != nil {
-			logger.Warn("failed to unmarshal data", zap.Error(err))
-			return
-		}
-		ch <- event
-	}
 	subject := fmt.Sprintf(heatEvents, since.CompetitionID, since.DistanceID, since.Key.Round, since.Key.Number)
-	sub, err := s.conn.stan.Subscribe(subject, cb, stan.StartAtTime(since.Time))
-	if err != nil {
-		return nil, err
-	}
-	go func() {
-		<-ctx.Done()
-		logger.Debug("unsubscribe from heat events")
-		sub.Close()
-	}()
-	return ch, nil
+	return s.replay(ctx, logger, subject, since.Time, after)
 }
@@ -0,0 +1,280 @@
+// Copyright © 2020 Emando B.V.
+
+// Package quality monitors the quality of timing data.
+package quality
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/results"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// Alert types.
+const (
+	MissingPassings   = "MissingPassings"
+	LapTimeDeviation  = "LapTimeDeviation"
+	SourceSwitch      = "SourceSwitch"
+	DuplicatePassing  = "DuplicatePassing"
+	OutOfOrderPassing = "OutOfOrderPassing"
+	LaneMismatch      = "LaneMismatch"
+)
+
+// Severities.
+const (
+	Info    = "info"
+	Warning = "warning"
+)
+
+// Alert is a timing data anomaly.
+type Alert struct {
+	Time          time.Time `json:"time"`
+	Type          string    `json:"type"`
+	Severity      string    `json:"severity"`
+	CompetitionID string    `json:"competitionId"`
+	DistanceID    string    `json:"distanceId"`
+	Round         int       `json:"round"`
+	Heat          int       `json:"heat"`
+	RaceID        string    `json:"raceId"`
+	Lane          int       `json:"lane"`
+	Competitor    string    `json:"competitor"`
+	Message       string    `json:"message"`
+}
+
+// Options contains options for anomaly detection.
+type Options struct {
+	// LapTimeTolerance is the relative deviation of a lap time from the estimate, i.e. 0.1 for 10%.
+	LapTimeTolerance float64
+}
+
+const (
+	// lapCluster is the time in which lap registrations from different timing sources belong to the same lap.
+	lapCluster = entities.Ticks(20000000)
+	// duplicateTolerance is the time in which passings at the same timing point are duplicates.
+	duplicateTolerance = entities.Ticks(10000)
+	// startSource is the source of the start registration, which is not a lap time.
+	startSource = "Start"
+	// photofinishSource supplements other sources at the finish and is not a source of lap times.
+	photofinishSource = "Photofinish"
+)
+
+// Detector detects timing data anomalies.
+type Detector struct {
+	opts Options
+}
+
+// NewDetector returns a new Detector.
+func NewDetector(opts Options) *Detector {
+	if opts.LapTimeTolerance <= 0 {
+		opts.LapTimeTolerance = 0.1
+	}
+	return &Detector{opts: opts}
+}
+
+// Detect returns the anomalies detected on the JSON encoded event. The event must be applied to the store first.
+func (d *Detector) Detect(store *state.Store, buf []byte) ([]Alert, error) {
+	var header events.Race
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return nil, err
+	}
+	switch header.TypeName() {
+	case events.RaceLapAddedType, events.RacePassingAddedType, events.LastPresentedRaceLapChangedType:
+	default:
+		return nil, nil
+	}
+	var alerts []Alert
+	var err error
+	store.Heat(header.CompetitionID, header.DistanceID, state.KeyOf(header.Heat.Heat), func(_ *state.Competition, dist *state.Distance, h *state.Heat) {
+		r := h.Race(header.RaceID)
+		if r == nil {
+			return
+		}
+		alert := func(typ, severity, format string, args ...interface{}) {
+			alerts = append(alerts, Alert{
+				Time:          time.Now(),
+				Type:          typ,
+				Severity:      severity,
+				CompetitionID: header.CompetitionID,
+				DistanceID:    header.DistanceID,
+				Round:         h.Key.Round,
+				Heat:          h.Key.Number,
+				RaceID:        r.ID,
+				Lane:          r.Lane,
+				Competitor:    r.Competitor.FullName,
+				Message:       fmt.Sprintf(format, args...),
+			})
+		}
+		switch header.TypeName() {
+		case events.RaceLapAddedType:
+			d.detectLap(h, r, alert)
+		case events.RacePassingAddedType:
+			d.detectPassing(r, alert)
+		case events.LastPresentedRaceLapChangedType:
+			event := &events.LastPresentedRaceLapChanged{}
+			if err = json.Unmarshal(buf, event); err != nil {
+				return
+			}
+			d.detectPresentedLap(dist, r, event.Lap, alert)
+		}
+	})
+	return alerts, err
+}
+
+type alertFunc func(typ, severity, format string, args ...interface{})
+
+// lap is a lap with the registrations of one or more timing sources.
+type lap struct {
+	time    entities.Ticks
+	sources []string
+}
+
+// lapsOf returns the laps of the race, clustering registrations of different sources.
+func lapsOf(r *state.Race) []lap {
+	regs := make([]entities.Lap, 0, len(r.Laps))
+	for _, l := range r.Laps {
+		if isLapSource(l.PresentationSource.How) {
+			regs = append(regs, l)
+		}
+	}
+	sort.Slice(regs, func(i, j int) bool {
+		return regs[i].Time < regs[j].Time
+	})
+	var laps []lap
+	for _, reg := range regs {
+		how := reg.PresentationSource.How
+		if n := len(laps); n > 0 && reg.Time-laps[n-1].time < lapCluster {
+			if !contains(laps[n-1].sources, how) {
+				laps[n-1].sources = append(laps[n-1].sources, how)
+				sort.Strings(laps[n-1].sources)
+			}
+			continue
+		}
+		laps = append(laps, lap{time: reg.Time, sources: []string{how}})
+	}
+	return laps
+}
+
+func isLapSource(how string) bool {
+	return how != startSource && how != photofinishSource
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// detectLap detects anomalies when a lap is registered. Anomalies are detected when the registration starts a new
+// lap, when the registrations of the previous lap are complete.
+func (d *Detector) detectLap(h *state.Heat, r *state.Race, alert alertFunc) {
+	laps := lapsOf(r)
+	n := len(laps)
+	if n < 2 {
+		return
+	}
+	last := r.Laps[len(r.Laps)-1]
+	if !isLapSource(last.PresentationSource.How) || last.Time-laps[n-1].time >= lapCluster || len(laps[n-1].sources) > 1 {
+		return
+	}
+	if n >= 3 {
+		prev, cur := laps[n-3], laps[n-2]
+		if strings.Join(prev.sources, ",") != strings.Join(cur.sources, ",") {
+			alert(SourceSwitch, Warning, "lap %d timed by %s, lap %d by %s",
+				n-2, strings.Join(prev.sources, ", "), n-1, strings.Join(cur.sources, ", "))
+		}
+	}
+
+	var before, between int
+	from, to := entities.Ticks(0), laps[n-1].time-lapCluster
+	if n >= 2 {
+		from = laps[n-2].time
+	}
+	for _, p := range r.Passings {
+		switch {
+		case p.Time < from-lapCluster:
+			before++
+		case p.Time > from+lapCluster && p.Time < to:
+			between++
+		}
+	}
+	if before > 0 && between == 0 {
+		alert(MissingPassings, Warning, "no passings between lap %d and lap %d", n-1, n)
+	}
+
+	for _, o := range h.Races {
+		if o.ID == r.ID {
+			continue
+		}
+		if other := len(lapsOf(o)); n-other == 2 {
+			alert(LaneMismatch, Warning, "%d laps registered, %d laps in lane %d", n, other, o.Lane)
+		}
+	}
+}
+
+// detectPassing detects duplicate and out-of-order passings when a passing is registered.
+func (d *Detector) detectPassing(r *state.Race, alert alertFunc) {
+	n := len(r.Passings)
+	if n < 2 {
+		return
+	}
+	p := r.Passings[n-1]
+	for _, q := range r.Passings[:n-1] {
+		if !sameSource(p, q) {
+			continue
+		}
+		if q.Where == p.Where && absTicks(q.Time-p.Time) <= duplicateTolerance {
+			alert(DuplicatePassing, Info, "duplicate passing at timing point %d at %s",
+				p.Where, results.FormatTime(p.Time, 0))
+			return
+		}
+	}
+	for i := n - 2; i >= 0; i-- {
+		q := r.Passings[i]
+		if !sameSource(p, q) {
+			continue
+		}
+		if q.Time > p.Time+duplicateTolerance {
+			alert(OutOfOrderPassing, Warning, "passing at timing point %d at %s after passing at timing point %d at %s",
+				p.Where, results.FormatTime(p.Time, 0), q.Where, results.FormatTime(q.Time, 0))
+		}
+		return
+	}
+}
+
+// detectPresentedLap detects lap times that deviate from the estimate.
+func (d *Detector) detectPresentedLap(dist *state.Distance, r *state.Race, l entities.PresentedLap, alert alertFunc) {
+	for _, e := range r.EstimatedLaps {
+		if e.Index != l.Index || e.LapTime <= 0 || l.LapTime <= 0 {
+			continue
+		}
+		deviation := float64(l.LapTime-e.LapTime) / float64(e.LapTime)
+		if math.Abs(deviation) > d.opts.LapTimeTolerance {
+			alert(LapTimeDeviation, Warning, "lap time %s at %gm deviates %.0f%% from estimate %s",
+				results.FormatTime(l.LapTime, dist.ClassificationPrecision), l.PassedLength, deviation*100,
+				results.FormatTime(e.LapTime, dist.ClassificationPrecision))
+		}
+		return
+	}
+}
+
+// sameSource returns whether the passings are registered by the same timing appliance in the same way.
+func sameSource(p, q entities.Passing) bool {
+	return p.InstanceName == q.InstanceName && p.PresentationSource == q.PresentationSource
+}
+
+func absTicks(t entities.Ticks) entities.Ticks {
+	if t < 0 {
+		return -t
+	}
+	return t
+}
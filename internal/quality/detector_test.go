@@ -0,0 +1,198 @@
+// Copyright © 2020 Emando B.V.
+
+package quality
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// second is a second in ticks.
+const second = entities.Ticks(10000000)
+
+// reg is a lap registration by the source.
+type reg struct {
+	how  string
+	time entities.Ticks
+}
+
+func raceWithLaps(id string, lane int, regs ...reg) *state.Race {
+	r := &state.Race{Race: entities.Race{ID: id, Lane: lane}}
+	for _, g := range regs {
+		r.Laps = append(r.Laps, entities.Lap{RaceID: id, Time: g.time, PresentationSource: entities.PresentationSource{How: g.how}})
+	}
+	return r
+}
+
+// recorder returns an alert function that records the alert types.
+func recorder(types *[]string) alertFunc {
+	return func(typ, severity, format string, args ...interface{}) {
+		*types = append(*types, typ)
+	}
+}
+
+func TestDetectLap(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		race     *state.Race
+		passings []entities.Ticks
+		others   []*state.Race
+		expected []string
+	}{
+		{
+			name:     "FirstLap",
+			race:     raceWithLaps("r", 0, reg{"Transponder", 10 * second}),
+			expected: nil,
+		},
+		{
+			name:     "SameSource",
+			race:     raceWithLaps("r", 0, reg{"Transponder", 10 * second}, reg{"Transponder", 20 * second}, reg{"Transponder", 30 * second}),
+			expected: nil,
+		},
+		{
+			name:     "SourceSwitch",
+			race:     raceWithLaps("r", 0, reg{"Transponder", 10 * second}, reg{"Manual", 20 * second}, reg{"Transponder", 30 * second}),
+			expected: []string{SourceSwitch},
+		},
+		{
+			name: "SecondSource",
+			race: raceWithLaps("r", 0,
+				reg{"Transponder", 10 * second}, reg{"Manual", 20 * second}, reg{"Transponder", 30 * second},
+				reg{"Manual", 30*second + 1000},
+			),
+			expected: nil,
+		},
+		{
+			name: "Photofinish",
+			race: raceWithLaps("r", 0,
+				reg{"Transponder", 10 * second}, reg{"Manual", 20 * second}, reg{"Transponder", 30 * second},
+				reg{photofinishSource, 30*second + 1000},
+			),
+			expected: nil,
+		},
+		{
+			name:     "MissingPassings",
+			race:     raceWithLaps("r", 0, reg{"Transponder", 10 * second}, reg{"Transponder", 20 * second}),
+			passings: []entities.Ticks{5 * second},
+			expected: []string{MissingPassings},
+		},
+		{
+			name:     "Passings",
+			race:     raceWithLaps("r", 0, reg{"Transponder", 10 * second}, reg{"Transponder", 20 * second}),
+			passings: []entities.Ticks{5 * second, 15 * second},
+			expected: nil,
+		},
+		{
+			name:     "LaneMismatch",
+			race:     raceWithLaps("r", 0, reg{"Transponder", 10 * second}, reg{"Transponder", 20 * second}),
+			others:   []*state.Race{raceWithLaps("o", 1)},
+			expected: []string{LaneMismatch},
+		},
+		{
+			name:     "LaneBehind",
+			race:     raceWithLaps("r", 0, reg{"Transponder", 10 * second}, reg{"Transponder", 20 * second}),
+			others:   []*state.Race{raceWithLaps("o", 1, reg{"Transponder", 11 * second})},
+			expected: nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, p := range tc.passings {
+				tc.race.Passings = append(tc.race.Passings, entities.Passing{Time: p})
+			}
+			h := &state.Heat{Races: append([]*state.Race{tc.race}, tc.others...)}
+			var res []string
+			NewDetector(Options{}).detectLap(h, tc.race, recorder(&res))
+			if !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("alerts are %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDetectPassing(t *testing.T) {
+	passing := func(where int, instance string, time entities.Ticks) entities.Passing {
+		return entities.Passing{Where: where, InstanceName: instance, Time: time}
+	}
+	for _, tc := range []struct {
+		name     string
+		passings []entities.Passing
+		expected []string
+	}{
+		{
+			name:     "First",
+			passings: []entities.Passing{passing(1, "a", 10*second)},
+		},
+		{
+			name:     "InOrder",
+			passings: []entities.Passing{passing(1, "a", 10*second), passing(2, "a", 15*second)},
+		},
+		{
+			name:     "Duplicate",
+			passings: []entities.Passing{passing(1, "a", 10*second), passing(1, "a", 10*second+duplicateTolerance)},
+			expected: []string{DuplicatePassing},
+		},
+		{
+			name:     "DuplicateOtherSource",
+			passings: []entities.Passing{passing(1, "a", 10*second), passing(1, "b", 10*second+duplicateTolerance)},
+		},
+		{
+			name:     "OutOfOrder",
+			passings: []entities.Passing{passing(1, "a", 10*second), passing(2, "a", 15*second), passing(3, "a", 12*second)},
+			expected: []string{OutOfOrderPassing},
+		},
+		{
+			name:     "OutOfOrderOtherSource",
+			passings: []entities.Passing{passing(1, "a", 10*second), passing(2, "b", 15*second), passing(3, "a", 12*second)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &state.Race{Passings: tc.passings}
+			var res []string
+			NewDetector(Options{}).detectPassing(r, recorder(&res))
+			if !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("alerts are %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDetectPresentedLap(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		tolerance float64
+		lap       entities.PresentedLap
+		expected  []string
+	}{
+		{name: "WithinTolerance", lap: entities.PresentedLap{Index: 1, LapTime: 27 * second}},
+		{name: "Slower", lap: entities.PresentedLap{Index: 1, LapTime: 30 * second}, expected: []string{LapTimeDeviation}},
+		{name: "Faster", lap: entities.PresentedLap{Index: 1, LapTime: 20 * second}, expected: []string{LapTimeDeviation}},
+		{name: "Tolerance", tolerance: 0.25, lap: entities.PresentedLap{Index: 1, LapTime: 30 * second}},
+		{name: "NoEstimate", lap: entities.PresentedLap{Index: 2, LapTime: 30 * second}},
+		{name: "NoLapTime", lap: entities.PresentedLap{Index: 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &state.Race{EstimatedLaps: []entities.PresentedLap{
+				{Index: 0, LapTime: 10 * second},
+				{Index: 1, LapTime: 25 * second},
+			}}
+			var res []string
+			NewDetector(Options{LapTimeTolerance: tc.tolerance}).detectPresentedLap(&state.Distance{}, r, tc.lap, recorder(&res))
+			if !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("alerts are %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDetectOtherEvents(t *testing.T) {
+	alerts, err := NewDetector(Options{}).Detect(state.NewStore(), []byte(`{"typeName":"HeatStartedEvent","competitionId":"c1"}`))
+	if err != nil || alerts != nil {
+		t.Errorf("alerts are %v with error %v, want none", alerts, err)
+	}
+	if _, err := NewDetector(Options{}).Detect(state.NewStore(), []byte(`{`)); err == nil {
+		t.Error("invalid event is detected")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright © 2020 Emando B.V.
+
+package quality
+
+import (
+	"sync"
+
+	"github.com/emando/vantage-events/pkg/state"
+	"go.uber.org/zap"
+)
+
+// recentAlerts is the number of recent alerts that the Monitor keeps.
+const recentAlerts = 1000
+
+// Monitor detects timing data anomalies in the events applied to the store, logs them and notifies subscribers.
+type Monitor struct {
+	logger   *zap.Logger
+	store    *state.Store
+	detector *Detector
+
+	mu          sync.Mutex
+	recent      []Alert
+	subscribers map[chan Alert]struct{}
+}
+
+// NewMonitor returns a new Monitor.
+func NewMonitor(logger *zap.Logger, store *state.Store, opts Options) *Monitor {
+	return &Monitor{
+		logger:      logger,
+		store:       store,
+		detector:    NewDetector(opts),
+		subscribers: make(map[chan Alert]struct{}),
+	}
+}
+
+// Handle detects anomalies on the JSON encoded event. The event must be applied to the store first.
+func (m *Monitor) Handle(buf []byte) {
+	alerts, err := m.detector.Detect(m.store, buf)
+	if err != nil {
+		m.logger.Debug("failed to detect anomalies", zap.Error(err))
+		return
+	}
+	for _, a := range alerts {
+		m.logger.Warn("timing data anomaly",
+			zap.String("type", a.Type),
+			zap.String("severity", a.Severity),
+			zap.String("competition_id", a.CompetitionID),
+			zap.String("distance_id", a.DistanceID),
+			zap.Int("heat_round", a.Round),
+			zap.Int("heat_number", a.Heat),
+			zap.Int("lane", a.Lane),
+			zap.String("competitor", a.Competitor),
+			zap.String("message", a.Message),
+		)
+		m.publish(a)
+	}
+}
+
+func (m *Monitor) publish(a Alert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recent = append(m.recent, a)
+	if n := len(m.recent); n > recentAlerts {
+		m.recent = append(m.recent[:0], m.recent[n-recentAlerts:]...)
+	}
+	for ch := range m.subscribers {
+		select {
+		case ch <- a:
+		default:
+		}
+	}
+}
+
+// Recent returns the recent alerts, oldest first. If competitionID is not empty, only alerts of the competition are
+// returned.
+func (m *Monitor) Recent(competitionID string) []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]Alert, 0, len(m.recent))
+	for _, a := range m.recent {
+		if competitionID == "" || a.CompetitionID == competitionID {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// Subscribe returns a channel that receives new alerts. Subscribers that do not keep up miss alerts.
+func (m *Monitor) Subscribe() chan Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch := make(chan Alert, 16)
+	m.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe stops sending alerts to the channel.
+func (m *Monitor) Unsubscribe(ch chan Alert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.subscribers, ch)
+}
@@ -0,0 +1,340 @@
+// Copyright © 2020 Emando B.V.
+
+package rpc
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/eventspb"
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func timestampProto(t time.Time) *timestamp.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	ts, err := ptypes.TimestampProto(t)
+	if err != nil {
+		return nil
+	}
+	return ts
+}
+
+func ticksProto(t *entities.Ticks) *eventspb.Ticks {
+	if t == nil {
+		return nil
+	}
+	return &eventspb.Ticks{Value: int64(*t)}
+}
+
+func competitionProto(c entities.Competition) *eventspb.Competition {
+	res := &eventspb.Competition{
+		Id:         c.ID,
+		Name:       c.Name,
+		Discipline: c.Discipline,
+		Class:      int32(c.Class),
+	}
+	if c.Venue != nil {
+		res.VenueCode = c.Venue.Code
+		res.VenueName = c.Venue.Name
+	}
+	return res
+}
+
+func distanceProto(d entities.Distance) *eventspb.Distance {
+	return &eventspb.Distance{
+		Id:                      d.ID,
+		Name:                    d.Name,
+		Number:                  int32(d.Number),
+		Discipline:              d.Discipline,
+		StartMode:               int32(d.StartMode),
+		ClassificationPrecision: int64(d.ClassificationPrecision),
+		TrackLength:             int32(d.TrackLength),
+		Value:                   int32(d.Value),
+		ValueQuantity:           int32(d.ValueQuantity),
+		Rounds:                  int32(d.Rounds),
+		FirstHeat:               int32(d.FirstHeat),
+	}
+}
+
+func heatKeyProto(round, number int) *eventspb.HeatKey {
+	return &eventspb.HeatKey{Round: int32(round), Number: int32(number)}
+}
+
+func heatRacesProto(races []entities.HeatRace) []*eventspb.HeatRace {
+	res := make([]*eventspb.HeatRace, 0, len(races))
+	for _, hr := range races {
+		res = append(res, &eventspb.HeatRace{
+			Race:          raceProto(hr.Race),
+			EstimatedLaps: presentedLapsProto(hr.EstimatedLaps),
+			Laps:          lapsProto(hr.Laps),
+			Passings:      passingsProto(hr.Passings),
+		})
+	}
+	return res
+}
+
+func raceProto(r entities.Race) *eventspb.Race {
+	res := &eventspb.Race{
+		Id:    r.ID,
+		Round: int32(r.Round),
+		Heat:  int32(r.Heat),
+		Lane:  int32(r.Lane),
+		Color: int32(r.Color),
+		Competitor: &eventspb.Competitor{
+			Id:              r.Competitor.ID,
+			TypeName:        r.Competitor.Type,
+			FullName:        r.Competitor.FullName,
+			ShortName:       r.Competitor.ShortName,
+			StartNumber:     int32(r.Competitor.StartNumber),
+			Category:        r.Competitor.Category,
+			NationalityCode: r.Competitor.NationalityCode,
+			PersonId:        r.Competitor.PersonID,
+		},
+		PersonalBest: ticksProto(r.PersonalBest),
+		SeasonBest:   ticksProto(r.SeasonBest),
+	}
+	for _, t := range r.Transponders {
+		res.Transponders = append(res.Transponders, &eventspb.Transponder{
+			Code:     t.Code,
+			Label:    t.Label,
+			PersonId: t.PersonID,
+			Set:      int32(t.Set),
+			Type:     t.Type,
+		})
+	}
+	if r.Time != nil {
+		res.Time = &eventspb.RaceTime{
+			Time:     int64(r.Time.Time),
+			TimeInfo: int32(r.Time.TimeInfo),
+			How:      r.Time.How,
+		}
+	}
+	if r.Result != nil {
+		res.Result = &eventspb.RaceResult{Status: int32(r.Result.Status)}
+	}
+	return res
+}
+
+func presentationSourceProto(s entities.PresentationSource) *eventspb.PresentationSource {
+	return &eventspb.PresentationSource{
+		ApplianceInstanceName: s.ApplianceInstanceName,
+		ApplianceName:         s.ApplianceName,
+		How:                   s.How,
+	}
+}
+
+func lapProto(l entities.Lap) *eventspb.Lap {
+	return &eventspb.Lap{
+		Time:               int64(l.Time),
+		When:               timestampProto(l.When),
+		Flags:              int32(l.Flags),
+		InstanceName:       l.InstanceName,
+		PresentationSource: presentationSourceProto(l.PresentationSource),
+	}
+}
+
+func lapsProto(laps []entities.Lap) []*eventspb.Lap {
+	res := make([]*eventspb.Lap, 0, len(laps))
+	for _, l := range laps {
+		res = append(res, lapProto(l))
+	}
+	return res
+}
+
+func presentedLapProto(l entities.PresentedLap) *eventspb.PresentedLap {
+	res := &eventspb.PresentedLap{
+		Index:        int32(l.Index),
+		Time:         int64(l.Time),
+		LapTime:      int64(l.LapTime),
+		PassedLength: l.PassedLength,
+		Rounds:       l.Rounds,
+		RoundsToGo:   l.RoundsToGo,
+	}
+	if l.Ranking != nil {
+		res.Ranking = int32(*l.Ranking)
+	}
+	return res
+}
+
+func presentedLapsProto(laps []entities.PresentedLap) []*eventspb.PresentedLap {
+	res := make([]*eventspb.PresentedLap, 0, len(laps))
+	for _, l := range laps {
+		res = append(res, presentedLapProto(l))
+	}
+	return res
+}
+
+func passingProto(p entities.Passing) *eventspb.Passing {
+	res := &eventspb.Passing{
+		Time:               int64(p.Time),
+		When:               timestampProto(p.When),
+		Where:              int32(p.Where),
+		Passed:             -1,
+		Speed:              -1,
+		Flags:              int32(p.Flags),
+		InstanceName:       p.InstanceName,
+		PresentationSource: presentationSourceProto(p.PresentationSource),
+	}
+	if p.Passed != nil {
+		res.Passed = *p.Passed
+	}
+	if p.Speed != nil {
+		res.Speed = *p.Speed
+	}
+	return res
+}
+
+func passingsProto(passings []entities.Passing) []*eventspb.Passing {
+	res := make([]*eventspb.Passing, 0, len(passings))
+	for _, p := range passings {
+		res = append(res, passingProto(p))
+	}
+	return res
+}
+
+// eventProto returns the protobuf message of the JSON encoded event. Events of unknown types only have the header
+// fields and the raw event.
+func eventProto(buf []byte) (*eventspb.Event, error) {
+	var header events.Race
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return nil, err
+	}
+	res := &eventspb.Event{
+		TypeName:      header.TypeName(),
+		CompetitionId: header.CompetitionID,
+		DistanceId:    header.DistanceID,
+		RaceId:        header.RaceID,
+		Raw:           buf,
+	}
+	if header.Key.Round != 0 || header.Key.Number != 0 {
+		res.Heat = heatKeyProto(header.Key.Round, header.Key.Number)
+	}
+	switch header.TypeName() {
+	case events.CompetitionActivatedType:
+		event := &events.CompetitionActivated{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_CompetitionActivated{CompetitionActivated: &eventspb.CompetitionActivated{
+			Competition: competitionProto(event.Value),
+		}}
+	case events.DistanceActivatedType:
+		event := &events.DistanceActivated{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_DistanceActivated{DistanceActivated: &eventspb.DistanceActivated{
+			Distance: distanceProto(event.Value),
+		}}
+	case events.HeatActivatedType:
+		event := &events.HeatActivated{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_HeatActivated{HeatActivated: &eventspb.HeatActivated{
+			Races: heatRacesProto(event.Races),
+		}}
+	case events.HeatStartedType:
+		event := &events.HeatStarted{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_HeatStarted{HeatStarted: &eventspb.HeatStarted{
+			Started: timestampProto(event.Started),
+		}}
+	case events.HeatCommittedType:
+		event := &events.HeatCommitted{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_HeatCommitted{HeatCommitted: &eventspb.HeatCommitted{
+			Races: heatRacesProto(event.Races),
+		}}
+	case events.RaceLapAddedType:
+		event := &events.RaceLapAdded{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_RaceLapAdded{RaceLapAdded: &eventspb.RaceLapAdded{
+			Lap: lapProto(event.Lap),
+		}}
+	case events.LastPresentedRaceLapChangedType:
+		event := &events.LastPresentedRaceLapChanged{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_LastPresentedRaceLapChanged{LastPresentedRaceLapChanged: &eventspb.LastPresentedRaceLapChanged{
+			Lap:            presentedLapProto(event.Lap),
+			TimeDifference: ticksProto(event.TimeDifference),
+		}}
+	case events.RacePassingAddedType:
+		event := &events.RacePassingAdded{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_RacePassingAdded{RacePassingAdded: &eventspb.RacePassingAdded{
+			Passing: passingProto(event.Passing),
+		}}
+	case events.LastRaceSpeedChangedType:
+		event := &events.LastRaceSpeedChanged{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_LastRaceSpeedChanged{LastRaceSpeedChanged: &eventspb.LastRaceSpeedChanged{
+			Passing: passingProto(event.Passing),
+		}}
+	case events.HeatNextLapIndexChangedType, events.RaceNextLapIndexChangedType:
+		event := &events.RaceNextLapIndexChanged{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return nil, err
+		}
+		res.Payload = &eventspb.Event_NextLapIndexChanged{NextLapIndexChanged: &eventspb.NextLapIndexChanged{
+			Index:        int32(event.Index),
+			PassedLength: event.PassedLength,
+			Rounds:       event.Rounds,
+			RoundsToGo:   event.RoundsToGo,
+		}}
+	}
+	return res, nil
+}
+
+func competitionStateProto(c *state.Competition) *eventspb.CompetitionState {
+	res := &eventspb.CompetitionState{
+		Competition:      competitionProto(c.Competition),
+		ActiveDistanceId: c.ActiveDistanceID,
+	}
+	for _, d := range c.Distances {
+		ds := &eventspb.DistanceState{
+			Distance: distanceProto(d.Distance),
+			Active:   d.Active,
+		}
+		for _, h := range d.Heats {
+			hs := &eventspb.HeatState{
+				Key:       heatKeyProto(h.Key.Round, h.Key.Number),
+				Active:    h.Active,
+				Started:   timestampProto(h.Started),
+				Committed: h.Committed,
+			}
+			for _, r := range h.Races {
+				hs.Races = append(hs.Races, &eventspb.RaceState{
+					Race:          raceProto(r.Race),
+					EstimatedLaps: presentedLapsProto(r.EstimatedLaps),
+					PresentedLaps: presentedLapsProto(r.PresentedLaps),
+				})
+			}
+			ds.Heats = append(ds.Heats, hs)
+		}
+		res.Distances = append(res.Distances, ds)
+	}
+	sort.Slice(res.Distances, func(i, j int) bool {
+		return res.Distances[i].Distance.Number < res.Distances[j].Distance.Number
+	})
+	return res
+}
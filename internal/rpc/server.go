@@ -0,0 +1,192 @@
+// Copyright © 2020 Emando B.V.
+
+// Package rpc serves Vantage events over gRPC.
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/emando/vantage-events/internal/follower"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/eventspb"
+	"github.com/emando/vantage-events/pkg/state"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// defaultWindow is the default time window to seek competition activations.
+const defaultWindow = 24 * time.Hour
+
+// Server is the gRPC events server.
+type Server struct {
+	logger   *zap.Logger
+	source   events.Source
+	store    *state.Store
+	address  string
+	certFile string
+	keyFile  string
+}
+
+// NewServer returns a new gRPC events server.
+func NewServer(logger *zap.Logger, source events.Source, store *state.Store, address, certFile, keyFile string) *Server {
+	return &Server{
+		logger:   logger,
+		source:   source,
+		store:    store,
+		address:  address,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+}
+
+// ListenAndServeTLS listens on the address and serves gRPC over TLS.
+func (s *Server) ListenAndServeTLS() error {
+	creds, err := credentials.NewServerTLSFromFile(s.certFile, s.keyFile)
+	if err != nil {
+		return err
+	}
+	lis, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer(grpc.Creds(creds))
+	eventspb.RegisterEventsServer(srv, s)
+	return srv.Serve(lis)
+}
+
+// ListCompetitions implements eventspb.EventsServer.
+func (s *Server) ListCompetitions(req *eventspb.ListCompetitionsRequest, stream eventspb.Events_ListCompetitionsServer) error {
+	window := time.Duration(req.WindowSeconds) * time.Second
+	if window <= 0 {
+		window = defaultWindow
+	}
+	ctx := stream.Context()
+	activations, err := s.source.CompetitionActivations(ctx, window)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case activation, ok := <-activations:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&eventspb.CompetitionActivated{
+				Competition: competitionProto(activation.Value),
+				Time:        timestampProto(activation.Time),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamCompetition implements eventspb.EventsServer.
+func (s *Server) StreamCompetition(req *eventspb.StreamCompetitionRequest, stream eventspb.Events_StreamCompetitionServer) error {
+	if req.CompetitionId == "" {
+		return status.Error(codes.InvalidArgument, "competition ID is required")
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	logger := s.logger.With(zap.String("competition_id", req.CompetitionId))
+	f := &follower.Follower{
+		Logger: logger,
+		Source: s.source,
+	}
+	eventsCh, err := f.Run(ctx, defaultWindow, req.CompetitionId)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	rawCh := follower.Raw(ctx, eventsCh)
+
+	// Events are replayed from the activation. When resuming, skip events up to and including the cursor. The stream
+	// fails if the replay is delivered without the cursor, so that clients start over.
+	resumed := req.Cursor == ""
+	var replays follower.Replays
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case buf := <-rawCh:
+			var header events.Base
+			// Clients of the gRPC API resume with cursors instead of StreamLiveEvents.
+			live := json.Unmarshal(buf, &header) == nil && header.TypeName() == events.StreamLiveType
+			cursor := events.Cursor(buf)
+			if !resumed {
+				if err := replays.Update(buf); err != nil {
+					logger.Debug("failed to unmarshal event", zap.Error(err))
+				}
+				resumed = !live && cursor == req.Cursor
+				if !resumed && replays.Delivered() {
+					return status.Errorf(codes.NotFound, "cursor %q not found", req.Cursor)
+				}
+				continue
+			}
+			if live {
+				continue
+			}
+			event, err := eventProto(buf)
+			if err != nil {
+				logger.Warn("failed to convert event", zap.Error(err))
+				continue
+			}
+			if !match(event, req) {
+				continue
+			}
+			event.Cursor = cursor
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// match returns whether the event matches the filters of the request.
+func match(event *eventspb.Event, req *eventspb.StreamCompetitionRequest) bool {
+	if len(req.Types) > 0 {
+		var found bool
+		for _, t := range req.Types {
+			if strings.EqualFold(t, event.TypeName) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(req.DistanceIds) > 0 && event.DistanceId != "" {
+		var found bool
+		for _, id := range req.DistanceIds {
+			if strings.EqualFold(id, event.DistanceId) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// GetState implements eventspb.EventsServer.
+func (s *Server) GetState(ctx context.Context, req *eventspb.GetStateRequest) (*eventspb.CompetitionState, error) {
+	var res *eventspb.CompetitionState
+	if !s.store.Competition(req.CompetitionId, func(c *state.Competition) {
+		res = competitionStateProto(c)
+	}) {
+		return nil, status.Error(codes.NotFound, "competition not found")
+	}
+	return res, nil
+}
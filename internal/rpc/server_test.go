@@ -0,0 +1,262 @@
+// Copyright © 2020 Emando B.V.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/eventspb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	competitionActivated = `{"typeName":"CompetitionActivatedEvent","competitionId":"c1","competition":{"id":"c1","name":"EC Single Distances"}}`
+	distanceActivated    = `{"typeName":"DistanceActivatedEvent","competitionId":"c1","distanceId":"d1","distance":{"id":"d1","name":"Men 500 meter"}}`
+)
+
+// distanceEvents are the replayed events of the distance of the source.
+var distanceEvents = []string{
+	`{"typeName":"DistanceUpdatedEvent","competitionId":"c1","distanceId":"d1","_cursor":"competition.c1.distances.d1:1"}`,
+	`{"typeName":"DistanceUpdatedEvent","competitionId":"c1","distanceId":"d1","_cursor":"competition.c1.distances.d1:2"}`,
+}
+
+// source is a source of a competition with a distance without heats.
+type source struct {
+	t *testing.T
+}
+
+func (source) CompetitionActivations(ctx context.Context, history time.Duration) (<-chan *events.CompetitionActivated, error) {
+	ch := make(chan *events.CompetitionActivated, 1)
+	ch <- &events.CompetitionActivated{
+		Competition: events.Competition{CompetitionID: "c1"},
+		Value:       entities.Competition{ID: "c1"},
+		Time:        time.Now(),
+		Raw:         []byte(competitionActivated),
+	}
+	return ch, nil
+}
+
+func (source) CompetitionEvents(ctx context.Context, since *events.CompetitionActivated, after uint64) (*events.Replay, error) {
+	return &events.Replay{Events: make(chan *events.Raw)}, nil
+}
+
+func (source) DistanceActivations(ctx context.Context, competitionID string) (*events.DistanceActivations, error) {
+	ch, replayed := make(chan *events.DistanceActivated), make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case ch <- &events.DistanceActivated{
+			Distance: events.Distance{Competition: events.Competition{CompetitionID: "c1"}, DistanceID: "d1"},
+			Value:    entities.Distance{ID: "d1"},
+			Time:     time.Now(),
+			Raw:      []byte(distanceActivated),
+		}:
+			close(replayed)
+		}
+	}()
+	return &events.DistanceActivations{Activations: ch, Replayed: replayed}, nil
+}
+
+func (s source) DistanceEvents(ctx context.Context, since *events.DistanceActivated, after uint64) (*events.Replay, error) {
+	ch := make(chan *events.Raw)
+	go func() {
+		for i, buf := range distanceEvents {
+			event := &events.Raw{Bytes: []byte(buf), Sequence: uint64(i + 1)}
+			if err := json.Unmarshal(event.Bytes, event); err != nil {
+				s.t.Error(err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- event:
+			}
+		}
+	}()
+	return &events.Replay{Events: ch, Last: uint64(len(distanceEvents))}, nil
+}
+
+func (source) HeatActivations(ctx context.Context, competitionID, distanceID string, groups ...int) (*events.HeatActivations, error) {
+	replayed := make(chan struct{})
+	close(replayed)
+	return &events.HeatActivations{Activations: make(chan *events.HeatActivated), Replayed: replayed}, nil
+}
+
+func (source) HeatEvents(ctx context.Context, since *events.HeatActivated, after uint64) (*events.Replay, error) {
+	return &events.Replay{Events: make(chan *events.Raw)}, nil
+}
+
+// stream is a server stream that sends events to a channel.
+type stream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *eventspb.Event
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *stream) Send(event *eventspb.Event) error {
+	select {
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	case s.events <- event:
+		return nil
+	}
+}
+
+func TestStreamCompetition(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		req      *eventspb.StreamCompetitionRequest
+		expected []string
+		code     codes.Code
+	}{
+		{
+			name:     "NoCursor",
+			req:      &eventspb.StreamCompetitionRequest{CompetitionId: "c1", Types: []string{"DistanceUpdatedEvent"}},
+			expected: []string{"competition.c1.distances.d1:1", "competition.c1.distances.d1:2"},
+		},
+		{
+			name: "FirstCursor",
+			req: &eventspb.StreamCompetitionRequest{
+				CompetitionId: "c1",
+				Types:         []string{"DistanceUpdatedEvent"},
+				Cursor:        "competition.c1.distances.d1:1",
+			},
+			expected: []string{"competition.c1.distances.d1:2"},
+		},
+		{
+			name: "LastCursor",
+			req: &eventspb.StreamCompetitionRequest{
+				CompetitionId: "c1",
+				Types:         []string{"DistanceUpdatedEvent"},
+				Cursor:        "competition.c1.distances.d1:2",
+			},
+		},
+		{
+			name: "OtherDistance",
+			req: &eventspb.StreamCompetitionRequest{
+				CompetitionId: "c1",
+				Types:         []string{"DistanceUpdatedEvent"},
+				DistanceIds:   []string{"d2"},
+			},
+		},
+		{
+			name: "CursorNotFound",
+			req:  &eventspb.StreamCompetitionRequest{CompetitionId: "c1", Cursor: "competition.c1.distances.d1:3"},
+			code: codes.NotFound,
+		},
+		{
+			name: "NoCompetition",
+			req:  &eventspb.StreamCompetitionRequest{},
+			code: codes.InvalidArgument,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			s := NewServer(zap.NewNop(), source{t: t}, nil, "", "", "")
+			out := &stream{ctx: ctx, events: make(chan *eventspb.Event)}
+			errs := make(chan error, 1)
+			go func() {
+				errs <- s.StreamCompetition(tc.req, out)
+			}()
+
+			var cursors []string
+			timeout := time.After(200 * time.Millisecond)
+		loop:
+			for {
+				select {
+				case event := <-out.events:
+					cursors = append(cursors, event.Cursor)
+				case err := <-errs:
+					if status.Code(err) != tc.code {
+						t.Fatalf("error is %v, want %v", err, tc.code)
+					}
+					return
+				case <-timeout:
+					break loop
+				}
+			}
+			if !reflect.DeepEqual(cursors, tc.expected) {
+				t.Errorf("cursors are %v, want %v", cursors, tc.expected)
+			}
+			cancel()
+			if err := <-errs; status.Code(err) != tc.code {
+				t.Errorf("error is %v, want %v", err, tc.code)
+			}
+		})
+	}
+}
+
+func TestEventProto(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		event    string
+		distance string
+		heat     *eventspb.HeatKey
+		payload  bool
+	}{
+		{name: "CompetitionActivated", event: competitionActivated, payload: true},
+		{name: "DistanceActivated", event: distanceActivated, distance: "d1", payload: true},
+		{
+			name:     "HeatStarted",
+			event:    `{"typeName":"HeatStartedEvent","competitionId":"c1","distanceId":"d1","heat":{"round":1,"number":2},"started":"2020-01-12T10:00:00Z"}`,
+			distance: "d1",
+			heat:     &eventspb.HeatKey{Round: 1, Number: 2},
+			payload:  true,
+		},
+		{name: "Unknown", event: `{"typeName":"DistanceUpdatedEvent","competitionId":"c1","distanceId":"d1"}`, distance: "d1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := eventProto([]byte(tc.event))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if event.CompetitionId != "c1" || event.DistanceId != tc.distance || string(event.Raw) != tc.event {
+				t.Errorf("event is %+v", event)
+			}
+			if !reflect.DeepEqual(event.Heat, tc.heat) {
+				t.Errorf("heat is %v, want %v", event.Heat, tc.heat)
+			}
+			if (event.Payload != nil) != tc.payload {
+				t.Errorf("payload is %v, want payload %v", event.Payload, tc.payload)
+			}
+		})
+	}
+	if _, err := eventProto([]byte(`{`)); err == nil {
+		t.Error("invalid event is converted")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		event    *eventspb.Event
+		req      *eventspb.StreamCompetitionRequest
+		expected bool
+	}{
+		{name: "NoFilters", event: &eventspb.Event{TypeName: "HeatCommittedEvent"}, req: &eventspb.StreamCompetitionRequest{}, expected: true},
+		{name: "Type", event: &eventspb.Event{TypeName: "HeatCommittedEvent"}, req: &eventspb.StreamCompetitionRequest{Types: []string{"heatcommittedevent"}}, expected: true},
+		{name: "OtherType", event: &eventspb.Event{TypeName: "HeatStartedEvent"}, req: &eventspb.StreamCompetitionRequest{Types: []string{"HeatCommittedEvent"}}},
+		{name: "Distance", event: &eventspb.Event{DistanceId: "d1"}, req: &eventspb.StreamCompetitionRequest{DistanceIds: []string{"d1"}}, expected: true},
+		{name: "OtherDistance", event: &eventspb.Event{DistanceId: "d2"}, req: &eventspb.StreamCompetitionRequest{DistanceIds: []string{"d1"}}},
+		{name: "CompetitionEvent", event: &eventspb.Event{}, req: &eventspb.StreamCompetitionRequest{DistanceIds: []string{"d1"}}, expected: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if res := match(tc.event, tc.req); res != tc.expected {
+				t.Errorf("match is %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,571 @@
+// Copyright © 2020 Emando B.V.
+
+// Package webhook delivers selected events to HTTP endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/events"
+	"go.uber.org/zap"
+)
+
+// Headers of deliveries.
+const (
+	EventHeader     = "X-Vantage-Event"
+	DeliveryHeader  = "X-Vantage-Delivery"
+	TimestampHeader = "X-Vantage-Timestamp"
+	SignatureHeader = "X-Vantage-Signature"
+)
+
+// Endpoint is a webhook endpoint. The name is used as directory of the retry queue, so it consists of letters, digits,
+// dots, dashes and underscores.
+type Endpoint struct {
+	Name         string   `mapstructure:"name"`
+	URL          string   `mapstructure:"url"`
+	Secret       string   `mapstructure:"secret"`
+	Types        []string `mapstructure:"types"`
+	Competitions []string `mapstructure:"competitions"`
+}
+
+// validName matches valid endpoint names.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+func (e Endpoint) matches(typeName, competitionID string) bool {
+	return contains(e.Types, typeName) && contains(e.Competitions, competitionID)
+}
+
+// contains returns whether the value is in the list. An empty list contains all values.
+func contains(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Options contains options for webhook delivery.
+type Options struct {
+	Endpoints []Endpoint
+	// Dir is the directory with the retry queue and delivery log.
+	Dir         string
+	MaxAttempts int
+	InitialBackoff,
+	MaxBackoff,
+	Timeout time.Duration
+}
+
+// Dispatcher delivers events to webhook endpoints.
+type Dispatcher struct {
+	logger  *zap.Logger
+	opts    Options
+	client  *http.Client
+	queues  []*queue
+	seen    *seen
+	logMu   sync.Mutex
+	logFile *os.File
+
+	// mu guards closed: Handle and log hold a read lock while writing files.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// delivery is a pending delivery of an event to an endpoint.
+type delivery struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"typeName"`
+	CompetitionID string          `json:"competitionId"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	Created       time.Time       `json:"created"`
+}
+
+// queue is the persistent queue of deliveries to an endpoint.
+type queue struct {
+	endpoint Endpoint
+	dir      string
+	mu       sync.Mutex
+	pending  []*delivery
+	notify   chan struct{}
+}
+
+// New returns a new Dispatcher. Pending deliveries in the retry queue are loaded from disk.
+func New(logger *zap.Logger, opts Options) (*Dispatcher, error) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 5 * time.Minute
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, err
+	}
+	logFile, err := os.OpenFile(filepath.Join(opts.Dir, "deliveries.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	seen, err := loadSeen(filepath.Join(opts.Dir, "seen"), maxSeen)
+	if err != nil {
+		logFile.Close()
+		return nil, err
+	}
+	d := &Dispatcher{
+		logger:  logger,
+		opts:    opts,
+		client:  &http.Client{Timeout: opts.Timeout},
+		seen:    seen,
+		logFile: logFile,
+	}
+	names := make(map[string]struct{}, len(opts.Endpoints))
+	for _, endpoint := range opts.Endpoints {
+		if endpoint.Name == "" {
+			d.close()
+			return nil, fmt.Errorf("webhook: endpoint %v has no name", endpoint.URL)
+		}
+		if !validName.MatchString(endpoint.Name) {
+			d.close()
+			return nil, fmt.Errorf("webhook: invalid name %q of endpoint %v", endpoint.Name, endpoint.URL)
+		}
+		if _, ok := names[strings.ToLower(endpoint.Name)]; ok {
+			d.close()
+			return nil, fmt.Errorf("webhook: duplicate endpoint name %q", endpoint.Name)
+		}
+		names[strings.ToLower(endpoint.Name)] = struct{}{}
+		q := &queue{
+			endpoint: endpoint,
+			dir:      filepath.Join(opts.Dir, "queue", endpoint.Name),
+			notify:   make(chan struct{}, 1),
+		}
+		if err := q.load(); err != nil {
+			d.close()
+			return nil, err
+		}
+		if len(q.pending) > 0 {
+			logger.Info("loaded pending webhook deliveries",
+				zap.String("endpoint", endpoint.Name),
+				zap.Int("count", len(q.pending)),
+			)
+		}
+		d.queues = append(d.queues, q)
+	}
+	return d, nil
+}
+
+func (q *queue) load() error {
+	if err := os.MkdirAll(q.dir, 0755); err != nil {
+		return err
+	}
+	infos, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		return err
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+	for _, info := range infos {
+		if filepath.Ext(info.Name()) != ".json" {
+			continue
+		}
+		buf, err := ioutil.ReadFile(filepath.Join(q.dir, info.Name()))
+		if err != nil {
+			return err
+		}
+		dl := &delivery{}
+		if err := json.Unmarshal(buf, dl); err != nil {
+			return err
+		}
+		q.pending = append(q.pending, dl)
+	}
+	return nil
+}
+
+func (q *queue) path(dl *delivery) string {
+	return filepath.Join(q.dir, dl.ID+".json")
+}
+
+// save persists the delivery.
+func (q *queue) save(dl *delivery) error {
+	buf, err := json.Marshal(dl)
+	if err != nil {
+		return err
+	}
+	tmp := q.path(dl) + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.path(dl))
+}
+
+func (q *queue) push(dl *delivery) error {
+	if err := q.save(dl); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	q.pending = append(q.pending, dl)
+	q.mu.Unlock()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *queue) peek() *delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return nil
+	}
+	return q.pending[0]
+}
+
+func (q *queue) pop() error {
+	q.mu.Lock()
+	dl := q.pending[0]
+	q.pending = q.pending[1:]
+	q.mu.Unlock()
+	return os.Remove(q.path(dl))
+}
+
+func newID() string {
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return fmt.Sprintf("%020d-%s", time.Now().UnixNano(), hex.EncodeToString(buf))
+}
+
+func (d *Dispatcher) close() {
+	d.logFile.Close()
+	d.seen.file.Close()
+}
+
+// Close closes the files of the Dispatcher. Events that are handled after closing are ignored.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	d.closed = true
+	d.close()
+}
+
+// Handle enqueues the JSON encoded event for delivery to matching endpoints.
+// Events that have been enqueued before, i.e. when the source replays history after a restart, are ignored.
+func (d *Dispatcher) Handle(buf []byte) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	var header events.Competition
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return
+	}
+	var matches bool
+	for _, q := range d.queues {
+		if q.endpoint.matches(header.TypeName(), header.CompetitionID) {
+			matches = true
+			break
+		}
+	}
+	if !matches {
+		return
+	}
+	if first, err := d.seen.add(buf); err != nil {
+		d.logger.Warn("failed to persist event digest", zap.Error(err))
+	} else if !first {
+		return
+	}
+	for _, q := range d.queues {
+		if !q.endpoint.matches(header.TypeName(), header.CompetitionID) {
+			continue
+		}
+		dl := &delivery{
+			ID:            newID(),
+			Type:          header.TypeName(),
+			CompetitionID: header.CompetitionID,
+			Payload:       append(buf[:0:0], buf...),
+			Created:       time.Now().UTC(),
+		}
+		if err := q.push(dl); err != nil {
+			d.logger.Error("failed to enqueue webhook delivery",
+				zap.String("endpoint", q.endpoint.Name),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// Run delivers events until the context is done. Close the Dispatcher to close its files.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, q := range d.queues {
+		wg.Add(1)
+		go func(q *queue) {
+			defer wg.Done()
+			d.run(ctx, q)
+		}(q)
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.opts.InitialBackoff
+	for i := 1; i < attempts && wait < d.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > d.opts.MaxBackoff {
+		wait = d.opts.MaxBackoff
+	}
+	return wait
+}
+
+// run delivers the queued events in order. A failed delivery is retried before subsequent deliveries.
+func (d *Dispatcher) run(ctx context.Context, q *queue) {
+	logger := d.logger.With(zap.String("endpoint", q.endpoint.Name))
+	for {
+		dl := q.peek()
+		if dl == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.notify:
+				continue
+			}
+		}
+		dl.Attempts++
+		status, err := d.deliver(ctx, q.endpoint, dl)
+		switch {
+		case err == nil:
+			d.log(q.endpoint, dl, status, nil, "delivered")
+			if err := q.pop(); err != nil {
+				logger.Warn("failed to remove delivery from queue", zap.Error(err))
+			}
+			continue
+		case ctx.Err() != nil:
+			return
+		case dl.Attempts >= d.opts.MaxAttempts:
+			d.log(q.endpoint, dl, status, err, "failed")
+			logger.Warn("webhook delivery failed", zap.String("delivery", dl.ID), zap.Error(err))
+			if err := q.pop(); err != nil {
+				logger.Warn("failed to remove delivery from queue", zap.Error(err))
+			}
+			continue
+		}
+		d.log(q.endpoint, dl, status, err, "retry")
+		if err := q.save(dl); err != nil {
+			logger.Warn("failed to update delivery in queue", zap.Error(err))
+		}
+		wait := d.backoff(dl.Attempts)
+		logger.Debug("retrying webhook delivery",
+			zap.String("delivery", dl.ID),
+			zap.Int("attempts", dl.Attempts),
+			zap.Duration("wait", wait),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, endpoint Endpoint, dl *delivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, dl.Type)
+	req.Header.Set(DeliveryHeader, dl.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, timestamp, dl.Payload))
+	}
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook: unexpected status %v", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// log appends the outcome of a delivery attempt to the delivery log.
+func (d *Dispatcher) log(endpoint Endpoint, dl *delivery, status int, err error, outcome string) {
+	entry := struct {
+		Time     time.Time `json:"time"`
+		Endpoint string    `json:"endpoint"`
+		Delivery string    `json:"delivery"`
+		Type     string    `json:"typeName"`
+		Attempt  int       `json:"attempt"`
+		Status   int       `json:"status,omitempty"`
+		Error    string    `json:"error,omitempty"`
+		Outcome  string    `json:"outcome"`
+	}{
+		Time:     time.Now().UTC(),
+		Endpoint: endpoint.Name,
+		Delivery: dl.ID,
+		Type:     dl.Type,
+		Attempt:  dl.Attempts,
+		Status:   status,
+		Outcome:  outcome,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	buf, _ := json.Marshal(entry)
+	buf = append(buf, '\n')
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	d.logMu.Lock()
+	defer d.logMu.Unlock()
+	if _, err := d.logFile.Write(buf); err != nil {
+		d.logger.Warn("failed to write delivery log", zap.Error(err))
+	}
+}
+
+// maxSeen is the number of event digests that are retained.
+const maxSeen = 100000
+
+// seen is the persistent set of digests of enqueued events. The file is compacted to the retained digests when it has
+// twice as many lines.
+type seen struct {
+	mu      sync.Mutex
+	name    string
+	max     int
+	digests map[string]struct{}
+	order   []string
+	file    *os.File
+	lines   int
+}
+
+// loadSeen loads the digests from the file and compacts the file to the max most recent digests.
+func loadSeen(name string, max int) (*seen, error) {
+	s := &seen{
+		name:    name,
+		max:     max,
+		digests: make(map[string]struct{}),
+	}
+	buf, err := ioutil.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	lines := strings.Fields(string(buf))
+	if len(lines) > max {
+		lines = lines[len(lines)-max:]
+	}
+	for _, digest := range lines {
+		s.digests[digest] = struct{}{}
+		s.order = append(s.order, digest)
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// compact rewrites the file with the retained digests.
+func (s *seen) compact() error {
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			return err
+		}
+		s.file = nil
+	}
+	tmp := s.name + ".tmp"
+	lines := append(s.order[:len(s.order):len(s.order)], "")
+	if err := ioutil.WriteFile(tmp, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.name); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.name, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.lines = len(s.order)
+	return nil
+}
+
+// add adds the digest of the payload and returns whether it was not seen before.
+func (s *seen) add(payload []byte) (bool, error) {
+	sum := sha256.Sum256(payload)
+	digest := hex.EncodeToString(sum[:])
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.digests[digest]; ok {
+		return false, nil
+	}
+	s.digests[digest] = struct{}{}
+	s.order = append(s.order, digest)
+	if len(s.order) > s.max {
+		delete(s.digests, s.order[0])
+		s.order = s.order[1:]
+	}
+	if s.file == nil {
+		// Compacting failed before.
+		return true, s.compact()
+	}
+	if _, err := s.file.WriteString(digest + "\n"); err != nil {
+		return true, err
+	}
+	if s.lines++; s.lines >= 2*s.max {
+		return true, s.compact()
+	}
+	return true, nil
+}
+
+// Sign returns the signature of the payload sent at the timestamp.
+// The signature is the hex encoded HMAC-SHA256 of the timestamp, a dot and the payload, prefixed with sha256=.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns whether the signature of the payload sent at the timestamp is valid.
+func Verify(secret, timestamp string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
@@ -0,0 +1,133 @@
+// Copyright © 2020 Emando B.V.
+
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDispatcherClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	d, err := New(zap.NewNop(), Options{
+		Endpoints: []Endpoint{{Name: "test", URL: "http://127.0.0.1:1"}},
+		Dir:       dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.Run(ctx)
+
+	// Run does not close the files, so that events are handled until the Dispatcher is closed.
+	d.Handle([]byte(`{"typeName":"HeatCommittedEvent","competitionId":"c1"}`))
+	if n := len(d.queues[0].pending); n != 1 {
+		t.Fatalf("%d pending deliveries before close, want 1", n)
+	}
+	d.Close()
+	d.Handle([]byte(`{"typeName":"HeatCommittedEvent","competitionId":"c2"}`))
+	if n := len(d.queues[0].pending); n != 1 {
+		t.Errorf("%d pending deliveries after close, want 1", n)
+	}
+	d.Close()
+}
+
+func TestNewEndpointNames(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		names []string
+		err   bool
+	}{
+		{name: "Valid", names: []string{"results-website", "scoreboard_1.2"}},
+		{name: "Empty", names: []string{""}, err: true},
+		{name: "Parent", names: []string{".."}, err: true},
+		{name: "Path", names: []string{"a/../../b"}, err: true},
+		{name: "Absolute", names: []string{"/tmp/queue"}, err: true},
+		{name: "Duplicate", names: []string{"results", "Results"}, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "webhook")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			var endpoints []Endpoint
+			for _, name := range tc.names {
+				endpoints = append(endpoints, Endpoint{Name: name, URL: "http://127.0.0.1:1"})
+			}
+			d, err := New(zap.NewNop(), Options{Endpoints: endpoints, Dir: dir})
+			if (err != nil) != tc.err {
+				t.Fatalf("error is %v, want error %v", err, tc.err)
+			}
+			if d != nil {
+				d.Close()
+			}
+		})
+	}
+}
+
+func TestSeenCompact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "seen")
+	lines := func() int {
+		buf, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(strings.Fields(string(buf)))
+	}
+
+	s, err := loadSeen(name, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []int{1, 2, 3, 4, 5, 3, 4} {
+		if first, err := s.add([]byte(strconv.Itoa(i))); err != nil || !first {
+			t.Fatalf("add %d is %v (%v), want first", i, first, err)
+		}
+		if n := lines(); n != expected {
+			t.Errorf("%d lines after adding %d, want %d", n, i, expected)
+		}
+	}
+	if first, _ := s.add([]byte("6")); first {
+		t.Error("6 is added twice")
+	}
+	s.file.Close()
+
+	// Loading retains the most recent digests.
+	if s, err = loadSeen(name, 2); err != nil {
+		t.Fatal(err)
+	}
+	defer s.file.Close()
+	if n := lines(); n != 2 {
+		t.Errorf("%d lines after loading, want 2", n)
+	}
+	for _, tc := range []struct {
+		payload string
+		first   bool
+	}{
+		{payload: "5"},
+		{payload: "6"},
+		{payload: "4", first: true},
+	} {
+		if first, _ := s.add([]byte(tc.payload)); first != tc.first {
+			t.Errorf("add %s after loading is %v, want %v", tc.payload, first, tc.first)
+		}
+	}
+}
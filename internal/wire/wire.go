@@ -0,0 +1,265 @@
+// Copyright © 2020 Emando B.V.
+
+// Package wire encodes JSON events in binary formats.
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MessagePack returns the JSON value encoded as MessagePack. Integers are encoded as integers, other numbers as 64-bit
+// floats. Map keys are sorted.
+func MessagePack(buf []byte) ([]byte, error) {
+	v, err := decode(buf)
+	if err != nil {
+		return nil, err
+	}
+	var e msgpackEncoder
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// CBOR returns the JSON value encoded as CBOR (RFC 7049). Integers are encoded as integers, other numbers as 64-bit
+// floats. Map keys are sorted.
+func CBOR(buf []byte) ([]byte, error) {
+	v, err := decode(buf)
+	if err != nil {
+		return nil, err
+	}
+	var e cborEncoder
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// decode decodes the JSON value with numbers as json.Number, to keep the precision of integers.
+func decode(buf []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(buf))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// number returns the JSON number as int64 if it is an integer, or as float64.
+func number(n json.Number) (interface{}, error) {
+	if i, err := n.Int64(); err == nil {
+		return i, nil
+	}
+	return n.Float64()
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type msgpackEncoder struct {
+	bytes.Buffer
+}
+
+func (e *msgpackEncoder) encode(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.WriteByte(0xc0)
+	case bool:
+		if v {
+			e.WriteByte(0xc3)
+		} else {
+			e.WriteByte(0xc2)
+		}
+	case json.Number:
+		n, err := number(v)
+		if err != nil {
+			return err
+		}
+		if i, ok := n.(int64); ok {
+			e.int(i)
+		} else {
+			e.WriteByte(0xcb)
+			e.uint(math.Float64bits(n.(float64)), 8)
+		}
+	case string:
+		e.length(len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		e.WriteString(v)
+	case []interface{}:
+		e.length(len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		e.length(len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, k := range sortedKeys(v) {
+			if err := e.encode(k); err != nil {
+				return err
+			}
+			if err := e.encode(v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("wire: unsupported type %T", v)
+	}
+	return nil
+}
+
+// length writes the length with the fix type if the length is less than fixMax, or with the 8, 16 or 32-bit type.
+// The 8-bit type is not used if it is 0.
+func (e *msgpackEncoder) length(n int, fix byte, fixMax int, t8, t16, t32 byte) {
+	switch {
+	case n < fixMax:
+		e.WriteByte(fix | byte(n))
+	case t8 != 0 && n <= math.MaxUint8:
+		e.WriteByte(t8)
+		e.uint(uint64(n), 1)
+	case n <= math.MaxUint16:
+		e.WriteByte(t16)
+		e.uint(uint64(n), 2)
+	default:
+		e.WriteByte(t32)
+		e.uint(uint64(n), 4)
+	}
+}
+
+func (e *msgpackEncoder) int(i int64) {
+	switch {
+	case i >= 0 && i < 128, i < 0 && i >= -32:
+		e.WriteByte(byte(i))
+	case i >= 0 && i <= math.MaxUint8:
+		e.WriteByte(0xcc)
+		e.uint(uint64(i), 1)
+	case i >= 0 && i <= math.MaxUint16:
+		e.WriteByte(0xcd)
+		e.uint(uint64(i), 2)
+	case i >= 0 && i <= math.MaxUint32:
+		e.WriteByte(0xce)
+		e.uint(uint64(i), 4)
+	case i >= 0:
+		e.WriteByte(0xcf)
+		e.uint(uint64(i), 8)
+	case i >= math.MinInt8:
+		e.WriteByte(0xd0)
+		e.uint(uint64(i), 1)
+	case i >= math.MinInt16:
+		e.WriteByte(0xd1)
+		e.uint(uint64(i), 2)
+	case i >= math.MinInt32:
+		e.WriteByte(0xd2)
+		e.uint(uint64(i), 4)
+	default:
+		e.WriteByte(0xd3)
+		e.uint(uint64(i), 8)
+	}
+}
+
+func (e *msgpackEncoder) uint(v uint64, size int) {
+	writeUint(&e.Buffer, v, size)
+}
+
+type cborEncoder struct {
+	bytes.Buffer
+}
+
+// CBOR major types.
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+)
+
+func (e *cborEncoder) encode(v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		e.WriteByte(0xf6)
+	case bool:
+		if v {
+			e.WriteByte(0xf5)
+		} else {
+			e.WriteByte(0xf4)
+		}
+	case json.Number:
+		n, err := number(v)
+		if err != nil {
+			return err
+		}
+		if i, ok := n.(int64); ok {
+			if i >= 0 {
+				e.head(cborUint, uint64(i))
+			} else {
+				e.head(cborNegint, uint64(-1-i))
+			}
+		} else {
+			e.WriteByte(0xfb)
+			writeUint(&e.Buffer, math.Float64bits(n.(float64)), 8)
+		}
+	case string:
+		e.head(cborText, uint64(len(v)))
+		e.WriteString(v)
+	case []interface{}:
+		e.head(cborArray, uint64(len(v)))
+		for _, item := range v {
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		e.head(cborMap, uint64(len(v)))
+		for _, k := range sortedKeys(v) {
+			if err := e.encode(k); err != nil {
+				return err
+			}
+			if err := e.encode(v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("wire: unsupported type %T", v)
+	}
+	return nil
+}
+
+// head writes the initial byte of the major type with the argument.
+func (e *cborEncoder) head(major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		e.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		e.WriteByte(major | 24)
+		writeUint(&e.Buffer, n, 1)
+	case n <= math.MaxUint16:
+		e.WriteByte(major | 25)
+		writeUint(&e.Buffer, n, 2)
+	case n <= math.MaxUint32:
+		e.WriteByte(major | 26)
+		writeUint(&e.Buffer, n, 4)
+	default:
+		e.WriteByte(major | 27)
+		writeUint(&e.Buffer, n, 8)
+	}
+}
+
+// writeUint writes the value big-endian in size bytes.
+func writeUint(b *bytes.Buffer, v uint64, size int) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	b.Write(buf[8-size:])
+}
@@ -0,0 +1,274 @@
+// Copyright © 2020 Emando B.V.
+
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMessagePack(t *testing.T) {
+	for _, tc := range []struct {
+		json     string
+		expected string
+	}{
+		{json: `null`, expected: "c0"},
+		{json: `true`, expected: "c3"},
+		{json: `false`, expected: "c2"},
+		{json: `0`, expected: "00"},
+		{json: `127`, expected: "7f"},
+		{json: `128`, expected: "cc80"},
+		{json: `256`, expected: "cd0100"},
+		{json: `65536`, expected: "ce00010000"},
+		{json: `4294967296`, expected: "cf0000000100000000"},
+		{json: `-1`, expected: "ff"},
+		{json: `-32`, expected: "e0"},
+		{json: `-33`, expected: "d0df"},
+		{json: `-129`, expected: "d1ff7f"},
+		{json: `-32769`, expected: "d2ffff7fff"},
+		{json: `-2147483649`, expected: "d3ffffffff7fffffff"},
+		{json: `1.5`, expected: "cb3ff8000000000000"},
+		{json: `""`, expected: "a0"},
+		{json: `"a"`, expected: "a161"},
+		{json: `"` + strings.Repeat("a", 32) + `"`, expected: "d920" + strings.Repeat("61", 32)},
+		{json: `[1,[2]]`, expected: "92019102"},
+		{json: `{"b":1,"a":2}`, expected: "82a16102a16201"},
+	} {
+		t.Run(tc.json, func(t *testing.T) {
+			res, err := MessagePack([]byte(tc.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h := hex.EncodeToString(res); h != tc.expected {
+				t.Errorf("encoded is %s, want %s", h, tc.expected)
+			}
+		})
+	}
+}
+
+// The expected encodings are from RFC 7049 appendix A.
+func TestCBOR(t *testing.T) {
+	for _, tc := range []struct {
+		json     string
+		expected string
+	}{
+		{json: `null`, expected: "f6"},
+		{json: `true`, expected: "f5"},
+		{json: `false`, expected: "f4"},
+		{json: `0`, expected: "00"},
+		{json: `23`, expected: "17"},
+		{json: `24`, expected: "1818"},
+		{json: `1000`, expected: "1903e8"},
+		{json: `1000000`, expected: "1a000f4240"},
+		{json: `1000000000000`, expected: "1b000000e8d4a51000"},
+		{json: `-1`, expected: "20"},
+		{json: `-1000`, expected: "3903e7"},
+		{json: `1.1`, expected: "fb3ff199999999999a"},
+		{json: `""`, expected: "60"},
+		{json: `"IETF"`, expected: "6449455446"},
+		{json: `[1,[2,3]]`, expected: "8201820203"},
+		{json: `{"b":[2,3],"a":1}`, expected: "a26161016162820203"},
+	} {
+		t.Run(tc.json, func(t *testing.T) {
+			res, err := CBOR([]byte(tc.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h := hex.EncodeToString(res); h != tc.expected {
+				t.Errorf("encoded is %s, want %s", h, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	event := `{"typeName":"RaceLapAddedEvent","competitionId":"c1","distanceId":"d1","heat":{"round":1,"number":2},` +
+		`"lap":{"raceId":"r1","time":352800000,"flags":0,"fixedIndex":null,"passed":-12.5,"valid":true},` +
+		`"laps":[1,-100000,70000,4294967296],"name":"` + strings.Repeat("long ", 20000) + `"}`
+	var expected interface{}
+	if err := json.Unmarshal([]byte(event), &expected); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name   string
+		encode func([]byte) ([]byte, error)
+		decode func(*bytes.Reader) (interface{}, error)
+	}{
+		{name: "MessagePack", encode: MessagePack, decode: decodeMessagePack},
+		{name: "CBOR", encode: CBOR, decode: decodeCBOR},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf, err := tc.encode([]byte(event))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := bytes.NewReader(buf)
+			res, err := tc.decode(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Len() != 0 {
+				t.Errorf("%d bytes left after decoding", r.Len())
+			}
+			if !reflect.DeepEqual(res, expected) {
+				t.Error("decoded event differs from the JSON event")
+			}
+			if _, err := tc.encode([]byte(`{`)); err == nil {
+				t.Error("invalid JSON is encoded")
+			}
+		})
+	}
+}
+
+// readUint reads a big-endian value of size bytes.
+func readUint(r *bytes.Reader, size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := r.Read(buf[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+func readString(r *bytes.Reader, n uint64) (string, error) {
+	buf := make([]byte, n)
+	if _, err := r.Read(buf); err != nil && n > 0 {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// decodeMessagePack decodes the MessagePack types that MessagePack encodes, with numbers as float64 like
+// encoding/json.
+func decodeMessagePack(r *bytes.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	var n uint64
+	switch {
+	case b == 0xc0:
+		return nil, nil
+	case b == 0xc2, b == 0xc3:
+		return b == 0xc3, nil
+	case b < 0x80:
+		return float64(b), nil
+	case b >= 0xe0:
+		return float64(int8(b)), nil
+	case b >= 0xcc && b <= 0xcf:
+		v, err := readUint(r, 1<<(b-0xcc))
+		return float64(v), err
+	case b >= 0xd0 && b <= 0xd3:
+		size := 1 << (b - 0xd0)
+		v, err := readUint(r, size)
+		shift := 64 - 8*uint(size)
+		return float64(int64(v<<shift) >> shift), err
+	case b == 0xcb:
+		v, err := readUint(r, 8)
+		return math.Float64frombits(v), err
+	case b&0xe0 == 0xa0:
+		return readString(r, uint64(b&0x1f))
+	case b >= 0xd9 && b <= 0xdb:
+		if n, err = readUint(r, 1<<(b-0xd9)); err != nil {
+			return nil, err
+		}
+		return readString(r, n)
+	case b&0xf0 == 0x90, b == 0xdc, b == 0xdd:
+		if n, err = msgpackLength(r, b, 0x90); err != nil {
+			return nil, err
+		}
+		res := make([]interface{}, n)
+		for i := range res {
+			if res[i], err = decodeMessagePack(r); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case b&0xf0 == 0x80, b == 0xde, b == 0xdf:
+		if n, err = msgpackLength(r, b, 0x80); err != nil {
+			return nil, err
+		}
+		return decodeMap(r, n, decodeMessagePack)
+	}
+	return nil, fmt.Errorf("unexpected type %#x", b)
+}
+
+// msgpackLength returns the length of the array or map of the type, which is fix if it has the fix prefix.
+func msgpackLength(r *bytes.Reader, b, fix byte) (uint64, error) {
+	switch {
+	case b&0xf0 == fix:
+		return uint64(b & 0x0f), nil
+	case b == 0xdc, b == 0xde:
+		return readUint(r, 2)
+	default:
+		return readUint(r, 4)
+	}
+}
+
+// decodeCBOR decodes the CBOR types that CBOR encodes, with numbers as float64 like encoding/json.
+func decodeCBOR(r *bytes.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch b {
+	case 0xf4, 0xf5:
+		return b == 0xf5, nil
+	case 0xf6:
+		return nil, nil
+	case 0xfb:
+		v, err := readUint(r, 8)
+		return math.Float64frombits(v), err
+	}
+	n := uint64(b & 0x1f)
+	if n >= 24 {
+		if n, err = readUint(r, 1<<(n-24)); err != nil {
+			return nil, err
+		}
+	}
+	switch b >> 5 {
+	case cborUint:
+		return float64(n), nil
+	case cborNegint:
+		return -1 - float64(n), nil
+	case cborText:
+		return readString(r, n)
+	case cborArray:
+		res := make([]interface{}, n)
+		for i := range res {
+			if res[i], err = decodeCBOR(r); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case cborMap:
+		return decodeMap(r, n, decodeCBOR)
+	}
+	return nil, fmt.Errorf("unexpected type %#x", b)
+}
+
+func decodeMap(r *bytes.Reader, n uint64, decode func(*bytes.Reader) (interface{}, error)) (interface{}, error) {
+	res := make(map[string]interface{}, n)
+	var last string
+	for i := uint64(0); i < n; i++ {
+		k, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok || (i > 0 && key <= last) {
+			return nil, fmt.Errorf("unexpected key %v", k)
+		}
+		last = key
+		if res[key], err = decode(r); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
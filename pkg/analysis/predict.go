@@ -0,0 +1,180 @@
+// Copyright © 2020 Emando B.V.
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/results"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// FinishPredictedType is the event name of a derived finish time prediction of a race.
+const FinishPredictedType = "RaceFinishPredictedEvent"
+
+// FinishPredicted is the event data of a derived finish time prediction of a race at a presented lap.
+type FinishPredicted struct {
+	events.Race
+	Prediction Prediction `json:"prediction"`
+}
+
+// Prediction is a projected final time and ranking of a race.
+type Prediction struct {
+	Model        Model          `json:"model"`
+	PassedLength float64        `json:"passedLength"`
+	Time         entities.Ticks `json:"time"`
+	FinalTime    entities.Ticks `json:"finalTime"`
+	// Ranking is the projected ranking among the committed races in the distance.
+	Ranking int `json:"ranking"`
+}
+
+// Model is a finish time prediction model.
+type Model string
+
+// Prediction models.
+const (
+	// Linear extrapolates the average speed.
+	Linear Model = "linear"
+	// Estimate adds the difference to the estimated time to the estimated final time. This falls back to Linear if
+	// there is no estimate.
+	Estimate Model = "estimate"
+	// Fade extrapolates the last lap time, slowing down each lap by the ratio of the last two lap times.
+	Fade Model = "fade"
+)
+
+// maxFade is the maximum slowdown per lap of the Fade model.
+const maxFade = 1.1
+
+// ParseModel parses the model. The default model is Estimate.
+func ParseModel(s string) (Model, error) {
+	switch m := Model(s); m {
+	case "":
+		return Estimate, nil
+	case Linear, Estimate, Fade:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid prediction model %q", s)
+	}
+}
+
+// length returns the length of the distance in meters, or 0 if unknown.
+func length(d *state.Distance, r *state.Race) float64 {
+	if d.ValueQuantity == 0 && d.Value > 0 {
+		return float64(d.Value)
+	}
+	if n := len(r.EstimatedLaps); n > 0 {
+		return r.EstimatedLaps[n-1].PassedLength
+	}
+	return 0
+}
+
+// Predict returns the projected final time of the race. This function returns false if there is no prediction.
+func Predict(model Model, d *state.Distance, r *state.Race) (entities.Ticks, bool) {
+	n := len(r.PresentedLaps)
+	total := length(d, r)
+	if n == 0 || total <= 0 {
+		return 0, false
+	}
+	last := r.PresentedLaps[n-1]
+	if last.PassedLength <= 0 {
+		return 0, false
+	}
+	if last.PassedLength >= total-state.LengthTolerance {
+		return last.Time, true
+	}
+	remaining := total - last.PassedLength
+	switch model {
+	case Estimate:
+		estimate, ok := state.TimeAt(r.EstimatedLaps, last.PassedLength)
+		final, finalOK := state.TimeAt(r.EstimatedLaps, total)
+		if ok && finalOK {
+			return final + last.Time - estimate, true
+		}
+	case Fade:
+		if n < 2 {
+			break
+		}
+		prev := r.PresentedLaps[n-2]
+		lapLength := last.PassedLength - prev.PassedLength
+		if lapLength <= 0 || last.LapTime <= 0 || prev.LapTime <= 0 {
+			break
+		}
+		ratio := float64(last.LapTime) / float64(prev.LapTime)
+		if ratio < 1 {
+			ratio = 1
+		} else if ratio > maxFade {
+			ratio = maxFade
+		}
+		t, lap := float64(last.Time), float64(last.LapTime)
+		for ; remaining > state.LengthTolerance; remaining -= lapLength {
+			lap *= ratio
+			if remaining < lapLength {
+				t += lap * remaining / lapLength
+				break
+			}
+			t += lap
+		}
+		return entities.Ticks(t), true
+	}
+	return entities.Ticks(float64(last.Time) * total / last.PassedLength), true
+}
+
+// rankAmongCommitted returns the ranking of the time among the final times of the committed races in the distance.
+func rankAmongCommitted(d *state.Distance, t entities.Ticks) int {
+	t = results.Truncate(t, d.ClassificationPrecision)
+	ranking := 1
+	for _, h := range d.Heats {
+		if !h.Committed {
+			continue
+		}
+		for _, r := range h.Races {
+			if r.Time != nil && r.Time.Time > 0 && results.Truncate(r.Time.Time, d.ClassificationPrecision) < t {
+				ranking++
+			}
+		}
+	}
+	return ranking
+}
+
+// FinishPredictor returns a function that returns the JSON encoded finish time prediction of the JSON encoded event,
+// or nil if the event is not a presented lap change. The event must be applied to the store first.
+func FinishPredictor(model Model) func(*state.Store, []byte) ([]byte, error) {
+	return func(store *state.Store, buf []byte) ([]byte, error) {
+		var event events.LastPresentedRaceLapChanged
+		if err := json.Unmarshal(buf, &event); err != nil {
+			return nil, err
+		}
+		if event.TypeName() != events.LastPresentedRaceLapChangedType {
+			return nil, nil
+		}
+		var res *FinishPredicted
+		store.Heat(event.CompetitionID, event.DistanceID, state.KeyOf(event.Heat.Heat), func(_ *state.Competition, d *state.Distance, h *state.Heat) {
+			r := h.Race(event.RaceID)
+			if r == nil {
+				return
+			}
+			final, ok := Predict(model, d, r)
+			if !ok {
+				return
+			}
+			res = &FinishPredicted{
+				Race: event.Race,
+				Prediction: Prediction{
+					Model:        model,
+					PassedLength: event.Lap.PassedLength,
+					Time:         event.Lap.Time,
+					FinalTime:    final,
+					Ranking:      rankAmongCommitted(d, final),
+				},
+			}
+			res.Type = FinishPredictedType
+		})
+		if res == nil {
+			return nil, nil
+		}
+		return json.Marshal(res)
+	}
+}
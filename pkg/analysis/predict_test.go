@@ -0,0 +1,71 @@
+// Copyright © 2020 Emando B.V.
+
+package analysis
+
+import (
+	"testing"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+func TestPredict(t *testing.T) {
+	laps := func(laps ...[3]entities.Ticks) []entities.PresentedLap {
+		res := make([]entities.PresentedLap, 0, len(laps))
+		for i, l := range laps {
+			res = append(res, entities.PresentedLap{Index: i, PassedLength: float64(l[0]), Time: l[1], LapTime: l[2]})
+		}
+		return res
+	}
+	for _, tc := range []struct {
+		name      string
+		model     Model
+		presented []entities.PresentedLap
+		estimated []entities.PresentedLap
+		expected  entities.Ticks
+		ok        bool
+	}{
+		{name: "NoLaps", model: Linear},
+		{name: "Linear", model: Linear, presented: laps([3]entities.Ticks{200, 200, 200}, [3]entities.Ticks{600, 590, 390}), expected: 983, ok: true},
+		{name: "Finished", model: Linear, presented: laps([3]entities.Ticks{1000, 1001, 400}), expected: 1001, ok: true},
+		{
+			name:      "Estimate",
+			model:     Estimate,
+			presented: laps([3]entities.Ticks{200, 200, 200}, [3]entities.Ticks{600, 590, 390}),
+			estimated: laps([3]entities.Ticks{200, 190, 190}, [3]entities.Ticks{600, 580, 390}, [3]entities.Ticks{1000, 980, 400}),
+			expected:  990,
+			ok:        true,
+		},
+		{name: "EstimateWithoutEstimate", model: Estimate, presented: laps([3]entities.Ticks{200, 200, 200}, [3]entities.Ticks{600, 590, 390}), expected: 983, ok: true},
+		{name: "Fade", model: Fade, presented: laps([3]entities.Ticks{200, 200, 200}, [3]entities.Ticks{600, 600, 400}), expected: 1040, ok: true},
+		{name: "FadeFaster", model: Fade, presented: laps([3]entities.Ticks{200, 400, 400}, [3]entities.Ticks{600, 780, 380}), expected: 1160, ok: true},
+		{name: "FadeOneLap", model: Fade, presented: laps([3]entities.Ticks{200, 200, 200}), expected: 1000, ok: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &state.Distance{Distance: entities.Distance{Value: 1000}}
+			r := &state.Race{PresentedLaps: tc.presented, EstimatedLaps: tc.estimated}
+			res, ok := Predict(tc.model, d, r)
+			if ok != tc.ok || res != tc.expected {
+				t.Errorf("prediction is %d (%v), want %d (%v)", res, ok, tc.expected, tc.ok)
+			}
+		})
+	}
+}
+
+func TestParseModel(t *testing.T) {
+	for _, tc := range []struct {
+		s        string
+		expected Model
+		err      bool
+	}{
+		{s: "", expected: Estimate},
+		{s: "linear", expected: Linear},
+		{s: "fade", expected: Fade},
+		{s: "magic", err: true},
+	} {
+		m, err := ParseModel(tc.s)
+		if (err != nil) != tc.err || m != tc.expected {
+			t.Errorf("model of %q is %q (%v), want %q", tc.s, m, err, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright © 2020 Emando B.V.
+
+// Package analysis derives analyses of races from the competition state.
+package analysis
+
+import (
+	"encoding/json"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// SplitAnalyzedType is the event name of a derived split analysis of a race.
+const SplitAnalyzedType = "RaceSplitAnalyzedEvent"
+
+// SplitAnalyzed is the event data of a derived split analysis of a race at a presented lap.
+type SplitAnalyzed struct {
+	events.Race
+	Split Split `json:"split"`
+}
+
+// Split is the analysis of a race at a presented lap.
+type Split struct {
+	Index        int            `json:"index"`
+	PassedLength float64        `json:"passedLength"`
+	Time         entities.Ticks `json:"time"`
+	LapTime      entities.Ticks `json:"lapTime"`
+	Ranking      *int           `json:"ranking"`
+	// Estimate is the estimated time at the passed length and EstimateDifference the difference to it.
+	Estimate           *entities.Ticks `json:"estimate"`
+	EstimateDifference *entities.Ticks `json:"estimateDifference"`
+	// OpponentRaceID is the race of the paired opponent in the heat and OpponentDifference the difference to the time
+	// of the opponent at the passed length, if the opponent passed it.
+	OpponentRaceID     string          `json:"opponentRaceId,omitempty"`
+	OpponentDifference *entities.Ticks `json:"opponentDifference"`
+	// LeaderRaceID is the race with the best time of other races in the distance at the passed length and
+	// LeaderDifference the difference to it.
+	LeaderRaceID     string          `json:"leaderRaceId,omitempty"`
+	LeaderDifference *entities.Ticks `json:"leaderDifference"`
+}
+
+func diff(a, b entities.Ticks) *entities.Ticks {
+	d := a - b
+	return &d
+}
+
+// SplitOf returns the analysis of the race in the heat at the presented lap.
+func SplitOf(d *state.Distance, h *state.Heat, r *state.Race, lap entities.PresentedLap) Split {
+	s := Split{
+		Index:        lap.Index,
+		PassedLength: lap.PassedLength,
+		Time:         lap.Time,
+		LapTime:      lap.LapTime,
+		Ranking:      lap.Ranking,
+	}
+	if t, ok := state.TimeAt(r.EstimatedLaps, lap.PassedLength); ok {
+		s.Estimate = &t
+		s.EstimateDifference = diff(lap.Time, t)
+	}
+	for _, o := range h.Races {
+		if o.ID == r.ID {
+			continue
+		}
+		s.OpponentRaceID = o.ID
+		if t, ok := state.TimeAt(o.PresentedLaps, lap.PassedLength); ok {
+			s.OpponentDifference = diff(lap.Time, t)
+		}
+		break
+	}
+	var leader *entities.Ticks
+	for _, oh := range d.Heats {
+		for _, o := range oh.Races {
+			if o.ID == r.ID {
+				continue
+			}
+			if t, ok := state.TimeAt(o.PresentedLaps, lap.PassedLength); ok && (leader == nil || t < *leader) {
+				t := t
+				leader = &t
+				s.LeaderRaceID = o.ID
+			}
+		}
+	}
+	if leader != nil {
+		s.LeaderDifference = diff(lap.Time, *leader)
+	}
+	return s
+}
+
+// Splits returns the analyses of the race at each presented lap.
+func Splits(d *state.Distance, h *state.Heat, r *state.Race) []Split {
+	res := make([]Split, 0, len(r.PresentedLaps))
+	for _, lap := range r.PresentedLaps {
+		res = append(res, SplitOf(d, h, r, lap))
+	}
+	return res
+}
+
+// DeriveSplit returns the JSON encoded split analysis of the JSON encoded event, or nil if the event is not a
+// presented lap change. The event must be applied to the store first.
+func DeriveSplit(store *state.Store, buf []byte) ([]byte, error) {
+	var event events.LastPresentedRaceLapChanged
+	if err := json.Unmarshal(buf, &event); err != nil {
+		return nil, err
+	}
+	if event.TypeName() != events.LastPresentedRaceLapChangedType {
+		return nil, nil
+	}
+	var res *SplitAnalyzed
+	store.Heat(event.CompetitionID, event.DistanceID, state.KeyOf(event.Heat.Heat), func(_ *state.Competition, d *state.Distance, h *state.Heat) {
+		r := h.Race(event.RaceID)
+		if r == nil {
+			return
+		}
+		res = &SplitAnalyzed{
+			Race:  event.Race,
+			Split: SplitOf(d, h, r, event.Lap),
+		}
+		res.Type = SplitAnalyzedType
+	})
+	if res == nil {
+		return nil, nil
+	}
+	return json.Marshal(res)
+}
@@ -0,0 +1,155 @@
+// Copyright © 2020 Emando B.V.
+
+package analysis
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// TeamAnalyzedType is the event name of a derived analysis of a team race.
+const TeamAnalyzedType = "RaceTeamAnalyzedEvent"
+
+// TeamAnalyzed is the event data of a derived analysis of a team race at a passing.
+type TeamAnalyzed struct {
+	events.Race
+	Team Team `json:"team"`
+}
+
+// teamTimeMember is the member that determines the team time, i.e. the third skater in a team pursuit.
+const teamTimeMember = 3
+
+// Team is the analysis of a team race.
+type Team struct {
+	Members []Member `json:"members"`
+	// PassedLength is the last length passed by the team, and Time the team time at that length.
+	PassedLength float64         `json:"passedLength"`
+	Time         *entities.Ticks `json:"time"`
+	// Passings is the number of passings registered at the passed length. Vantage registers one passing per length
+	// for the team, or one per member.
+	Passings int `json:"passings"`
+	// Gaps are the differences of the passings at the passed length to the first passing, in order of passing, if
+	// more than one passing is registered at the length.
+	Gaps []entities.Ticks `json:"gaps,omitempty"`
+}
+
+// Member is a member of a team race.
+type Member struct {
+	PersonID string `json:"personId"`
+	Name     string `json:"name,omitempty"`
+	// Order is the order of the member in the team, or the transponder set if the team has no members.
+	Order        int     `json:"order"`
+	Transponders []int64 `json:"transponders"`
+}
+
+// Members returns the composition of the team from the members of the competitor and the persons of the transponders
+// of the race, ordered by member order or transponder set.
+func Members(r *state.Race) []Member {
+	var res []Member
+	index := make(map[string]int)
+	for _, m := range r.Competitor.Members {
+		if m.Member.PersonID == "" {
+			continue
+		}
+		index[m.Member.PersonID] = len(res)
+		res = append(res, Member{
+			PersonID:     m.Member.PersonID,
+			Name:         m.Member.FullName,
+			Order:        m.Order,
+			Transponders: []int64{},
+		})
+	}
+	ordered := len(res) > 0
+	for _, t := range r.Transponders {
+		if t.PersonID == "" {
+			continue
+		}
+		i, ok := index[t.PersonID]
+		if !ok {
+			i = len(res)
+			index[t.PersonID] = i
+			res = append(res, Member{
+				PersonID:     t.PersonID,
+				Transponders: []int64{},
+			})
+			if !ordered {
+				res[i].Order = t.Set
+			}
+		}
+		res[i].Transponders = append(res[i].Transponders, t.Code)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Order < res[j].Order
+	})
+	return res
+}
+
+// TeamOf returns the analysis of the team race. The team time at a length is the time of the passing of the member
+// that determines the team time if a passing is registered per member, or of the last passing otherwise.
+func TeamOf(r *state.Race) Team {
+	team := Team{Members: Members(r)}
+	// times are the times of the passings at each passed length.
+	times := make(map[float64][]entities.Ticks)
+	for _, p := range r.Passings {
+		if p.Passed == nil {
+			continue
+		}
+		length := math.Round(*p.Passed/state.LengthTolerance) * state.LengthTolerance
+		times[length] = append(times[length], p.Time)
+		if length > team.PassedLength {
+			team.PassedLength = length
+		}
+	}
+	passed := times[team.PassedLength]
+	if len(passed) == 0 {
+		return team
+	}
+	sort.Slice(passed, func(i, j int) bool {
+		return passed[i] < passed[j]
+	})
+	team.Passings = len(passed)
+	t := passed[len(passed)-1]
+	if len(passed) >= teamTimeMember {
+		t = passed[teamTimeMember-1]
+	}
+	team.Time = &t
+	if len(passed) > 1 {
+		for _, p := range passed {
+			team.Gaps = append(team.Gaps, p-passed[0])
+		}
+	}
+	return team
+}
+
+// DeriveTeam returns the JSON encoded team analysis of the JSON encoded event, or nil if the event is not a passing of
+// a team race. The event must be applied to the store first.
+func DeriveTeam(store *state.Store, buf []byte) ([]byte, error) {
+	var event events.RacePassingAdded
+	if err := json.Unmarshal(buf, &event); err != nil {
+		return nil, err
+	}
+	if event.TypeName() != events.RacePassingAddedType || event.Passing.Passed == nil {
+		return nil, nil
+	}
+	var res *TeamAnalyzed
+	store.Heat(event.CompetitionID, event.DistanceID, state.KeyOf(event.Heat.Heat), func(_ *state.Competition, _ *state.Distance, h *state.Heat) {
+		r := h.Race(event.RaceID)
+		if r == nil || r.Competitor.Type != entities.TeamCompetitorType {
+			return
+		}
+		res = &TeamAnalyzed{
+			Race: event.Race,
+			Team: TeamOf(r),
+		}
+		res.Type = TeamAnalyzedType
+	})
+	if res == nil {
+		return nil, nil
+	}
+	return json.Marshal(res)
+}
@@ -0,0 +1,182 @@
+// Copyright © 2020 Emando B.V.
+
+package analysis
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// replay applies the events of the example file to a new store and calls f after each event.
+func replay(t *testing.T, name string, f func(store *state.Store, buf []byte)) {
+	t.Helper()
+	file, err := os.Open("../../examples/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	store := state.NewStore()
+	s := bufio.NewScanner(file)
+	s.Buffer(nil, 1<<22)
+	for s.Scan() {
+		buf := append([]byte(nil), s.Bytes()...)
+		if err := store.Apply(buf); err != nil {
+			t.Fatal(err)
+		}
+		f(store, buf)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeriveTeam(t *testing.T) {
+	var last map[string]TeamAnalyzed
+	var derived int
+	replay(t, "20200112-ec-single-distances-recover-10-3.json", func(store *state.Store, buf []byte) {
+		res, err := DeriveTeam(store, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res == nil {
+			return
+		}
+		var event TeamAnalyzed
+		if err := json.Unmarshal(res, &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.TypeName() != TeamAnalyzedType {
+			t.Fatalf("type name is %q", event.TypeName())
+		}
+		if last == nil {
+			last = make(map[string]TeamAnalyzed)
+		}
+		if prev, ok := last[event.RaceID]; ok && event.Team.PassedLength < prev.Team.PassedLength {
+			t.Errorf("passed length of race %s decreased from %g to %g", event.RaceID, prev.Team.PassedLength,
+				event.Team.PassedLength)
+		}
+		last[event.RaceID] = event
+		derived++
+	})
+	if derived == 0 {
+		t.Fatal("no team analysis derived")
+	}
+	if len(last) != 2 {
+		t.Fatalf("analyzed %d races, expected 2", len(last))
+	}
+	for id, event := range last {
+		team := event.Team
+		if len(team.Members) != 3 {
+			t.Errorf("race %s has %d members, expected 3", id, len(team.Members))
+		}
+		for _, m := range team.Members {
+			if m.PersonID == "" || len(m.Transponders) != 2 {
+				t.Errorf("race %s has member %+v, expected a person with 2 transponders", id, m)
+			}
+		}
+		if team.Time == nil || *team.Time <= 0 {
+			t.Errorf("race %s has no team time", id)
+		}
+		if team.PassedLength < 3000 {
+			t.Errorf("race %s passed %gm, expected at least 3000m", id, team.PassedLength)
+		}
+	}
+}
+
+func TestTeamOf(t *testing.T) {
+	passed := func(length float64) *float64 { return &length }
+	race := func(passings ...entities.Passing) *state.Race {
+		r := &state.Race{}
+		r.Competitor.Type = entities.TeamCompetitorType
+		r.Transponders = []entities.Transponder{
+			{Code: 3, PersonID: "c", Set: 3},
+			{Code: 1, PersonID: "a", Set: 1},
+			{Code: 2, PersonID: "b", Set: 2},
+			{Code: 4, PersonID: "a", Set: 1},
+		}
+		r.Passings = passings
+		return r
+	}
+	for _, tc := range []struct {
+		name     string
+		race     *state.Race
+		length   float64
+		time     entities.Ticks
+		passings int
+		gaps     []entities.Ticks
+	}{
+		{
+			name: "NoPassings",
+			race: race(),
+		},
+		{
+			name: "TeamPassings",
+			race: race(
+				entities.Passing{Passed: passed(100), Time: 100},
+				entities.Passing{Passed: passed(200), Time: 200},
+			),
+			length:   200,
+			time:     200,
+			passings: 1,
+		},
+		{
+			name: "MemberPassings",
+			race: race(
+				entities.Passing{Passed: passed(100), Time: 102},
+				entities.Passing{Passed: passed(100), Time: 100},
+				entities.Passing{Passed: passed(100), Time: 105},
+				entities.Passing{Passed: passed(100), Time: 101},
+			),
+			length:   100,
+			time:     102,
+			passings: 4,
+			gaps:     []entities.Ticks{0, 1, 2, 5},
+		},
+		{
+			name: "PartialMemberPassings",
+			race: race(
+				entities.Passing{Passed: passed(100), Time: 100},
+				entities.Passing{Passed: passed(200), Time: 200},
+				entities.Passing{Passed: passed(200), Time: 203},
+			),
+			length:   200,
+			time:     203,
+			passings: 2,
+			gaps:     []entities.Ticks{0, 3},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			team := TeamOf(tc.race)
+			var ids []string
+			for _, m := range team.Members {
+				ids = append(ids, m.PersonID)
+			}
+			if len(ids) != 3 || ids[0] != "a" || ids[1] != "b" || ids[2] != "c" {
+				t.Errorf("members are %v, expected [a b c]", ids)
+			}
+			if team.PassedLength != tc.length || team.Passings != tc.passings {
+				t.Errorf("passed %gm with %d passings, expected %gm with %d passings", team.PassedLength,
+					team.Passings, tc.length, tc.passings)
+			}
+			switch {
+			case tc.time == 0 && team.Time != nil:
+				t.Errorf("time is %d, expected none", *team.Time)
+			case tc.time != 0 && (team.Time == nil || *team.Time != tc.time):
+				t.Errorf("time is %v, expected %d", team.Time, tc.time)
+			}
+			if len(team.Gaps) != len(tc.gaps) {
+				t.Fatalf("gaps are %v, expected %v", team.Gaps, tc.gaps)
+			}
+			for i := range tc.gaps {
+				if team.Gaps[i] != tc.gaps[i] {
+					t.Errorf("gaps are %v, expected %v", team.Gaps, tc.gaps)
+				}
+			}
+		})
+	}
+}
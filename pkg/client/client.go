@@ -0,0 +1,389 @@
+// Copyright © 2020 Emando B.V.
+
+// Package client is a client of the Vantage Events Server websocket hub.
+//
+// The client reconnects with jittered exponential backoff when the connection drops. The hub replays the events of
+// a competition on each connection; the client rebuilds the competition state from the replay and delivers events
+// that were already delivered before the reconnect only once.
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	writeWait = 10 * time.Second
+	pingWait  = 90 * time.Second
+
+	defaultWindow = 100000
+)
+
+// CompetitionsURL returns the URL of the stream of competition activations of the host.
+func CompetitionsURL(host string) string {
+	return fmt.Sprintf("wss://%s/v1/competitions", host)
+}
+
+// CompetitionURL returns the URL of the stream of events of the competition on the host.
+func CompetitionURL(host, id string) string {
+	return fmt.Sprintf("wss://%s/v1/competitions/%s", host, id)
+}
+
+// Client is a hub client.
+// Callbacks and subscriptions must be registered before calling Run. Callbacks are called sequentially in the order
+// of the events.
+type Client struct {
+	url        string
+	logger     *zap.Logger
+	dialer     *websocket.Dialer
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	store      *state.Store
+	window     int
+
+	// seen contains the digests of the events of the connection, and previous those of the previous connection, so
+	// that events that are replayed after reconnecting are delivered once.
+	seen,
+	previous *digests
+	handlers      map[string][]func(Event)
+	all           []func(Event)
+	raw           []func([]byte)
+	subscriptions []*subscription
+	connected     []func()
+	disconnected  []func(error)
+}
+
+type subscription struct {
+	types []string
+	ch    chan Event
+}
+
+// Option configures the Client.
+type Option func(*Client)
+
+// WithLogger configures the logger of the client.
+func WithLogger(logger *zap.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithDialer configures the websocket dialer of the client.
+func WithDialer(dialer *websocket.Dialer) Option {
+	return func(c *Client) {
+		c.dialer = dialer
+	}
+}
+
+// WithBackoff configures the minimum and maximum backoff between reconnects.
+func WithBackoff(min, max time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff, c.maxBackoff = min, max
+	}
+}
+
+// WithStore configures the client to reconstruct the state of the competitions in the store.
+func WithStore(store *state.Store) Option {
+	return func(c *Client) {
+		c.store = store
+	}
+}
+
+// WithWindow configures the number of events of a connection that are remembered, so that they are not delivered
+// again when the hub replays them after reconnecting. The default is 100000.
+func WithWindow(n int) Option {
+	return func(c *Client) {
+		c.window = n
+	}
+}
+
+// New returns a new Client that connects to the URL. See CompetitionsURL and CompetitionURL.
+func New(url string, opts ...Option) *Client {
+	c := &Client{
+		url:        url,
+		logger:     zap.NewNop(),
+		dialer:     websocket.DefaultDialer,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+		store:      state.NewStore(),
+		window:     defaultWindow,
+		handlers:   make(map[string][]func(Event)),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.seen, c.previous = newDigests(c.window), newDigests(0)
+	return c
+}
+
+// Store returns the state of the competitions, as reconstructed from the received events.
+func (c *Client) Store() *state.Store {
+	return c.store
+}
+
+// On registers a callback for events of the given type name.
+func (c *Client) On(typeName string, f func(Event)) {
+	c.handlers[typeName] = append(c.handlers[typeName], f)
+}
+
+// OnEvent registers a callback for all events.
+func (c *Client) OnEvent(f func(Event)) {
+	c.all = append(c.all, f)
+}
+
+// OnRaw registers a callback for all JSON encoded events.
+func (c *Client) OnRaw(f func([]byte)) {
+	c.raw = append(c.raw, f)
+}
+
+// OnConnected registers a callback that is called when the client is connected.
+func (c *Client) OnConnected(f func()) {
+	c.connected = append(c.connected, f)
+}
+
+// OnDisconnected registers a callback that is called when the client is disconnected.
+func (c *Client) OnDisconnected(f func(error)) {
+	c.disconnected = append(c.disconnected, f)
+}
+
+// OnCompetitionActivated registers a callback for competition activations.
+func (c *Client) OnCompetitionActivated(f func(*events.CompetitionActivated)) {
+	c.On(events.CompetitionActivatedType, func(e Event) { f(e.(*events.CompetitionActivated)) })
+}
+
+// OnDistanceActivated registers a callback for distance activations.
+func (c *Client) OnDistanceActivated(f func(*events.DistanceActivated)) {
+	c.On(events.DistanceActivatedType, func(e Event) { f(e.(*events.DistanceActivated)) })
+}
+
+// OnDistanceDeactivated registers a callback for distance deactivations.
+func (c *Client) OnDistanceDeactivated(f func(*events.Distance)) {
+	c.On(events.DistanceDeactivatedType, func(e Event) { f(e.(*events.Distance)) })
+}
+
+// OnHeatActivated registers a callback for heat activations.
+func (c *Client) OnHeatActivated(f func(*events.HeatActivated)) {
+	c.On(events.HeatActivatedType, func(e Event) { f(e.(*events.HeatActivated)) })
+}
+
+// OnHeatDeactivated registers a callback for heat deactivations.
+func (c *Client) OnHeatDeactivated(f func(*events.Heat)) {
+	c.On(events.HeatDeactivatedType, func(e Event) { f(e.(*events.Heat)) })
+}
+
+// OnHeatStarted registers a callback for heat starts.
+func (c *Client) OnHeatStarted(f func(*events.HeatStarted)) {
+	c.On(events.HeatStartedType, func(e Event) { f(e.(*events.HeatStarted)) })
+}
+
+// OnHeatCommitted registers a callback for heat commits.
+func (c *Client) OnHeatCommitted(f func(*events.HeatCommitted)) {
+	c.On(events.HeatCommittedType, func(e Event) { f(e.(*events.HeatCommitted)) })
+}
+
+// OnRaceLapAdded registers a callback for race lap registrations.
+func (c *Client) OnRaceLapAdded(f func(*events.RaceLapAdded)) {
+	c.On(events.RaceLapAddedType, func(e Event) { f(e.(*events.RaceLapAdded)) })
+}
+
+// OnLastPresentedRaceLapChanged registers a callback for race presented lap changes.
+func (c *Client) OnLastPresentedRaceLapChanged(f func(*events.LastPresentedRaceLapChanged)) {
+	c.On(events.LastPresentedRaceLapChangedType, func(e Event) { f(e.(*events.LastPresentedRaceLapChanged)) })
+}
+
+// OnRacePassingAdded registers a callback for race passing registrations.
+func (c *Client) OnRacePassingAdded(f func(*events.RacePassingAdded)) {
+	c.On(events.RacePassingAddedType, func(e Event) { f(e.(*events.RacePassingAdded)) })
+}
+
+// OnLastRaceSpeedChanged registers a callback for race speed changes.
+func (c *Client) OnLastRaceSpeedChanged(f func(*events.LastRaceSpeedChanged)) {
+	c.On(events.LastRaceSpeedChangedType, func(e Event) { f(e.(*events.LastRaceSpeedChanged)) })
+}
+
+// Subscribe returns a channel with events of the given type names, or all events if no type names are given.
+// The channel is closed when Run returns. A subscriber that does not keep up blocks the client.
+func (c *Client) Subscribe(size int, types ...string) <-chan Event {
+	sub := &subscription{
+		types: types,
+		ch:    make(chan Event, size),
+	}
+	c.subscriptions = append(c.subscriptions, sub)
+	return sub.ch
+}
+
+// Run connects to the hub and handles events until the context is canceled. The client reconnects when the
+// connection drops.
+func (c *Client) Run(ctx context.Context) error {
+	defer func() {
+		for _, sub := range c.subscriptions {
+			close(sub.ch)
+		}
+	}()
+	backoff := c.minBackoff
+	for {
+		received, err := c.run(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		for _, f := range c.disconnected {
+			f(err)
+		}
+		if received {
+			backoff = c.minBackoff
+		}
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		c.logger.Warn("disconnected from hub, reconnecting",
+			zap.String("url", c.url),
+			zap.Duration("wait", wait),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		if backoff *= 2; backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// run connects once and handles events until the connection drops. This method returns whether any event was
+// received.
+func (c *Client) run(ctx context.Context) (bool, error) {
+	conn, _, err := c.dialer.DialContext(ctx, c.url, nil)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	c.logger.Info("connected to hub", zap.String("url", c.url))
+	for _, f := range c.connected {
+		f()
+	}
+	c.store.Reset()
+	c.previous, c.seen = c.seen, newDigests(c.window)
+
+	conn.SetReadDeadline(time.Now().Add(pingWait))
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(pingWait))
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeWait))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	var received bool
+	for {
+		messageType, buf, err := conn.ReadMessage()
+		if err != nil {
+			return received, err
+		}
+		if messageType != websocket.TextMessage {
+			continue
+		}
+		received = true
+		if err := c.handle(ctx, buf); err != nil {
+			c.logger.Warn("failed to handle event", zap.Error(err))
+		}
+	}
+}
+
+func (c *Client) handle(ctx context.Context, buf []byte) error {
+	event, err := Decode(buf)
+	if err != nil {
+		return err
+	}
+	if err := c.store.Apply(buf); err != nil {
+		return err
+	}
+	digest := sha256.Sum256(buf)
+	c.seen.add(digest)
+	if c.previous.contains(digest) {
+		return nil
+	}
+
+	for _, f := range c.raw {
+		f(buf)
+	}
+	for _, f := range c.all {
+		f(event)
+	}
+	for _, f := range c.handlers[event.TypeName()] {
+		f(event)
+	}
+	for _, sub := range c.subscriptions {
+		if !sub.matches(event.TypeName()) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sub.ch <- event:
+		}
+	}
+	return nil
+}
+
+func (s *subscription) matches(typeName string) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+	for _, t := range s.types {
+		if strings.EqualFold(t, typeName) {
+			return true
+		}
+	}
+	return false
+}
+
+// digests is a set of the digests of the last events, up to size.
+type digests struct {
+	size  int
+	set   map[[sha256.Size]byte]struct{}
+	order [][sha256.Size]byte
+}
+
+func newDigests(size int) *digests {
+	return &digests{
+		size: size,
+		set:  make(map[[sha256.Size]byte]struct{}),
+	}
+}
+
+// add adds the digest. The oldest digest is removed if the set is full.
+func (d *digests) add(digest [sha256.Size]byte) {
+	if _, ok := d.set[digest]; ok || d.size <= 0 {
+		return
+	}
+	if len(d.order) >= d.size {
+		delete(d.set, d.order[0])
+		d.order = d.order[1:]
+	}
+	d.set[digest] = struct{}{}
+	d.order = append(d.order, digest)
+}
+
+// contains returns whether the set contains the digest.
+func (d *digests) contains(digest [sha256.Size]byte) bool {
+	_, ok := d.set[digest]
+	return ok
+}
@@ -0,0 +1,110 @@
+// Copyright © 2020 Emando B.V.
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestDigests(t *testing.T) {
+	digest := func(s string) [sha256.Size]byte {
+		return sha256.Sum256([]byte(s))
+	}
+	for _, tc := range []struct {
+		name     string
+		size     int
+		add      []string
+		contains []string
+		missing  []string
+	}{
+		{name: "Empty", size: 0, add: []string{"a"}, missing: []string{"a"}},
+		{name: "Window", size: 2, add: []string{"a", "b", "c"}, contains: []string{"b", "c"}, missing: []string{"a"}},
+		{name: "Duplicate", size: 2, add: []string{"a", "a", "b"}, contains: []string{"a", "b"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newDigests(tc.size)
+			for _, s := range tc.add {
+				d.add(digest(s))
+			}
+			for _, s := range tc.contains {
+				if !d.contains(digest(s)) {
+					t.Errorf("%q is missing", s)
+				}
+			}
+			for _, s := range tc.missing {
+				if d.contains(digest(s)) {
+					t.Errorf("%q is not removed", s)
+				}
+			}
+			if len(d.order) > tc.size || len(d.set) != len(d.order) {
+				t.Errorf("%d digests in order and %d in set", len(d.order), len(d.set))
+			}
+		})
+	}
+}
+
+func TestClientReconnect(t *testing.T) {
+	// Each connection replays the events so far and adds an event, then drops.
+	replays := [][]string{
+		{`{"typeName":"A"}`, `{"typeName":"B"}`},
+		{`{"typeName":"A"}`, `{"typeName":"B"}`, `{"typeName":"C"}`},
+		{`{"typeName":"A"}`, `{"typeName":"B"}`, `{"typeName":"C"}`, `{"typeName":"D"}`},
+	}
+	for _, tc := range []struct {
+		name     string
+		window   int
+		expected string
+	}{
+		{name: "Default", window: defaultWindow, expected: "A B C D"},
+		// Events that are not remembered in the window are delivered again.
+		{name: "SmallWindow", window: 2, expected: "A B C A D"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var connections int32
+			upgrader := websocket.Upgrader{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				n := int(atomic.AddInt32(&connections, 1))
+				if n > len(replays) {
+					time.Sleep(time.Second)
+					return
+				}
+				for _, event := range replays[n-1] {
+					conn.WriteMessage(websocket.TextMessage, []byte(event))
+				}
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			c := New("ws"+strings.TrimPrefix(server.URL, "http"),
+				WithBackoff(time.Millisecond, time.Millisecond),
+				WithWindow(tc.window),
+			)
+			var received []string
+			c.OnEvent(func(event Event) {
+				received = append(received, event.TypeName())
+				if len(received) == len(strings.Fields(tc.expected)) {
+					cancel()
+				}
+			})
+			c.Run(ctx)
+			if res := strings.Join(received, " "); res != tc.expected {
+				t.Errorf("received %q, want %q", res, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright © 2020 Emando B.V.
+
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/emando/vantage-events/pkg/analysis"
+	"github.com/emando/vantage-events/pkg/events"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// Event is a decoded event. The concrete type depends on the type name, i.e. *events.HeatCommitted for
+// HeatCommittedEvent. Events of unknown types are decoded as *events.Raw.
+type Event interface {
+	TypeName() string
+}
+
+// Decode decodes the JSON encoded event into the typed event.
+func Decode(buf []byte) (Event, error) {
+	var raw events.Raw
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		return nil, err
+	}
+	var event Event
+	switch raw.TypeName() {
+	case events.CompetitionActivatedType:
+		event = &events.CompetitionActivated{Raw: buf}
+	case events.DistanceActivatedType:
+		event = &events.DistanceActivated{Raw: buf}
+	case events.DistanceDeactivatedType:
+		event = &events.Distance{}
+	case events.HeatActivatedType:
+		event = &events.HeatActivated{Raw: buf}
+	case events.HeatDeactivatedType, events.HeatClearedType:
+		event = &events.Heat{}
+	case events.HeatStartedType:
+		event = &events.HeatStarted{}
+	case events.HeatCommittedType:
+		event = &events.HeatCommitted{}
+	case events.HeatNextLapIndexChangedType:
+		event = &events.HeatNextLapIndexChanged{}
+	case events.RaceLapAddedType:
+		event = &events.RaceLapAdded{}
+	case events.LastPresentedRaceLapChangedType:
+		event = &events.LastPresentedRaceLapChanged{}
+	case events.RacePassingAddedType:
+		event = &events.RacePassingAdded{}
+	case events.LastRaceSpeedChangedType:
+		event = &events.LastRaceSpeedChanged{}
+	case events.RaceNextLapIndexChangedType:
+		event = &events.RaceNextLapIndexChanged{}
+	case analysis.SplitAnalyzedType:
+		event = &analysis.SplitAnalyzed{}
+	case analysis.FinishPredictedType:
+		event = &analysis.FinishPredicted{}
+	case analysis.TeamAnalyzedType:
+		event = &analysis.TeamAnalyzed{}
+	case events.StreamLiveType:
+		event = &events.StreamLive{}
+	case events.StreamBatchType:
+		event = &events.StreamBatch{}
+	case events.StreamResetType:
+		event = &events.StreamReset{}
+	case state.StreamSnapshotType:
+		event = &state.StreamSnapshot{}
+	default:
+		raw.Bytes = buf
+		return &raw, nil
+	}
+	if err := json.Unmarshal(buf, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
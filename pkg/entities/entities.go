@@ -2,18 +2,65 @@
 
 package entities
 
+import "time"
+
+// Ticks is a duration in Vantage ticks of 100 nanoseconds.
+type Ticks int64
+
+// Duration returns the ticks as duration.
+func (t Ticks) Duration() time.Duration {
+	return time.Duration(t) * 100
+}
+
+// Truncate truncates the ticks to the precision, i.e. the classification precision of a distance. If the precision is
+// not positive, the ticks are returned as is.
+func (t Ticks) Truncate(precision Ticks) Ticks {
+	if precision <= 0 {
+		return t
+	}
+	return t - t%precision
+}
+
 // Competition is a Vantage competition.
 type Competition struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Discipline string `json:"discipline"`
+	Class      int    `json:"class"`
+	Venue      *Venue `json:"venue"`
+}
+
+// Venue is a Vantage venue.
+type Venue struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Address struct {
+		City        string `json:"city"`
+		CountryCode string `json:"countryCode"`
+	} `json:"address"`
 }
 
+// Disciplines of distances.
+const (
+	// LongTrackPairs is the discipline prefix of long track distances skated in pairs.
+	LongTrackPairs = "SpeedSkating.LongTrack.PairsDistance."
+	// LongTrackMassStart is the discipline of long track mass start distances.
+	LongTrackMassStart = "SpeedSkating.LongTrack.MassStartDistance"
+)
+
 // Distance is a Vantage competition distance.
 type Distance struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	Discipline string `json:"discipline"`
-	StartMode  int    `json:"startMode"`
+	ID                      string `json:"id"`
+	Name                    string `json:"name"`
+	Number                  int    `json:"number"`
+	Discipline              string `json:"discipline"`
+	StartMode               int    `json:"startMode"`
+	ClassificationPrecision Ticks  `json:"classificationPrecision"`
+	TrackLength             int    `json:"trackLength"`
+	Value                   int    `json:"value"`
+	ValueQuantity           int    `json:"valueQuantity"`
+	Rounds                  int    `json:"rounds"`
+	FirstHeat               int    `json:"firstHeat"`
 }
 
 // Heat is a Vantage competition distance heat.
@@ -23,3 +70,131 @@ type Heat struct {
 		Number int `json:"number"`
 	} `json:"heat"`
 }
+
+// HeatRace is a race within a heat, as sent on heat activation and commit.
+type HeatRace struct {
+	Race          Race           `json:"race"`
+	Start         *Start         `json:"start"`
+	EstimatedLaps []PresentedLap `json:"estimatedLaps"`
+	Laps          []Lap          `json:"laps"`
+	Passings      []Passing      `json:"passings"`
+}
+
+// Race is a Vantage competition distance race of a competitor.
+type Race struct {
+	ID           string        `json:"id"`
+	Round        int           `json:"round"`
+	Heat         int           `json:"heat"`
+	Lane         int           `json:"lane"`
+	Color        int           `json:"color"`
+	Competitor   Competitor    `json:"competitor"`
+	PersonalBest *Ticks        `json:"personalBest"`
+	SeasonBest   *Ticks        `json:"seasonBest"`
+	Transponders []Transponder `json:"transponders"`
+	Laps         []Lap         `json:"laps"`
+	Time         *RaceTime     `json:"time"`
+	Result       *RaceResult   `json:"result"`
+}
+
+// TeamCompetitorType is the type name of a team competitor.
+const TeamCompetitorType = "TeamCompetitor"
+
+// Competitor is a competitor in a race. This is either a person or a team.
+type Competitor struct {
+	ID              string `json:"id"`
+	Type            string `json:"typeName"`
+	FullName        string `json:"fullName"`
+	ShortName       string `json:"shortName"`
+	StartNumber     int    `json:"startNumber"`
+	Category        string `json:"category"`
+	NationalityCode string `json:"nationalityCode"`
+	ClubCountryCode string `json:"clubCountryCode"`
+	PersonID        string `json:"personId"`
+	// Members are the members of a team competitor.
+	Members []TeamMember `json:"members"`
+}
+
+// TeamMember is a member of a team competitor.
+type TeamMember struct {
+	Order  int        `json:"order"`
+	Member Competitor `json:"member"`
+}
+
+// Transponder is a transponder worn by a competitor.
+type Transponder struct {
+	Code     int64  `json:"code"`
+	Label    string `json:"label"`
+	PersonID string `json:"personId"`
+	Set      int    `json:"set"`
+	Type     string `json:"type"`
+}
+
+// PresentationSource is the timing appliance that produced a time.
+type PresentationSource struct {
+	ApplianceInstanceName string `json:"applianceInstanceName"`
+	ApplianceName         string `json:"applianceName"`
+	How                   string `json:"how"`
+}
+
+// Start is the start of a race.
+type Start struct {
+	Flags              int                `json:"flags"`
+	InstanceName       string             `json:"instanceName"`
+	PresentationSource PresentationSource `json:"presentationSource"`
+	When               time.Time          `json:"when"`
+}
+
+// Lap is a lap time registered by a timing appliance.
+type Lap struct {
+	RaceID             string             `json:"raceId"`
+	Time               Ticks              `json:"time"`
+	When               time.Time          `json:"when"`
+	Flags              int                `json:"flags"`
+	FixedIndex         *int               `json:"fixedIndex"`
+	FixedRanking       *int               `json:"fixedRanking"`
+	Points             *int               `json:"points"`
+	InstanceName       string             `json:"instanceName"`
+	PresentationSource PresentationSource `json:"presentationSource"`
+}
+
+// PresentedLap is a lap as presented, or estimated, for a race.
+type PresentedLap struct {
+	Index        int     `json:"index"`
+	Time         Ticks   `json:"time"`
+	LapTime      Ticks   `json:"lapTime"`
+	PassedLength float64 `json:"passedLength"`
+	Ranking      *int    `json:"ranking"`
+	Rounds       float64 `json:"rounds"`
+	RoundsToGo   float64 `json:"roundsToGo"`
+}
+
+// Passing is a passing of a timing point.
+type Passing struct {
+	RaceID             string             `json:"raceId"`
+	Time               Ticks              `json:"time"`
+	When               time.Time          `json:"when"`
+	Where              int                `json:"where"`
+	Passed             *float64           `json:"passed"`
+	Speed              *float64           `json:"speed"`
+	Flags              int                `json:"flags"`
+	InstanceName       string             `json:"instanceName"`
+	PresentationSource PresentationSource `json:"presentationSource"`
+}
+
+// RaceTime is the final time of a race.
+type RaceTime struct {
+	Time                  Ticks  `json:"time"`
+	TimeInfo              int    `json:"timeInfo"`
+	InstanceName          string `json:"instanceName"`
+	ApplianceInstanceName string `json:"applianceInstanceName"`
+	ApplianceName         string `json:"applianceName"`
+	How                   string `json:"how"`
+}
+
+// RaceResult is the result of a race.
+type RaceResult struct {
+	Status            int      `json:"status"`
+	Points            *float64 `json:"points"`
+	TimeInvalidReason *string  `json:"timeInvalidReason"`
+	InstanceName      string   `json:"instanceName"`
+}
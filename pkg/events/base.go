@@ -2,6 +2,8 @@
 
 package events
 
+import "time"
+
 // Base contains fields of all events.
 type Base struct {
 	Type string `json:"typeName"`
@@ -16,4 +18,8 @@ func (b Base) TypeName() string {
 type Raw struct {
 	Base
 	Bytes []byte `json:"-"`
+	// Time is the time the event was published, if known by the source.
+	Time time.Time `json:"-"`
+	// Sequence is the sequence of the event in its subject, if known by the source.
+	Sequence uint64 `json:"-"`
 }
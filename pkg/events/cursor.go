@@ -0,0 +1,23 @@
+// Copyright © 2020 Emando B.V.
+
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Cursor returns the cursor of the JSON encoded event. This is the _cursor field with the subject and sequence of the
+// event in the source, if set, so that cursors are consistent across instances. Otherwise, the cursor is a hash of the
+// event.
+func Cursor(buf []byte) string {
+	var event struct {
+		Cursor string `json:"_cursor"`
+	}
+	if err := json.Unmarshal(buf, &event); err == nil && event.Cursor != "" {
+		return event.Cursor
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:8])
+}
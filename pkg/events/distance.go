@@ -18,7 +18,7 @@ const (
 	// DistanceActivatedType is the event name of a Vantage competition distance activated event.
 	DistanceActivatedType = "DistanceActivatedEvent"
 	// DistanceDeactivatedType is the event name of a Vantage competition distance deactivated event.
-	DistanceDeactivatedType = "DistanceActivatedEvent"
+	DistanceDeactivatedType = "DistanceDeactivatedEvent"
 )
 
 // DistanceActivated is the event data of a Vantage competition distance activation.
@@ -19,11 +19,41 @@ const (
 	HeatActivatedType = "HeatActivatedEvent"
 	// HeatDeactivatedType is the event name of a Vantage competition distance heat deactivation.
 	HeatDeactivatedType = "HeatDeactivatedEvent"
+	// HeatClearedType is the event name of a Vantage competition distance heat clear.
+	HeatClearedType = "HeatClearedEvent"
+	// HeatStartedType is the event name of a Vantage competition distance heat start.
+	HeatStartedType = "HeatStartedEvent"
+	// HeatCommittedType is the event name of a Vantage competition distance heat commit.
+	HeatCommittedType = "HeatCommittedEvent"
+	// HeatNextLapIndexChangedType is the event name of a Vantage competition distance heat next lap change.
+	HeatNextLapIndexChangedType = "HeatNextLapIndexChangedEvent"
 )
 
 // HeatActivated is the event data of a Vantage competition activation.
 type HeatActivated struct {
 	Heat
-	Time time.Time `json:"-"`
-	Raw  []byte    `json:"-"`
+	Races []entities.HeatRace `json:"races"`
+	Time  time.Time           `json:"-"`
+	Raw   []byte              `json:"-"`
+}
+
+// HeatStarted is the event data of a Vantage competition distance heat start.
+type HeatStarted struct {
+	Heat
+	Started time.Time `json:"started"`
+}
+
+// HeatCommitted is the event data of a Vantage competition distance heat commit.
+type HeatCommitted struct {
+	Heat
+	Races []entities.HeatRace `json:"races"`
+}
+
+// HeatNextLapIndexChanged is the event data of a Vantage competition distance heat next lap change.
+type HeatNextLapIndexChanged struct {
+	Heat
+	Index        int     `json:"index"`
+	PassedLength float64 `json:"passedLength"`
+	Rounds       float64 `json:"rounds"`
+	RoundsToGo   float64 `json:"roundsToGo"`
 }
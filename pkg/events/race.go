@@ -0,0 +1,58 @@
+// Copyright © 2020 Emando B.V.
+
+package events
+
+import "github.com/emando/vantage-events/pkg/entities"
+
+// Race is a Vantage competition distance heat race event.
+type Race struct {
+	Heat
+	RaceID string `json:"raceId"`
+}
+
+const (
+	// RaceLapAddedType is the event name of a Vantage race lap registration.
+	RaceLapAddedType = "RaceLapAddedEvent"
+	// LastPresentedRaceLapChangedType is the event name of a Vantage race presented lap change.
+	LastPresentedRaceLapChangedType = "LastPresentedRaceLapChangedEvent"
+	// RacePassingAddedType is the event name of a Vantage race passing registration.
+	RacePassingAddedType = "RacePassingAddedEvent"
+	// LastRaceSpeedChangedType is the event name of a Vantage race speed change.
+	LastRaceSpeedChangedType = "LastRaceSpeedChangedEvent"
+	// RaceNextLapIndexChangedType is the event name of a Vantage race next lap change.
+	RaceNextLapIndexChangedType = "RaceNextLapIndexChangedEvent"
+)
+
+// RaceLapAdded is the event data of a Vantage race lap registration.
+type RaceLapAdded struct {
+	Race
+	Lap entities.Lap `json:"lap"`
+}
+
+// LastPresentedRaceLapChanged is the event data of a Vantage race presented lap change.
+type LastPresentedRaceLapChanged struct {
+	Race
+	Lap            entities.PresentedLap `json:"lap"`
+	TimeDifference *entities.Ticks       `json:"timeDifference"`
+}
+
+// RacePassingAdded is the event data of a Vantage race passing registration.
+type RacePassingAdded struct {
+	Race
+	Passing entities.Passing `json:"passing"`
+}
+
+// LastRaceSpeedChanged is the event data of a Vantage race speed change.
+type LastRaceSpeedChanged struct {
+	Race
+	Passing entities.Passing `json:"passing"`
+}
+
+// RaceNextLapIndexChanged is the event data of a Vantage race next lap change.
+type RaceNextLapIndexChanged struct {
+	Race
+	Index        int     `json:"index"`
+	PassedLength float64 `json:"passedLength"`
+	Rounds       float64 `json:"rounds"`
+	RoundsToGo   float64 `json:"roundsToGo"`
+}
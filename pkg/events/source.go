@@ -4,15 +4,75 @@ package events
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
-// Source is a source for competition events.
+// ErrClosed is returned by consumers of a Source when a channel is closed before the context is done.
+var ErrClosed = errors.New("events: subscription closed")
+
+// Source is a source for competition events. The channels are unbuffered, so that consumers control the pace of
+// delivery, and closed when the context is done or when the subscription fails. A channel that is closed before the
+// context is done indicates a failed subscription; consumers may subscribe again.
+//
+// Events are delivered since the activation, or after the event with sequence after if it is not zero, so that
+// consumers resume failed subscriptions without receiving events twice.
 type Source interface {
 	CompetitionActivations(ctx context.Context, history time.Duration) (<-chan *CompetitionActivated, error)
-	CompetitionEvents(ctx context.Context, since *CompetitionActivated) (<-chan *Raw, error)
-	DistanceActivations(ctx context.Context, competitionID string) (<-chan *DistanceActivated, error)
-	DistanceEvents(ctx context.Context, since *DistanceActivated) (<-chan *Raw, error)
-	HeatActivations(ctx context.Context, competitionID, distanceID string, groups ...int) (<-chan *HeatActivated, error)
-	HeatEvents(ctx context.Context, since *HeatActivated) (<-chan *Raw, error)
+	CompetitionEvents(ctx context.Context, since *CompetitionActivated, after uint64) (*Replay, error)
+	DistanceActivations(ctx context.Context, competitionID string) (*DistanceActivations, error)
+	DistanceEvents(ctx context.Context, since *DistanceActivated, after uint64) (*Replay, error)
+	HeatActivations(ctx context.Context, competitionID, distanceID string, groups ...int) (*HeatActivations, error)
+	HeatEvents(ctx context.Context, since *HeatActivated, after uint64) (*Replay, error)
+}
+
+// Replay is a subscription to the events of a competition, distance or heat.
+type Replay struct {
+	Events <-chan *Raw
+	// Last is the sequence of the last event that was published before subscribing, or zero if there are no events to
+	// replay. Events with a greater sequence are live.
+	Last uint64
+}
+
+// DistanceActivations is a subscription to the distance activations of a competition. The last activation that was
+// published before subscribing is delivered first.
+type DistanceActivations struct {
+	Activations <-chan *DistanceActivated
+	// Replayed is closed when the activations that were published before subscribing have been delivered.
+	Replayed <-chan struct{}
+}
+
+// HeatActivations is a subscription to the heat activations of a distance. The last activation of each group that was
+// published before subscribing is delivered first.
+type HeatActivations struct {
+	Activations <-chan *HeatActivated
+	// Replayed is closed when the activations that were published before subscribing have been delivered.
+	Replayed <-chan struct{}
+}
+
+// ConnState is the state of the connection of a Source.
+type ConnState string
+
+// Connection states.
+const (
+	Connected    ConnState = "connected"
+	Reconnecting ConnState = "reconnecting"
+	Closed       ConnState = "closed"
+)
+
+// SourceStatus is the connection status of a Source.
+type SourceStatus struct {
+	State ConnState `json:"state"`
+	// Since is the time of the last state change.
+	Since      time.Time `json:"since"`
+	Reconnects int       `json:"reconnects"`
+	LastError  string    `json:"lastError,omitempty"`
+}
+
+// StatusReporter is implemented by sources that report the status of their connection.
+type StatusReporter interface {
+	// Status returns the current connection status.
+	Status() SourceStatus
+	// NotifyStatus calls f on each change of the connection status until the returned function is called.
+	NotifyStatus(f func(SourceStatus)) func()
 }
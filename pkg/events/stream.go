@@ -0,0 +1,59 @@
+// Copyright © 2020 Emando B.V.
+
+package events
+
+import "encoding/json"
+
+const (
+	// StreamLiveType is the event name of the control message that marks the end of the replay of a competition,
+	// distance or heat in a stream. Events that follow are live.
+	StreamLiveType = "StreamLiveEvent"
+	// StreamBatchType is the event name of the periodic batch of speed changes and passings of a heat in batched
+	// streams.
+	StreamBatchType = "StreamBatchEvent"
+	// StreamResetType is the event name of the control message that marks that the cursor of a resumed stream was not
+	// found in the replay. The full replay follows.
+	StreamResetType = "StreamResetEvent"
+)
+
+// Stream levels.
+const (
+	CompetitionLevel = "competition"
+	DistanceLevel    = "distance"
+	HeatLevel        = "heat"
+)
+
+// StreamLive is the event data of the control message that marks the end of the replay.
+type StreamLive struct {
+	Competition
+	// Level is the level of the subscription: competition, distance or heat.
+	Level      string          `json:"level"`
+	DistanceID string          `json:"distanceId,omitempty"`
+	Heat       *StreamLiveHeat `json:"heat,omitempty"`
+}
+
+// StreamLiveHeat identifies the heat of a StreamLive message.
+type StreamLiveHeat struct {
+	Round  int `json:"round"`
+	Number int `json:"number"`
+}
+
+// StreamReset is the event data of the control message that marks that the cursor was not found.
+type StreamReset struct {
+	Competition
+	Cursor string `json:"cursor"`
+}
+
+// StreamBatch is the event data of the periodic batch of speed changes and passings of a heat.
+type StreamBatch struct {
+	Heat
+	Races []StreamBatchRace `json:"races"`
+}
+
+// StreamBatchRace contains the changes of a race in a StreamBatch. Speed and passings contain the fields of the passing
+// that changed since the previous speed or passing of the race in the stream.
+type StreamBatchRace struct {
+	RaceID   string            `json:"raceId"`
+	Speed    json.RawMessage   `json:"speed,omitempty"`
+	Passings []json.RawMessage `json:"passings,omitempty"`
+}
@@ -0,0 +1,2519 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: events.proto
+
+package eventspb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type ListCompetitionsRequest struct {
+	// Window is the time window in seconds to seek competition activations. The default is 24 hours.
+	WindowSeconds        int64    `protobuf:"varint,1,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListCompetitionsRequest) Reset()         { *m = ListCompetitionsRequest{} }
+func (m *ListCompetitionsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListCompetitionsRequest) ProtoMessage()    {}
+func (*ListCompetitionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{0}
+}
+
+func (m *ListCompetitionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListCompetitionsRequest.Unmarshal(m, b)
+}
+func (m *ListCompetitionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListCompetitionsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListCompetitionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListCompetitionsRequest.Merge(m, src)
+}
+func (m *ListCompetitionsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListCompetitionsRequest.Size(m)
+}
+func (m *ListCompetitionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListCompetitionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListCompetitionsRequest proto.InternalMessageInfo
+
+func (m *ListCompetitionsRequest) GetWindowSeconds() int64 {
+	if m != nil {
+		return m.WindowSeconds
+	}
+	return 0
+}
+
+type StreamCompetitionRequest struct {
+	CompetitionId string `protobuf:"bytes,1,opt,name=competition_id,json=competitionId,proto3" json:"competition_id,omitempty"`
+	// Types filters events by type name, i.e. HeatCommittedEvent. Empty matches all types.
+	Types []string `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`
+	// DistanceIDs filters distance and heat events by distance ID. Empty matches all distances.
+	DistanceIds []string `protobuf:"bytes,3,rep,name=distance_ids,json=distanceIds,proto3" json:"distance_ids,omitempty"`
+	// Cursor resumes the stream after the event with the cursor.
+	Cursor               string   `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamCompetitionRequest) Reset()         { *m = StreamCompetitionRequest{} }
+func (m *StreamCompetitionRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamCompetitionRequest) ProtoMessage()    {}
+func (*StreamCompetitionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{1}
+}
+
+func (m *StreamCompetitionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamCompetitionRequest.Unmarshal(m, b)
+}
+func (m *StreamCompetitionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamCompetitionRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamCompetitionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamCompetitionRequest.Merge(m, src)
+}
+func (m *StreamCompetitionRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamCompetitionRequest.Size(m)
+}
+func (m *StreamCompetitionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamCompetitionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamCompetitionRequest proto.InternalMessageInfo
+
+func (m *StreamCompetitionRequest) GetCompetitionId() string {
+	if m != nil {
+		return m.CompetitionId
+	}
+	return ""
+}
+
+func (m *StreamCompetitionRequest) GetTypes() []string {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
+func (m *StreamCompetitionRequest) GetDistanceIds() []string {
+	if m != nil {
+		return m.DistanceIds
+	}
+	return nil
+}
+
+func (m *StreamCompetitionRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+type GetStateRequest struct {
+	CompetitionId        string   `protobuf:"bytes,1,opt,name=competition_id,json=competitionId,proto3" json:"competition_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStateRequest) Reset()         { *m = GetStateRequest{} }
+func (m *GetStateRequest) String() string { return proto.CompactTextString(m) }
+func (*GetStateRequest) ProtoMessage()    {}
+func (*GetStateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{2}
+}
+
+func (m *GetStateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStateRequest.Unmarshal(m, b)
+}
+func (m *GetStateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStateRequest.Marshal(b, m, deterministic)
+}
+func (m *GetStateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStateRequest.Merge(m, src)
+}
+func (m *GetStateRequest) XXX_Size() int {
+	return xxx_messageInfo_GetStateRequest.Size(m)
+}
+func (m *GetStateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStateRequest proto.InternalMessageInfo
+
+func (m *GetStateRequest) GetCompetitionId() string {
+	if m != nil {
+		return m.CompetitionId
+	}
+	return ""
+}
+
+// Event is a Vantage event.
+type Event struct {
+	// Cursor identifies the event in the stream to resume from.
+	Cursor        string   `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	TypeName      string   `protobuf:"bytes,2,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	CompetitionId string   `protobuf:"bytes,3,opt,name=competition_id,json=competitionId,proto3" json:"competition_id,omitempty"`
+	DistanceId    string   `protobuf:"bytes,4,opt,name=distance_id,json=distanceId,proto3" json:"distance_id,omitempty"`
+	Heat          *HeatKey `protobuf:"bytes,5,opt,name=heat,proto3" json:"heat,omitempty"`
+	RaceId        string   `protobuf:"bytes,6,opt,name=race_id,json=raceId,proto3" json:"race_id,omitempty"`
+	// Raw is the JSON encoded event.
+	Raw []byte `protobuf:"bytes,7,opt,name=raw,proto3" json:"raw,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//	*Event_CompetitionActivated
+	//	*Event_DistanceActivated
+	//	*Event_HeatActivated
+	//	*Event_HeatStarted
+	//	*Event_HeatCommitted
+	//	*Event_RaceLapAdded
+	//	*Event_LastPresentedRaceLapChanged
+	//	*Event_RacePassingAdded
+	//	*Event_LastRaceSpeedChanged
+	//	*Event_NextLapIndexChanged
+	Payload              isEvent_Payload `protobuf_oneof:"payload"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{3}
+}
+
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *Event) GetTypeName() string {
+	if m != nil {
+		return m.TypeName
+	}
+	return ""
+}
+
+func (m *Event) GetCompetitionId() string {
+	if m != nil {
+		return m.CompetitionId
+	}
+	return ""
+}
+
+func (m *Event) GetDistanceId() string {
+	if m != nil {
+		return m.DistanceId
+	}
+	return ""
+}
+
+func (m *Event) GetHeat() *HeatKey {
+	if m != nil {
+		return m.Heat
+	}
+	return nil
+}
+
+func (m *Event) GetRaceId() string {
+	if m != nil {
+		return m.RaceId
+	}
+	return ""
+}
+
+func (m *Event) GetRaw() []byte {
+	if m != nil {
+		return m.Raw
+	}
+	return nil
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_CompetitionActivated struct {
+	CompetitionActivated *CompetitionActivated `protobuf:"bytes,10,opt,name=competition_activated,json=competitionActivated,proto3,oneof"`
+}
+
+type Event_DistanceActivated struct {
+	DistanceActivated *DistanceActivated `protobuf:"bytes,11,opt,name=distance_activated,json=distanceActivated,proto3,oneof"`
+}
+
+type Event_HeatActivated struct {
+	HeatActivated *HeatActivated `protobuf:"bytes,12,opt,name=heat_activated,json=heatActivated,proto3,oneof"`
+}
+
+type Event_HeatStarted struct {
+	HeatStarted *HeatStarted `protobuf:"bytes,13,opt,name=heat_started,json=heatStarted,proto3,oneof"`
+}
+
+type Event_HeatCommitted struct {
+	HeatCommitted *HeatCommitted `protobuf:"bytes,14,opt,name=heat_committed,json=heatCommitted,proto3,oneof"`
+}
+
+type Event_RaceLapAdded struct {
+	RaceLapAdded *RaceLapAdded `protobuf:"bytes,15,opt,name=race_lap_added,json=raceLapAdded,proto3,oneof"`
+}
+
+type Event_LastPresentedRaceLapChanged struct {
+	LastPresentedRaceLapChanged *LastPresentedRaceLapChanged `protobuf:"bytes,16,opt,name=last_presented_race_lap_changed,json=lastPresentedRaceLapChanged,proto3,oneof"`
+}
+
+type Event_RacePassingAdded struct {
+	RacePassingAdded *RacePassingAdded `protobuf:"bytes,17,opt,name=race_passing_added,json=racePassingAdded,proto3,oneof"`
+}
+
+type Event_LastRaceSpeedChanged struct {
+	LastRaceSpeedChanged *LastRaceSpeedChanged `protobuf:"bytes,18,opt,name=last_race_speed_changed,json=lastRaceSpeedChanged,proto3,oneof"`
+}
+
+type Event_NextLapIndexChanged struct {
+	NextLapIndexChanged *NextLapIndexChanged `protobuf:"bytes,19,opt,name=next_lap_index_changed,json=nextLapIndexChanged,proto3,oneof"`
+}
+
+func (*Event_CompetitionActivated) isEvent_Payload() {}
+
+func (*Event_DistanceActivated) isEvent_Payload() {}
+
+func (*Event_HeatActivated) isEvent_Payload() {}
+
+func (*Event_HeatStarted) isEvent_Payload() {}
+
+func (*Event_HeatCommitted) isEvent_Payload() {}
+
+func (*Event_RaceLapAdded) isEvent_Payload() {}
+
+func (*Event_LastPresentedRaceLapChanged) isEvent_Payload() {}
+
+func (*Event_RacePassingAdded) isEvent_Payload() {}
+
+func (*Event_LastRaceSpeedChanged) isEvent_Payload() {}
+
+func (*Event_NextLapIndexChanged) isEvent_Payload() {}
+
+func (m *Event) GetPayload() isEvent_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *Event) GetCompetitionActivated() *CompetitionActivated {
+	if x, ok := m.GetPayload().(*Event_CompetitionActivated); ok {
+		return x.CompetitionActivated
+	}
+	return nil
+}
+
+func (m *Event) GetDistanceActivated() *DistanceActivated {
+	if x, ok := m.GetPayload().(*Event_DistanceActivated); ok {
+		return x.DistanceActivated
+	}
+	return nil
+}
+
+func (m *Event) GetHeatActivated() *HeatActivated {
+	if x, ok := m.GetPayload().(*Event_HeatActivated); ok {
+		return x.HeatActivated
+	}
+	return nil
+}
+
+func (m *Event) GetHeatStarted() *HeatStarted {
+	if x, ok := m.GetPayload().(*Event_HeatStarted); ok {
+		return x.HeatStarted
+	}
+	return nil
+}
+
+func (m *Event) GetHeatCommitted() *HeatCommitted {
+	if x, ok := m.GetPayload().(*Event_HeatCommitted); ok {
+		return x.HeatCommitted
+	}
+	return nil
+}
+
+func (m *Event) GetRaceLapAdded() *RaceLapAdded {
+	if x, ok := m.GetPayload().(*Event_RaceLapAdded); ok {
+		return x.RaceLapAdded
+	}
+	return nil
+}
+
+func (m *Event) GetLastPresentedRaceLapChanged() *LastPresentedRaceLapChanged {
+	if x, ok := m.GetPayload().(*Event_LastPresentedRaceLapChanged); ok {
+		return x.LastPresentedRaceLapChanged
+	}
+	return nil
+}
+
+func (m *Event) GetRacePassingAdded() *RacePassingAdded {
+	if x, ok := m.GetPayload().(*Event_RacePassingAdded); ok {
+		return x.RacePassingAdded
+	}
+	return nil
+}
+
+func (m *Event) GetLastRaceSpeedChanged() *LastRaceSpeedChanged {
+	if x, ok := m.GetPayload().(*Event_LastRaceSpeedChanged); ok {
+		return x.LastRaceSpeedChanged
+	}
+	return nil
+}
+
+func (m *Event) GetNextLapIndexChanged() *NextLapIndexChanged {
+	if x, ok := m.GetPayload().(*Event_NextLapIndexChanged); ok {
+		return x.NextLapIndexChanged
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Event) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Event_CompetitionActivated)(nil),
+		(*Event_DistanceActivated)(nil),
+		(*Event_HeatActivated)(nil),
+		(*Event_HeatStarted)(nil),
+		(*Event_HeatCommitted)(nil),
+		(*Event_RaceLapAdded)(nil),
+		(*Event_LastPresentedRaceLapChanged)(nil),
+		(*Event_RacePassingAdded)(nil),
+		(*Event_LastRaceSpeedChanged)(nil),
+		(*Event_NextLapIndexChanged)(nil),
+	}
+}
+
+type CompetitionActivated struct {
+	Competition          *Competition         `protobuf:"bytes,1,opt,name=competition,proto3" json:"competition,omitempty"`
+	Time                 *timestamp.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *CompetitionActivated) Reset()         { *m = CompetitionActivated{} }
+func (m *CompetitionActivated) String() string { return proto.CompactTextString(m) }
+func (*CompetitionActivated) ProtoMessage()    {}
+func (*CompetitionActivated) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{4}
+}
+
+func (m *CompetitionActivated) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompetitionActivated.Unmarshal(m, b)
+}
+func (m *CompetitionActivated) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompetitionActivated.Marshal(b, m, deterministic)
+}
+func (m *CompetitionActivated) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompetitionActivated.Merge(m, src)
+}
+func (m *CompetitionActivated) XXX_Size() int {
+	return xxx_messageInfo_CompetitionActivated.Size(m)
+}
+func (m *CompetitionActivated) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompetitionActivated.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompetitionActivated proto.InternalMessageInfo
+
+func (m *CompetitionActivated) GetCompetition() *Competition {
+	if m != nil {
+		return m.Competition
+	}
+	return nil
+}
+
+func (m *CompetitionActivated) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+type DistanceActivated struct {
+	Distance             *Distance `protobuf:"bytes,1,opt,name=distance,proto3" json:"distance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *DistanceActivated) Reset()         { *m = DistanceActivated{} }
+func (m *DistanceActivated) String() string { return proto.CompactTextString(m) }
+func (*DistanceActivated) ProtoMessage()    {}
+func (*DistanceActivated) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{5}
+}
+
+func (m *DistanceActivated) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DistanceActivated.Unmarshal(m, b)
+}
+func (m *DistanceActivated) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DistanceActivated.Marshal(b, m, deterministic)
+}
+func (m *DistanceActivated) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DistanceActivated.Merge(m, src)
+}
+func (m *DistanceActivated) XXX_Size() int {
+	return xxx_messageInfo_DistanceActivated.Size(m)
+}
+func (m *DistanceActivated) XXX_DiscardUnknown() {
+	xxx_messageInfo_DistanceActivated.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DistanceActivated proto.InternalMessageInfo
+
+func (m *DistanceActivated) GetDistance() *Distance {
+	if m != nil {
+		return m.Distance
+	}
+	return nil
+}
+
+type HeatActivated struct {
+	Races                []*HeatRace `protobuf:"bytes,1,rep,name=races,proto3" json:"races,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *HeatActivated) Reset()         { *m = HeatActivated{} }
+func (m *HeatActivated) String() string { return proto.CompactTextString(m) }
+func (*HeatActivated) ProtoMessage()    {}
+func (*HeatActivated) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{6}
+}
+
+func (m *HeatActivated) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeatActivated.Unmarshal(m, b)
+}
+func (m *HeatActivated) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeatActivated.Marshal(b, m, deterministic)
+}
+func (m *HeatActivated) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeatActivated.Merge(m, src)
+}
+func (m *HeatActivated) XXX_Size() int {
+	return xxx_messageInfo_HeatActivated.Size(m)
+}
+func (m *HeatActivated) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeatActivated.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeatActivated proto.InternalMessageInfo
+
+func (m *HeatActivated) GetRaces() []*HeatRace {
+	if m != nil {
+		return m.Races
+	}
+	return nil
+}
+
+type HeatStarted struct {
+	Started              *timestamp.Timestamp `protobuf:"bytes,1,opt,name=started,proto3" json:"started,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *HeatStarted) Reset()         { *m = HeatStarted{} }
+func (m *HeatStarted) String() string { return proto.CompactTextString(m) }
+func (*HeatStarted) ProtoMessage()    {}
+func (*HeatStarted) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{7}
+}
+
+func (m *HeatStarted) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeatStarted.Unmarshal(m, b)
+}
+func (m *HeatStarted) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeatStarted.Marshal(b, m, deterministic)
+}
+func (m *HeatStarted) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeatStarted.Merge(m, src)
+}
+func (m *HeatStarted) XXX_Size() int {
+	return xxx_messageInfo_HeatStarted.Size(m)
+}
+func (m *HeatStarted) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeatStarted.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeatStarted proto.InternalMessageInfo
+
+func (m *HeatStarted) GetStarted() *timestamp.Timestamp {
+	if m != nil {
+		return m.Started
+	}
+	return nil
+}
+
+type HeatCommitted struct {
+	Races                []*HeatRace `protobuf:"bytes,1,rep,name=races,proto3" json:"races,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *HeatCommitted) Reset()         { *m = HeatCommitted{} }
+func (m *HeatCommitted) String() string { return proto.CompactTextString(m) }
+func (*HeatCommitted) ProtoMessage()    {}
+func (*HeatCommitted) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{8}
+}
+
+func (m *HeatCommitted) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeatCommitted.Unmarshal(m, b)
+}
+func (m *HeatCommitted) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeatCommitted.Marshal(b, m, deterministic)
+}
+func (m *HeatCommitted) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeatCommitted.Merge(m, src)
+}
+func (m *HeatCommitted) XXX_Size() int {
+	return xxx_messageInfo_HeatCommitted.Size(m)
+}
+func (m *HeatCommitted) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeatCommitted.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeatCommitted proto.InternalMessageInfo
+
+func (m *HeatCommitted) GetRaces() []*HeatRace {
+	if m != nil {
+		return m.Races
+	}
+	return nil
+}
+
+type RaceLapAdded struct {
+	Lap                  *Lap     `protobuf:"bytes,1,opt,name=lap,proto3" json:"lap,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RaceLapAdded) Reset()         { *m = RaceLapAdded{} }
+func (m *RaceLapAdded) String() string { return proto.CompactTextString(m) }
+func (*RaceLapAdded) ProtoMessage()    {}
+func (*RaceLapAdded) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{9}
+}
+
+func (m *RaceLapAdded) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RaceLapAdded.Unmarshal(m, b)
+}
+func (m *RaceLapAdded) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RaceLapAdded.Marshal(b, m, deterministic)
+}
+func (m *RaceLapAdded) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RaceLapAdded.Merge(m, src)
+}
+func (m *RaceLapAdded) XXX_Size() int {
+	return xxx_messageInfo_RaceLapAdded.Size(m)
+}
+func (m *RaceLapAdded) XXX_DiscardUnknown() {
+	xxx_messageInfo_RaceLapAdded.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RaceLapAdded proto.InternalMessageInfo
+
+func (m *RaceLapAdded) GetLap() *Lap {
+	if m != nil {
+		return m.Lap
+	}
+	return nil
+}
+
+type LastPresentedRaceLapChanged struct {
+	Lap *PresentedLap `protobuf:"bytes,1,opt,name=lap,proto3" json:"lap,omitempty"`
+	// TimeDifference is the difference in ticks of 100 nanoseconds, if known.
+	TimeDifference       *Ticks   `protobuf:"bytes,2,opt,name=time_difference,json=timeDifference,proto3" json:"time_difference,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LastPresentedRaceLapChanged) Reset()         { *m = LastPresentedRaceLapChanged{} }
+func (m *LastPresentedRaceLapChanged) String() string { return proto.CompactTextString(m) }
+func (*LastPresentedRaceLapChanged) ProtoMessage()    {}
+func (*LastPresentedRaceLapChanged) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{10}
+}
+
+func (m *LastPresentedRaceLapChanged) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LastPresentedRaceLapChanged.Unmarshal(m, b)
+}
+func (m *LastPresentedRaceLapChanged) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LastPresentedRaceLapChanged.Marshal(b, m, deterministic)
+}
+func (m *LastPresentedRaceLapChanged) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LastPresentedRaceLapChanged.Merge(m, src)
+}
+func (m *LastPresentedRaceLapChanged) XXX_Size() int {
+	return xxx_messageInfo_LastPresentedRaceLapChanged.Size(m)
+}
+func (m *LastPresentedRaceLapChanged) XXX_DiscardUnknown() {
+	xxx_messageInfo_LastPresentedRaceLapChanged.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LastPresentedRaceLapChanged proto.InternalMessageInfo
+
+func (m *LastPresentedRaceLapChanged) GetLap() *PresentedLap {
+	if m != nil {
+		return m.Lap
+	}
+	return nil
+}
+
+func (m *LastPresentedRaceLapChanged) GetTimeDifference() *Ticks {
+	if m != nil {
+		return m.TimeDifference
+	}
+	return nil
+}
+
+type RacePassingAdded struct {
+	Passing              *Passing `protobuf:"bytes,1,opt,name=passing,proto3" json:"passing,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RacePassingAdded) Reset()         { *m = RacePassingAdded{} }
+func (m *RacePassingAdded) String() string { return proto.CompactTextString(m) }
+func (*RacePassingAdded) ProtoMessage()    {}
+func (*RacePassingAdded) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{11}
+}
+
+func (m *RacePassingAdded) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RacePassingAdded.Unmarshal(m, b)
+}
+func (m *RacePassingAdded) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RacePassingAdded.Marshal(b, m, deterministic)
+}
+func (m *RacePassingAdded) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RacePassingAdded.Merge(m, src)
+}
+func (m *RacePassingAdded) XXX_Size() int {
+	return xxx_messageInfo_RacePassingAdded.Size(m)
+}
+func (m *RacePassingAdded) XXX_DiscardUnknown() {
+	xxx_messageInfo_RacePassingAdded.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RacePassingAdded proto.InternalMessageInfo
+
+func (m *RacePassingAdded) GetPassing() *Passing {
+	if m != nil {
+		return m.Passing
+	}
+	return nil
+}
+
+type LastRaceSpeedChanged struct {
+	Passing              *Passing `protobuf:"bytes,1,opt,name=passing,proto3" json:"passing,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LastRaceSpeedChanged) Reset()         { *m = LastRaceSpeedChanged{} }
+func (m *LastRaceSpeedChanged) String() string { return proto.CompactTextString(m) }
+func (*LastRaceSpeedChanged) ProtoMessage()    {}
+func (*LastRaceSpeedChanged) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{12}
+}
+
+func (m *LastRaceSpeedChanged) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LastRaceSpeedChanged.Unmarshal(m, b)
+}
+func (m *LastRaceSpeedChanged) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LastRaceSpeedChanged.Marshal(b, m, deterministic)
+}
+func (m *LastRaceSpeedChanged) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LastRaceSpeedChanged.Merge(m, src)
+}
+func (m *LastRaceSpeedChanged) XXX_Size() int {
+	return xxx_messageInfo_LastRaceSpeedChanged.Size(m)
+}
+func (m *LastRaceSpeedChanged) XXX_DiscardUnknown() {
+	xxx_messageInfo_LastRaceSpeedChanged.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LastRaceSpeedChanged proto.InternalMessageInfo
+
+func (m *LastRaceSpeedChanged) GetPassing() *Passing {
+	if m != nil {
+		return m.Passing
+	}
+	return nil
+}
+
+// NextLapIndexChanged is the next lap of a heat or race.
+type NextLapIndexChanged struct {
+	Index                int32    `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	PassedLength         float64  `protobuf:"fixed64,2,opt,name=passed_length,json=passedLength,proto3" json:"passed_length,omitempty"`
+	Rounds               float64  `protobuf:"fixed64,3,opt,name=rounds,proto3" json:"rounds,omitempty"`
+	RoundsToGo           float64  `protobuf:"fixed64,4,opt,name=rounds_to_go,json=roundsToGo,proto3" json:"rounds_to_go,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NextLapIndexChanged) Reset()         { *m = NextLapIndexChanged{} }
+func (m *NextLapIndexChanged) String() string { return proto.CompactTextString(m) }
+func (*NextLapIndexChanged) ProtoMessage()    {}
+func (*NextLapIndexChanged) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{13}
+}
+
+func (m *NextLapIndexChanged) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NextLapIndexChanged.Unmarshal(m, b)
+}
+func (m *NextLapIndexChanged) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NextLapIndexChanged.Marshal(b, m, deterministic)
+}
+func (m *NextLapIndexChanged) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NextLapIndexChanged.Merge(m, src)
+}
+func (m *NextLapIndexChanged) XXX_Size() int {
+	return xxx_messageInfo_NextLapIndexChanged.Size(m)
+}
+func (m *NextLapIndexChanged) XXX_DiscardUnknown() {
+	xxx_messageInfo_NextLapIndexChanged.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NextLapIndexChanged proto.InternalMessageInfo
+
+func (m *NextLapIndexChanged) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *NextLapIndexChanged) GetPassedLength() float64 {
+	if m != nil {
+		return m.PassedLength
+	}
+	return 0
+}
+
+func (m *NextLapIndexChanged) GetRounds() float64 {
+	if m != nil {
+		return m.Rounds
+	}
+	return 0
+}
+
+func (m *NextLapIndexChanged) GetRoundsToGo() float64 {
+	if m != nil {
+		return m.RoundsToGo
+	}
+	return 0
+}
+
+// Ticks is a duration in ticks of 100 nanoseconds.
+type Ticks struct {
+	Value                int64    `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Ticks) Reset()         { *m = Ticks{} }
+func (m *Ticks) String() string { return proto.CompactTextString(m) }
+func (*Ticks) ProtoMessage()    {}
+func (*Ticks) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{14}
+}
+
+func (m *Ticks) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Ticks.Unmarshal(m, b)
+}
+func (m *Ticks) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Ticks.Marshal(b, m, deterministic)
+}
+func (m *Ticks) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Ticks.Merge(m, src)
+}
+func (m *Ticks) XXX_Size() int {
+	return xxx_messageInfo_Ticks.Size(m)
+}
+func (m *Ticks) XXX_DiscardUnknown() {
+	xxx_messageInfo_Ticks.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Ticks proto.InternalMessageInfo
+
+func (m *Ticks) GetValue() int64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type Competition struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Discipline           string   `protobuf:"bytes,3,opt,name=discipline,proto3" json:"discipline,omitempty"`
+	Class                int32    `protobuf:"varint,4,opt,name=class,proto3" json:"class,omitempty"`
+	VenueCode            string   `protobuf:"bytes,5,opt,name=venue_code,json=venueCode,proto3" json:"venue_code,omitempty"`
+	VenueName            string   `protobuf:"bytes,6,opt,name=venue_name,json=venueName,proto3" json:"venue_name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Competition) Reset()         { *m = Competition{} }
+func (m *Competition) String() string { return proto.CompactTextString(m) }
+func (*Competition) ProtoMessage()    {}
+func (*Competition) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{15}
+}
+
+func (m *Competition) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Competition.Unmarshal(m, b)
+}
+func (m *Competition) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Competition.Marshal(b, m, deterministic)
+}
+func (m *Competition) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Competition.Merge(m, src)
+}
+func (m *Competition) XXX_Size() int {
+	return xxx_messageInfo_Competition.Size(m)
+}
+func (m *Competition) XXX_DiscardUnknown() {
+	xxx_messageInfo_Competition.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Competition proto.InternalMessageInfo
+
+func (m *Competition) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Competition) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Competition) GetDiscipline() string {
+	if m != nil {
+		return m.Discipline
+	}
+	return ""
+}
+
+func (m *Competition) GetClass() int32 {
+	if m != nil {
+		return m.Class
+	}
+	return 0
+}
+
+func (m *Competition) GetVenueCode() string {
+	if m != nil {
+		return m.VenueCode
+	}
+	return ""
+}
+
+func (m *Competition) GetVenueName() string {
+	if m != nil {
+		return m.VenueName
+	}
+	return ""
+}
+
+type Distance struct {
+	Id                      string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                    string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Number                  int32    `protobuf:"varint,3,opt,name=number,proto3" json:"number,omitempty"`
+	Discipline              string   `protobuf:"bytes,4,opt,name=discipline,proto3" json:"discipline,omitempty"`
+	StartMode               int32    `protobuf:"varint,5,opt,name=start_mode,json=startMode,proto3" json:"start_mode,omitempty"`
+	ClassificationPrecision int64    `protobuf:"varint,6,opt,name=classification_precision,json=classificationPrecision,proto3" json:"classification_precision,omitempty"`
+	TrackLength             int32    `protobuf:"varint,7,opt,name=track_length,json=trackLength,proto3" json:"track_length,omitempty"`
+	Value                   int32    `protobuf:"varint,8,opt,name=value,proto3" json:"value,omitempty"`
+	ValueQuantity           int32    `protobuf:"varint,9,opt,name=value_quantity,json=valueQuantity,proto3" json:"value_quantity,omitempty"`
+	Rounds                  int32    `protobuf:"varint,10,opt,name=rounds,proto3" json:"rounds,omitempty"`
+	FirstHeat               int32    `protobuf:"varint,11,opt,name=first_heat,json=firstHeat,proto3" json:"first_heat,omitempty"`
+	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
+	XXX_unrecognized        []byte   `json:"-"`
+	XXX_sizecache           int32    `json:"-"`
+}
+
+func (m *Distance) Reset()         { *m = Distance{} }
+func (m *Distance) String() string { return proto.CompactTextString(m) }
+func (*Distance) ProtoMessage()    {}
+func (*Distance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{16}
+}
+
+func (m *Distance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Distance.Unmarshal(m, b)
+}
+func (m *Distance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Distance.Marshal(b, m, deterministic)
+}
+func (m *Distance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Distance.Merge(m, src)
+}
+func (m *Distance) XXX_Size() int {
+	return xxx_messageInfo_Distance.Size(m)
+}
+func (m *Distance) XXX_DiscardUnknown() {
+	xxx_messageInfo_Distance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Distance proto.InternalMessageInfo
+
+func (m *Distance) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Distance) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Distance) GetNumber() int32 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *Distance) GetDiscipline() string {
+	if m != nil {
+		return m.Discipline
+	}
+	return ""
+}
+
+func (m *Distance) GetStartMode() int32 {
+	if m != nil {
+		return m.StartMode
+	}
+	return 0
+}
+
+func (m *Distance) GetClassificationPrecision() int64 {
+	if m != nil {
+		return m.ClassificationPrecision
+	}
+	return 0
+}
+
+func (m *Distance) GetTrackLength() int32 {
+	if m != nil {
+		return m.TrackLength
+	}
+	return 0
+}
+
+func (m *Distance) GetValue() int32 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *Distance) GetValueQuantity() int32 {
+	if m != nil {
+		return m.ValueQuantity
+	}
+	return 0
+}
+
+func (m *Distance) GetRounds() int32 {
+	if m != nil {
+		return m.Rounds
+	}
+	return 0
+}
+
+func (m *Distance) GetFirstHeat() int32 {
+	if m != nil {
+		return m.FirstHeat
+	}
+	return 0
+}
+
+type HeatKey struct {
+	Round                int32    `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Number               int32    `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HeatKey) Reset()         { *m = HeatKey{} }
+func (m *HeatKey) String() string { return proto.CompactTextString(m) }
+func (*HeatKey) ProtoMessage()    {}
+func (*HeatKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{17}
+}
+
+func (m *HeatKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeatKey.Unmarshal(m, b)
+}
+func (m *HeatKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeatKey.Marshal(b, m, deterministic)
+}
+func (m *HeatKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeatKey.Merge(m, src)
+}
+func (m *HeatKey) XXX_Size() int {
+	return xxx_messageInfo_HeatKey.Size(m)
+}
+func (m *HeatKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeatKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeatKey proto.InternalMessageInfo
+
+func (m *HeatKey) GetRound() int32 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *HeatKey) GetNumber() int32 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+type HeatRace struct {
+	Race                 *Race           `protobuf:"bytes,1,opt,name=race,proto3" json:"race,omitempty"`
+	EstimatedLaps        []*PresentedLap `protobuf:"bytes,2,rep,name=estimated_laps,json=estimatedLaps,proto3" json:"estimated_laps,omitempty"`
+	Laps                 []*Lap          `protobuf:"bytes,3,rep,name=laps,proto3" json:"laps,omitempty"`
+	Passings             []*Passing      `protobuf:"bytes,4,rep,name=passings,proto3" json:"passings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *HeatRace) Reset()         { *m = HeatRace{} }
+func (m *HeatRace) String() string { return proto.CompactTextString(m) }
+func (*HeatRace) ProtoMessage()    {}
+func (*HeatRace) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{18}
+}
+
+func (m *HeatRace) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeatRace.Unmarshal(m, b)
+}
+func (m *HeatRace) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeatRace.Marshal(b, m, deterministic)
+}
+func (m *HeatRace) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeatRace.Merge(m, src)
+}
+func (m *HeatRace) XXX_Size() int {
+	return xxx_messageInfo_HeatRace.Size(m)
+}
+func (m *HeatRace) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeatRace.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeatRace proto.InternalMessageInfo
+
+func (m *HeatRace) GetRace() *Race {
+	if m != nil {
+		return m.Race
+	}
+	return nil
+}
+
+func (m *HeatRace) GetEstimatedLaps() []*PresentedLap {
+	if m != nil {
+		return m.EstimatedLaps
+	}
+	return nil
+}
+
+func (m *HeatRace) GetLaps() []*Lap {
+	if m != nil {
+		return m.Laps
+	}
+	return nil
+}
+
+func (m *HeatRace) GetPassings() []*Passing {
+	if m != nil {
+		return m.Passings
+	}
+	return nil
+}
+
+type Race struct {
+	Id                   string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Round                int32          `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	Heat                 int32          `protobuf:"varint,3,opt,name=heat,proto3" json:"heat,omitempty"`
+	Lane                 int32          `protobuf:"varint,4,opt,name=lane,proto3" json:"lane,omitempty"`
+	Color                int32          `protobuf:"varint,5,opt,name=color,proto3" json:"color,omitempty"`
+	Competitor           *Competitor    `protobuf:"bytes,6,opt,name=competitor,proto3" json:"competitor,omitempty"`
+	PersonalBest         *Ticks         `protobuf:"bytes,7,opt,name=personal_best,json=personalBest,proto3" json:"personal_best,omitempty"`
+	SeasonBest           *Ticks         `protobuf:"bytes,8,opt,name=season_best,json=seasonBest,proto3" json:"season_best,omitempty"`
+	Transponders         []*Transponder `protobuf:"bytes,9,rep,name=transponders,proto3" json:"transponders,omitempty"`
+	Time                 *RaceTime      `protobuf:"bytes,10,opt,name=time,proto3" json:"time,omitempty"`
+	Result               *RaceResult    `protobuf:"bytes,11,opt,name=result,proto3" json:"result,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Race) Reset()         { *m = Race{} }
+func (m *Race) String() string { return proto.CompactTextString(m) }
+func (*Race) ProtoMessage()    {}
+func (*Race) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{19}
+}
+
+func (m *Race) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Race.Unmarshal(m, b)
+}
+func (m *Race) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Race.Marshal(b, m, deterministic)
+}
+func (m *Race) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Race.Merge(m, src)
+}
+func (m *Race) XXX_Size() int {
+	return xxx_messageInfo_Race.Size(m)
+}
+func (m *Race) XXX_DiscardUnknown() {
+	xxx_messageInfo_Race.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Race proto.InternalMessageInfo
+
+func (m *Race) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Race) GetRound() int32 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *Race) GetHeat() int32 {
+	if m != nil {
+		return m.Heat
+	}
+	return 0
+}
+
+func (m *Race) GetLane() int32 {
+	if m != nil {
+		return m.Lane
+	}
+	return 0
+}
+
+func (m *Race) GetColor() int32 {
+	if m != nil {
+		return m.Color
+	}
+	return 0
+}
+
+func (m *Race) GetCompetitor() *Competitor {
+	if m != nil {
+		return m.Competitor
+	}
+	return nil
+}
+
+func (m *Race) GetPersonalBest() *Ticks {
+	if m != nil {
+		return m.PersonalBest
+	}
+	return nil
+}
+
+func (m *Race) GetSeasonBest() *Ticks {
+	if m != nil {
+		return m.SeasonBest
+	}
+	return nil
+}
+
+func (m *Race) GetTransponders() []*Transponder {
+	if m != nil {
+		return m.Transponders
+	}
+	return nil
+}
+
+func (m *Race) GetTime() *RaceTime {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *Race) GetResult() *RaceResult {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+type Competitor struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TypeName             string   `protobuf:"bytes,2,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	FullName             string   `protobuf:"bytes,3,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+	ShortName            string   `protobuf:"bytes,4,opt,name=short_name,json=shortName,proto3" json:"short_name,omitempty"`
+	StartNumber          int32    `protobuf:"varint,5,opt,name=start_number,json=startNumber,proto3" json:"start_number,omitempty"`
+	Category             string   `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	NationalityCode      string   `protobuf:"bytes,7,opt,name=nationality_code,json=nationalityCode,proto3" json:"nationality_code,omitempty"`
+	PersonId             string   `protobuf:"bytes,8,opt,name=person_id,json=personId,proto3" json:"person_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Competitor) Reset()         { *m = Competitor{} }
+func (m *Competitor) String() string { return proto.CompactTextString(m) }
+func (*Competitor) ProtoMessage()    {}
+func (*Competitor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{20}
+}
+
+func (m *Competitor) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Competitor.Unmarshal(m, b)
+}
+func (m *Competitor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Competitor.Marshal(b, m, deterministic)
+}
+func (m *Competitor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Competitor.Merge(m, src)
+}
+func (m *Competitor) XXX_Size() int {
+	return xxx_messageInfo_Competitor.Size(m)
+}
+func (m *Competitor) XXX_DiscardUnknown() {
+	xxx_messageInfo_Competitor.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Competitor proto.InternalMessageInfo
+
+func (m *Competitor) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Competitor) GetTypeName() string {
+	if m != nil {
+		return m.TypeName
+	}
+	return ""
+}
+
+func (m *Competitor) GetFullName() string {
+	if m != nil {
+		return m.FullName
+	}
+	return ""
+}
+
+func (m *Competitor) GetShortName() string {
+	if m != nil {
+		return m.ShortName
+	}
+	return ""
+}
+
+func (m *Competitor) GetStartNumber() int32 {
+	if m != nil {
+		return m.StartNumber
+	}
+	return 0
+}
+
+func (m *Competitor) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *Competitor) GetNationalityCode() string {
+	if m != nil {
+		return m.NationalityCode
+	}
+	return ""
+}
+
+func (m *Competitor) GetPersonId() string {
+	if m != nil {
+		return m.PersonId
+	}
+	return ""
+}
+
+type Transponder struct {
+	Code                 int64    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Label                string   `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	PersonId             string   `protobuf:"bytes,3,opt,name=person_id,json=personId,proto3" json:"person_id,omitempty"`
+	Set                  int32    `protobuf:"varint,4,opt,name=set,proto3" json:"set,omitempty"`
+	Type                 string   `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Transponder) Reset()         { *m = Transponder{} }
+func (m *Transponder) String() string { return proto.CompactTextString(m) }
+func (*Transponder) ProtoMessage()    {}
+func (*Transponder) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{21}
+}
+
+func (m *Transponder) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Transponder.Unmarshal(m, b)
+}
+func (m *Transponder) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Transponder.Marshal(b, m, deterministic)
+}
+func (m *Transponder) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Transponder.Merge(m, src)
+}
+func (m *Transponder) XXX_Size() int {
+	return xxx_messageInfo_Transponder.Size(m)
+}
+func (m *Transponder) XXX_DiscardUnknown() {
+	xxx_messageInfo_Transponder.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Transponder proto.InternalMessageInfo
+
+func (m *Transponder) GetCode() int64 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *Transponder) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+func (m *Transponder) GetPersonId() string {
+	if m != nil {
+		return m.PersonId
+	}
+	return ""
+}
+
+func (m *Transponder) GetSet() int32 {
+	if m != nil {
+		return m.Set
+	}
+	return 0
+}
+
+func (m *Transponder) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+type PresentationSource struct {
+	ApplianceInstanceName string   `protobuf:"bytes,1,opt,name=appliance_instance_name,json=applianceInstanceName,proto3" json:"appliance_instance_name,omitempty"`
+	ApplianceName         string   `protobuf:"bytes,2,opt,name=appliance_name,json=applianceName,proto3" json:"appliance_name,omitempty"`
+	How                   string   `protobuf:"bytes,3,opt,name=how,proto3" json:"how,omitempty"`
+	XXX_NoUnkeyedLiteral  struct{} `json:"-"`
+	XXX_unrecognized      []byte   `json:"-"`
+	XXX_sizecache         int32    `json:"-"`
+}
+
+func (m *PresentationSource) Reset()         { *m = PresentationSource{} }
+func (m *PresentationSource) String() string { return proto.CompactTextString(m) }
+func (*PresentationSource) ProtoMessage()    {}
+func (*PresentationSource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{22}
+}
+
+func (m *PresentationSource) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PresentationSource.Unmarshal(m, b)
+}
+func (m *PresentationSource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PresentationSource.Marshal(b, m, deterministic)
+}
+func (m *PresentationSource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PresentationSource.Merge(m, src)
+}
+func (m *PresentationSource) XXX_Size() int {
+	return xxx_messageInfo_PresentationSource.Size(m)
+}
+func (m *PresentationSource) XXX_DiscardUnknown() {
+	xxx_messageInfo_PresentationSource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PresentationSource proto.InternalMessageInfo
+
+func (m *PresentationSource) GetApplianceInstanceName() string {
+	if m != nil {
+		return m.ApplianceInstanceName
+	}
+	return ""
+}
+
+func (m *PresentationSource) GetApplianceName() string {
+	if m != nil {
+		return m.ApplianceName
+	}
+	return ""
+}
+
+func (m *PresentationSource) GetHow() string {
+	if m != nil {
+		return m.How
+	}
+	return ""
+}
+
+type Lap struct {
+	Time                 int64                `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	When                 *timestamp.Timestamp `protobuf:"bytes,2,opt,name=when,proto3" json:"when,omitempty"`
+	Flags                int32                `protobuf:"varint,3,opt,name=flags,proto3" json:"flags,omitempty"`
+	InstanceName         string               `protobuf:"bytes,4,opt,name=instance_name,json=instanceName,proto3" json:"instance_name,omitempty"`
+	PresentationSource   *PresentationSource  `protobuf:"bytes,5,opt,name=presentation_source,json=presentationSource,proto3" json:"presentation_source,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Lap) Reset()         { *m = Lap{} }
+func (m *Lap) String() string { return proto.CompactTextString(m) }
+func (*Lap) ProtoMessage()    {}
+func (*Lap) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{23}
+}
+
+func (m *Lap) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Lap.Unmarshal(m, b)
+}
+func (m *Lap) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Lap.Marshal(b, m, deterministic)
+}
+func (m *Lap) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Lap.Merge(m, src)
+}
+func (m *Lap) XXX_Size() int {
+	return xxx_messageInfo_Lap.Size(m)
+}
+func (m *Lap) XXX_DiscardUnknown() {
+	xxx_messageInfo_Lap.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Lap proto.InternalMessageInfo
+
+func (m *Lap) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Lap) GetWhen() *timestamp.Timestamp {
+	if m != nil {
+		return m.When
+	}
+	return nil
+}
+
+func (m *Lap) GetFlags() int32 {
+	if m != nil {
+		return m.Flags
+	}
+	return 0
+}
+
+func (m *Lap) GetInstanceName() string {
+	if m != nil {
+		return m.InstanceName
+	}
+	return ""
+}
+
+func (m *Lap) GetPresentationSource() *PresentationSource {
+	if m != nil {
+		return m.PresentationSource
+	}
+	return nil
+}
+
+type PresentedLap struct {
+	Index        int32   `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Time         int64   `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	LapTime      int64   `protobuf:"varint,3,opt,name=lap_time,json=lapTime,proto3" json:"lap_time,omitempty"`
+	PassedLength float64 `protobuf:"fixed64,4,opt,name=passed_length,json=passedLength,proto3" json:"passed_length,omitempty"`
+	// Ranking is the ranking at the lap, or 0 if unknown.
+	Ranking              int32    `protobuf:"varint,5,opt,name=ranking,proto3" json:"ranking,omitempty"`
+	Rounds               float64  `protobuf:"fixed64,6,opt,name=rounds,proto3" json:"rounds,omitempty"`
+	RoundsToGo           float64  `protobuf:"fixed64,7,opt,name=rounds_to_go,json=roundsToGo,proto3" json:"rounds_to_go,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PresentedLap) Reset()         { *m = PresentedLap{} }
+func (m *PresentedLap) String() string { return proto.CompactTextString(m) }
+func (*PresentedLap) ProtoMessage()    {}
+func (*PresentedLap) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{24}
+}
+
+func (m *PresentedLap) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PresentedLap.Unmarshal(m, b)
+}
+func (m *PresentedLap) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PresentedLap.Marshal(b, m, deterministic)
+}
+func (m *PresentedLap) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PresentedLap.Merge(m, src)
+}
+func (m *PresentedLap) XXX_Size() int {
+	return xxx_messageInfo_PresentedLap.Size(m)
+}
+func (m *PresentedLap) XXX_DiscardUnknown() {
+	xxx_messageInfo_PresentedLap.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PresentedLap proto.InternalMessageInfo
+
+func (m *PresentedLap) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *PresentedLap) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *PresentedLap) GetLapTime() int64 {
+	if m != nil {
+		return m.LapTime
+	}
+	return 0
+}
+
+func (m *PresentedLap) GetPassedLength() float64 {
+	if m != nil {
+		return m.PassedLength
+	}
+	return 0
+}
+
+func (m *PresentedLap) GetRanking() int32 {
+	if m != nil {
+		return m.Ranking
+	}
+	return 0
+}
+
+func (m *PresentedLap) GetRounds() float64 {
+	if m != nil {
+		return m.Rounds
+	}
+	return 0
+}
+
+func (m *PresentedLap) GetRoundsToGo() float64 {
+	if m != nil {
+		return m.RoundsToGo
+	}
+	return 0
+}
+
+type Passing struct {
+	Time  int64                `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	When  *timestamp.Timestamp `protobuf:"bytes,2,opt,name=when,proto3" json:"when,omitempty"`
+	Where int32                `protobuf:"varint,3,opt,name=where,proto3" json:"where,omitempty"`
+	// Passed is the passed length in meters, or -1 if unknown.
+	Passed float64 `protobuf:"fixed64,4,opt,name=passed,proto3" json:"passed,omitempty"`
+	// Speed is the speed in meters per second, or -1 if unknown.
+	Speed                float64             `protobuf:"fixed64,5,opt,name=speed,proto3" json:"speed,omitempty"`
+	Flags                int32               `protobuf:"varint,6,opt,name=flags,proto3" json:"flags,omitempty"`
+	InstanceName         string              `protobuf:"bytes,7,opt,name=instance_name,json=instanceName,proto3" json:"instance_name,omitempty"`
+	PresentationSource   *PresentationSource `protobuf:"bytes,8,opt,name=presentation_source,json=presentationSource,proto3" json:"presentation_source,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *Passing) Reset()         { *m = Passing{} }
+func (m *Passing) String() string { return proto.CompactTextString(m) }
+func (*Passing) ProtoMessage()    {}
+func (*Passing) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{25}
+}
+
+func (m *Passing) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Passing.Unmarshal(m, b)
+}
+func (m *Passing) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Passing.Marshal(b, m, deterministic)
+}
+func (m *Passing) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Passing.Merge(m, src)
+}
+func (m *Passing) XXX_Size() int {
+	return xxx_messageInfo_Passing.Size(m)
+}
+func (m *Passing) XXX_DiscardUnknown() {
+	xxx_messageInfo_Passing.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Passing proto.InternalMessageInfo
+
+func (m *Passing) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Passing) GetWhen() *timestamp.Timestamp {
+	if m != nil {
+		return m.When
+	}
+	return nil
+}
+
+func (m *Passing) GetWhere() int32 {
+	if m != nil {
+		return m.Where
+	}
+	return 0
+}
+
+func (m *Passing) GetPassed() float64 {
+	if m != nil {
+		return m.Passed
+	}
+	return 0
+}
+
+func (m *Passing) GetSpeed() float64 {
+	if m != nil {
+		return m.Speed
+	}
+	return 0
+}
+
+func (m *Passing) GetFlags() int32 {
+	if m != nil {
+		return m.Flags
+	}
+	return 0
+}
+
+func (m *Passing) GetInstanceName() string {
+	if m != nil {
+		return m.InstanceName
+	}
+	return ""
+}
+
+func (m *Passing) GetPresentationSource() *PresentationSource {
+	if m != nil {
+		return m.PresentationSource
+	}
+	return nil
+}
+
+type RaceTime struct {
+	Time                 int64    `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	TimeInfo             int32    `protobuf:"varint,2,opt,name=time_info,json=timeInfo,proto3" json:"time_info,omitempty"`
+	How                  string   `protobuf:"bytes,3,opt,name=how,proto3" json:"how,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RaceTime) Reset()         { *m = RaceTime{} }
+func (m *RaceTime) String() string { return proto.CompactTextString(m) }
+func (*RaceTime) ProtoMessage()    {}
+func (*RaceTime) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{26}
+}
+
+func (m *RaceTime) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RaceTime.Unmarshal(m, b)
+}
+func (m *RaceTime) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RaceTime.Marshal(b, m, deterministic)
+}
+func (m *RaceTime) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RaceTime.Merge(m, src)
+}
+func (m *RaceTime) XXX_Size() int {
+	return xxx_messageInfo_RaceTime.Size(m)
+}
+func (m *RaceTime) XXX_DiscardUnknown() {
+	xxx_messageInfo_RaceTime.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RaceTime proto.InternalMessageInfo
+
+func (m *RaceTime) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *RaceTime) GetTimeInfo() int32 {
+	if m != nil {
+		return m.TimeInfo
+	}
+	return 0
+}
+
+func (m *RaceTime) GetHow() string {
+	if m != nil {
+		return m.How
+	}
+	return ""
+}
+
+type RaceResult struct {
+	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RaceResult) Reset()         { *m = RaceResult{} }
+func (m *RaceResult) String() string { return proto.CompactTextString(m) }
+func (*RaceResult) ProtoMessage()    {}
+func (*RaceResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{27}
+}
+
+func (m *RaceResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RaceResult.Unmarshal(m, b)
+}
+func (m *RaceResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RaceResult.Marshal(b, m, deterministic)
+}
+func (m *RaceResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RaceResult.Merge(m, src)
+}
+func (m *RaceResult) XXX_Size() int {
+	return xxx_messageInfo_RaceResult.Size(m)
+}
+func (m *RaceResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_RaceResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RaceResult proto.InternalMessageInfo
+
+func (m *RaceResult) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+// CompetitionState is a snapshot of the state of a competition.
+type CompetitionState struct {
+	Competition          *Competition     `protobuf:"bytes,1,opt,name=competition,proto3" json:"competition,omitempty"`
+	ActiveDistanceId     string           `protobuf:"bytes,2,opt,name=active_distance_id,json=activeDistanceId,proto3" json:"active_distance_id,omitempty"`
+	Distances            []*DistanceState `protobuf:"bytes,3,rep,name=distances,proto3" json:"distances,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *CompetitionState) Reset()         { *m = CompetitionState{} }
+func (m *CompetitionState) String() string { return proto.CompactTextString(m) }
+func (*CompetitionState) ProtoMessage()    {}
+func (*CompetitionState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{28}
+}
+
+func (m *CompetitionState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompetitionState.Unmarshal(m, b)
+}
+func (m *CompetitionState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompetitionState.Marshal(b, m, deterministic)
+}
+func (m *CompetitionState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompetitionState.Merge(m, src)
+}
+func (m *CompetitionState) XXX_Size() int {
+	return xxx_messageInfo_CompetitionState.Size(m)
+}
+func (m *CompetitionState) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompetitionState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompetitionState proto.InternalMessageInfo
+
+func (m *CompetitionState) GetCompetition() *Competition {
+	if m != nil {
+		return m.Competition
+	}
+	return nil
+}
+
+func (m *CompetitionState) GetActiveDistanceId() string {
+	if m != nil {
+		return m.ActiveDistanceId
+	}
+	return ""
+}
+
+func (m *CompetitionState) GetDistances() []*DistanceState {
+	if m != nil {
+		return m.Distances
+	}
+	return nil
+}
+
+type DistanceState struct {
+	Distance             *Distance    `protobuf:"bytes,1,opt,name=distance,proto3" json:"distance,omitempty"`
+	Active               bool         `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	Heats                []*HeatState `protobuf:"bytes,3,rep,name=heats,proto3" json:"heats,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *DistanceState) Reset()         { *m = DistanceState{} }
+func (m *DistanceState) String() string { return proto.CompactTextString(m) }
+func (*DistanceState) ProtoMessage()    {}
+func (*DistanceState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{29}
+}
+
+func (m *DistanceState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DistanceState.Unmarshal(m, b)
+}
+func (m *DistanceState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DistanceState.Marshal(b, m, deterministic)
+}
+func (m *DistanceState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DistanceState.Merge(m, src)
+}
+func (m *DistanceState) XXX_Size() int {
+	return xxx_messageInfo_DistanceState.Size(m)
+}
+func (m *DistanceState) XXX_DiscardUnknown() {
+	xxx_messageInfo_DistanceState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DistanceState proto.InternalMessageInfo
+
+func (m *DistanceState) GetDistance() *Distance {
+	if m != nil {
+		return m.Distance
+	}
+	return nil
+}
+
+func (m *DistanceState) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *DistanceState) GetHeats() []*HeatState {
+	if m != nil {
+		return m.Heats
+	}
+	return nil
+}
+
+type HeatState struct {
+	Key                  *HeatKey             `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Active               bool                 `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	Started              *timestamp.Timestamp `protobuf:"bytes,3,opt,name=started,proto3" json:"started,omitempty"`
+	Committed            bool                 `protobuf:"varint,4,opt,name=committed,proto3" json:"committed,omitempty"`
+	Races                []*RaceState         `protobuf:"bytes,5,rep,name=races,proto3" json:"races,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *HeatState) Reset()         { *m = HeatState{} }
+func (m *HeatState) String() string { return proto.CompactTextString(m) }
+func (*HeatState) ProtoMessage()    {}
+func (*HeatState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{30}
+}
+
+func (m *HeatState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeatState.Unmarshal(m, b)
+}
+func (m *HeatState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeatState.Marshal(b, m, deterministic)
+}
+func (m *HeatState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeatState.Merge(m, src)
+}
+func (m *HeatState) XXX_Size() int {
+	return xxx_messageInfo_HeatState.Size(m)
+}
+func (m *HeatState) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeatState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeatState proto.InternalMessageInfo
+
+func (m *HeatState) GetKey() *HeatKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *HeatState) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *HeatState) GetStarted() *timestamp.Timestamp {
+	if m != nil {
+		return m.Started
+	}
+	return nil
+}
+
+func (m *HeatState) GetCommitted() bool {
+	if m != nil {
+		return m.Committed
+	}
+	return false
+}
+
+func (m *HeatState) GetRaces() []*RaceState {
+	if m != nil {
+		return m.Races
+	}
+	return nil
+}
+
+type RaceState struct {
+	Race                 *Race           `protobuf:"bytes,1,opt,name=race,proto3" json:"race,omitempty"`
+	EstimatedLaps        []*PresentedLap `protobuf:"bytes,2,rep,name=estimated_laps,json=estimatedLaps,proto3" json:"estimated_laps,omitempty"`
+	PresentedLaps        []*PresentedLap `protobuf:"bytes,3,rep,name=presented_laps,json=presentedLaps,proto3" json:"presented_laps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *RaceState) Reset()         { *m = RaceState{} }
+func (m *RaceState) String() string { return proto.CompactTextString(m) }
+func (*RaceState) ProtoMessage()    {}
+func (*RaceState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f22242cb04491f9, []int{31}
+}
+
+func (m *RaceState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RaceState.Unmarshal(m, b)
+}
+func (m *RaceState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RaceState.Marshal(b, m, deterministic)
+}
+func (m *RaceState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RaceState.Merge(m, src)
+}
+func (m *RaceState) XXX_Size() int {
+	return xxx_messageInfo_RaceState.Size(m)
+}
+func (m *RaceState) XXX_DiscardUnknown() {
+	xxx_messageInfo_RaceState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RaceState proto.InternalMessageInfo
+
+func (m *RaceState) GetRace() *Race {
+	if m != nil {
+		return m.Race
+	}
+	return nil
+}
+
+func (m *RaceState) GetEstimatedLaps() []*PresentedLap {
+	if m != nil {
+		return m.EstimatedLaps
+	}
+	return nil
+}
+
+func (m *RaceState) GetPresentedLaps() []*PresentedLap {
+	if m != nil {
+		return m.PresentedLaps
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ListCompetitionsRequest)(nil), "vantage.events.v1.ListCompetitionsRequest")
+	proto.RegisterType((*StreamCompetitionRequest)(nil), "vantage.events.v1.StreamCompetitionRequest")
+	proto.RegisterType((*GetStateRequest)(nil), "vantage.events.v1.GetStateRequest")
+	proto.RegisterType((*Event)(nil), "vantage.events.v1.Event")
+	proto.RegisterType((*CompetitionActivated)(nil), "vantage.events.v1.CompetitionActivated")
+	proto.RegisterType((*DistanceActivated)(nil), "vantage.events.v1.DistanceActivated")
+	proto.RegisterType((*HeatActivated)(nil), "vantage.events.v1.HeatActivated")
+	proto.RegisterType((*HeatStarted)(nil), "vantage.events.v1.HeatStarted")
+	proto.RegisterType((*HeatCommitted)(nil), "vantage.events.v1.HeatCommitted")
+	proto.RegisterType((*RaceLapAdded)(nil), "vantage.events.v1.RaceLapAdded")
+	proto.RegisterType((*LastPresentedRaceLapChanged)(nil), "vantage.events.v1.LastPresentedRaceLapChanged")
+	proto.RegisterType((*RacePassingAdded)(nil), "vantage.events.v1.RacePassingAdded")
+	proto.RegisterType((*LastRaceSpeedChanged)(nil), "vantage.events.v1.LastRaceSpeedChanged")
+	proto.RegisterType((*NextLapIndexChanged)(nil), "vantage.events.v1.NextLapIndexChanged")
+	proto.RegisterType((*Ticks)(nil), "vantage.events.v1.Ticks")
+	proto.RegisterType((*Competition)(nil), "vantage.events.v1.Competition")
+	proto.RegisterType((*Distance)(nil), "vantage.events.v1.Distance")
+	proto.RegisterType((*HeatKey)(nil), "vantage.events.v1.HeatKey")
+	proto.RegisterType((*HeatRace)(nil), "vantage.events.v1.HeatRace")
+	proto.RegisterType((*Race)(nil), "vantage.events.v1.Race")
+	proto.RegisterType((*Competitor)(nil), "vantage.events.v1.Competitor")
+	proto.RegisterType((*Transponder)(nil), "vantage.events.v1.Transponder")
+	proto.RegisterType((*PresentationSource)(nil), "vantage.events.v1.PresentationSource")
+	proto.RegisterType((*Lap)(nil), "vantage.events.v1.Lap")
+	proto.RegisterType((*PresentedLap)(nil), "vantage.events.v1.PresentedLap")
+	proto.RegisterType((*Passing)(nil), "vantage.events.v1.Passing")
+	proto.RegisterType((*RaceTime)(nil), "vantage.events.v1.RaceTime")
+	proto.RegisterType((*RaceResult)(nil), "vantage.events.v1.RaceResult")
+	proto.RegisterType((*CompetitionState)(nil), "vantage.events.v1.CompetitionState")
+	proto.RegisterType((*DistanceState)(nil), "vantage.events.v1.DistanceState")
+	proto.RegisterType((*HeatState)(nil), "vantage.events.v1.HeatState")
+	proto.RegisterType((*RaceState)(nil), "vantage.events.v1.RaceState")
+}
+
+func init() { proto.RegisterFile("events.proto", fileDescriptor_8f22242cb04491f9) }
+
+var fileDescriptor_8f22242cb04491f9 = []byte{
+	// 2035 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x18, 0x4d, 0x73, 0x1c, 0x47,
+	0xd5, 0xa3, 0xd5, 0x7e, 0xbd, 0xdd, 0x95, 0xe5, 0xb6, 0x63, 0x0d, 0x52, 0x1c, 0x2b, 0xe3, 0x04,
+	0x4c, 0x12, 0xa4, 0xd8, 0x80, 0xe3, 0x14, 0x15, 0x2a, 0x96, 0x4c, 0x2c, 0x15, 0x8a, 0xcb, 0x8c,
+	0x0c, 0x07, 0x0a, 0x58, 0x5a, 0x33, 0xad, 0xdd, 0x29, 0xcd, 0xce, 0x4c, 0xa6, 0x7b, 0x25, 0x8b,
+	0x3b, 0x55, 0x14, 0x47, 0xe0, 0x27, 0x00, 0x3f, 0x84, 0x13, 0x07, 0xaa, 0x38, 0x70, 0xa0, 0xa8,
+	0xe2, 0xc6, 0xdf, 0xe0, 0x40, 0xbd, 0xd7, 0x3d, 0x33, 0x2d, 0x69, 0x76, 0x25, 0x4c, 0xaa, 0xb8,
+	0xf5, 0x7b, 0xfd, 0xde, 0xeb, 0xf7, 0xdd, 0xaf, 0x1b, 0xfa, 0xe2, 0x58, 0x24, 0x4a, 0x6e, 0x64,
+	0x79, 0xaa, 0x52, 0x76, 0xe3, 0x98, 0x27, 0x8a, 0x8f, 0xc4, 0x86, 0xc1, 0x1e, 0x3f, 0x58, 0xbd,
+	0x3b, 0x4a, 0xd3, 0x51, 0x2c, 0x36, 0x89, 0xe0, 0x60, 0x7a, 0xb8, 0xa9, 0xa2, 0x89, 0x90, 0x8a,
+	0x4f, 0x32, 0xcd, 0xe3, 0x7d, 0x0a, 0x2b, 0x7b, 0x91, 0x54, 0xdb, 0xe9, 0x24, 0x13, 0x2a, 0x52,
+	0x51, 0x9a, 0x48, 0x5f, 0x7c, 0x31, 0x15, 0x52, 0xb1, 0x77, 0x61, 0xe9, 0x24, 0x4a, 0xc2, 0xf4,
+	0x64, 0x28, 0x45, 0x90, 0x26, 0xa1, 0x74, 0x9d, 0x75, 0xe7, 0x7e, 0xc3, 0x1f, 0x68, 0xec, 0xbe,
+	0x46, 0x7a, 0xbf, 0x71, 0xc0, 0xdd, 0x57, 0xb9, 0xe0, 0x13, 0x4b, 0x88, 0x25, 0x23, 0xa8, 0xb0,
+	0xc3, 0x28, 0x24, 0x19, 0x5d, 0x7f, 0x60, 0x61, 0x77, 0x43, 0x76, 0x0b, 0x9a, 0xea, 0x34, 0x13,
+	0xd2, 0x5d, 0x58, 0x6f, 0xdc, 0xef, 0xfa, 0x1a, 0x60, 0x6f, 0x43, 0x3f, 0x8c, 0xa4, 0xe2, 0x49,
+	0x20, 0x86, 0x51, 0x28, 0xdd, 0x06, 0x6d, 0xf6, 0x0a, 0xdc, 0x6e, 0x28, 0xd9, 0x6d, 0x68, 0x05,
+	0xd3, 0x5c, 0xa6, 0xb9, 0xbb, 0x48, 0x72, 0x0d, 0xe4, 0x3d, 0x86, 0xeb, 0xcf, 0x84, 0xda, 0x57,
+	0x5c, 0x89, 0xff, 0x4e, 0x15, 0xef, 0x6f, 0x1d, 0x68, 0x7e, 0x0f, 0xfd, 0x67, 0xc9, 0x76, 0x6c,
+	0xd9, 0x6c, 0x0d, 0xba, 0xa8, 0xdf, 0x30, 0xe1, 0x13, 0xe1, 0x2e, 0xd0, 0x56, 0x07, 0x11, 0xcf,
+	0xf9, 0x44, 0xd4, 0x9c, 0xd2, 0xa8, 0x33, 0xf8, 0x2e, 0xf4, 0x2c, 0xd3, 0x8c, 0xf2, 0x50, 0x59,
+	0xc6, 0x36, 0x60, 0x71, 0x2c, 0xb8, 0x72, 0x9b, 0xeb, 0xce, 0xfd, 0xde, 0xc3, 0xd5, 0x8d, 0x0b,
+	0xa1, 0xdd, 0xd8, 0x11, 0x5c, 0x7d, 0x5f, 0x9c, 0xfa, 0x44, 0xc7, 0x56, 0xa0, 0x9d, 0x73, 0x2d,
+	0xac, 0xa5, 0xb5, 0x45, 0x70, 0x37, 0x64, 0xcb, 0xd0, 0xc8, 0xf9, 0x89, 0xdb, 0x5e, 0x77, 0xee,
+	0xf7, 0x7d, 0x5c, 0xb2, 0x9f, 0xc1, 0x1b, 0xb6, 0x8a, 0x3c, 0x50, 0xd1, 0x31, 0x57, 0x22, 0x74,
+	0x81, 0xce, 0xfa, 0x5a, 0xcd, 0x59, 0x56, 0x64, 0x9f, 0x14, 0xe4, 0x3b, 0xd7, 0xfc, 0x5b, 0x41,
+	0x0d, 0x9e, 0xfd, 0x10, 0x58, 0x69, 0x5b, 0x25, 0xbc, 0x47, 0xc2, 0xdf, 0xa9, 0x11, 0xfe, 0xd4,
+	0x10, 0xdb, 0x92, 0x6f, 0x84, 0xe7, 0x91, 0x6c, 0x17, 0x96, 0xd0, 0x52, 0x4b, 0x64, 0x9f, 0x44,
+	0xae, 0xcf, 0xf0, 0x8d, 0x2d, 0x6e, 0x30, 0xb6, 0x11, 0x6c, 0x1b, 0xfa, 0x24, 0x4a, 0x2a, 0x9e,
+	0xa3, 0xa0, 0x01, 0x09, 0x7a, 0x6b, 0x86, 0xa0, 0x7d, 0x4d, 0xb5, 0x73, 0xcd, 0xef, 0x8d, 0x2b,
+	0xb0, 0xd4, 0x27, 0x48, 0x27, 0x93, 0x48, 0xa1, 0x98, 0xa5, 0xb9, 0xfa, 0x6c, 0x17, 0x74, 0x85,
+	0x3e, 0x25, 0x82, 0x3d, 0x83, 0x25, 0x0a, 0x5e, 0xcc, 0xb3, 0x21, 0x0f, 0x43, 0x11, 0xba, 0xd7,
+	0x49, 0xd4, 0xdd, 0x1a, 0x51, 0x3e, 0x0f, 0xc4, 0x1e, 0xcf, 0x9e, 0x20, 0xd9, 0xce, 0x35, 0xbf,
+	0x9f, 0x5b, 0x30, 0x3b, 0x86, 0xbb, 0x31, 0x97, 0x6a, 0x98, 0xe5, 0x42, 0x8a, 0x44, 0x89, 0x70,
+	0x58, 0xca, 0x0d, 0xc6, 0x3c, 0x19, 0x89, 0xd0, 0x5d, 0x26, 0xc9, 0x1b, 0x35, 0x92, 0xf7, 0xb8,
+	0x54, 0x2f, 0x0a, 0x46, 0x73, 0xcc, 0xb6, 0xe6, 0xda, 0xb9, 0xe6, 0xaf, 0xc5, 0xb3, 0xb7, 0xd9,
+	0x3e, 0x30, 0x3a, 0x28, 0xe3, 0x52, 0x46, 0xc9, 0xc8, 0x18, 0x71, 0x83, 0x8e, 0xba, 0x37, 0xc3,
+	0x88, 0x17, 0x9a, 0xb6, 0x30, 0x64, 0x39, 0x3f, 0x87, 0x63, 0x3f, 0x87, 0x15, 0x32, 0x86, 0x24,
+	0xcb, 0x4c, 0x88, 0xb0, 0x34, 0x82, 0xcd, 0xcc, 0x54, 0x34, 0x02, 0xa5, 0xef, 0x23, 0x7d, 0xa5,
+	0xfd, 0xad, 0xb8, 0x06, 0xcf, 0x7e, 0x0a, 0xb7, 0x13, 0xf1, 0x4a, 0x91, 0x7f, 0xa2, 0x24, 0x14,
+	0xaf, 0xca, 0x03, 0x6e, 0xd2, 0x01, 0x5f, 0xad, 0x39, 0xe0, 0xb9, 0x78, 0xa5, 0xf6, 0x78, 0xb6,
+	0x8b, 0xe4, 0x95, 0xfc, 0x9b, 0xc9, 0x45, 0xf4, 0x56, 0x17, 0xda, 0x19, 0x3f, 0x8d, 0x53, 0x1e,
+	0x7a, 0xbf, 0x72, 0xe0, 0x56, 0x5d, 0x11, 0xb1, 0x4f, 0xa1, 0x67, 0x15, 0x91, 0xeb, 0xcc, 0xcc,
+	0x44, 0x8b, 0xdb, 0xb7, 0x59, 0xb0, 0x53, 0xa8, 0xc8, 0x74, 0x22, 0xec, 0x14, 0xba, 0xe3, 0x6f,
+	0x14, 0x1d, 0x7f, 0xe3, 0x65, 0xd1, 0xf1, 0x7d, 0xa2, 0xf3, 0xf6, 0xe0, 0xc6, 0x85, 0x8a, 0x63,
+	0x1f, 0x41, 0xa7, 0xa8, 0x38, 0xa3, 0xc3, 0xda, 0x9c, 0x4a, 0xf5, 0x4b, 0x62, 0x6f, 0x0b, 0x06,
+	0x67, 0x8a, 0x8d, 0x3d, 0x80, 0x26, 0x06, 0x0c, 0x2f, 0x8b, 0xc6, 0x0c, 0x31, 0xc8, 0x80, 0xb1,
+	0xf0, 0x35, 0xa5, 0xb7, 0x0d, 0x3d, 0xab, 0xce, 0xd8, 0xb7, 0xa0, 0x5d, 0x14, 0xa6, 0x73, 0xa9,
+	0x4d, 0x05, 0x69, 0xa1, 0x48, 0x55, 0x54, 0xaf, 0xa1, 0xc8, 0x63, 0xe8, 0xdb, 0xe5, 0xc5, 0xee,
+	0x43, 0x23, 0xe6, 0x99, 0xd1, 0xe2, 0x76, 0x6d, 0xb6, 0x65, 0x3e, 0x92, 0x78, 0xbf, 0x75, 0x60,
+	0x6d, 0x4e, 0xfd, 0xb0, 0x07, 0xb6, 0xa4, 0xba, 0xb2, 0x2e, 0x19, 0x0b, 0x91, 0xec, 0x09, 0x5c,
+	0xc7, 0x78, 0x0d, 0xc3, 0xe8, 0xf0, 0x50, 0xe4, 0x22, 0x09, 0x8a, 0x10, 0xbb, 0x35, 0xec, 0x2f,
+	0xa3, 0xe0, 0x48, 0xfa, 0x4b, 0xc8, 0xf0, 0xb4, 0xa4, 0xf7, 0x76, 0x60, 0xf9, 0x7c, 0xa5, 0xa1,
+	0x77, 0x4d, 0x95, 0x96, 0xde, 0xad, 0xd1, 0x46, 0x53, 0xf8, 0x05, 0xa9, 0xb7, 0x07, 0xb7, 0xea,
+	0x2a, 0xeb, 0x35, 0xa5, 0xfd, 0xda, 0x81, 0x9b, 0x35, 0x75, 0x84, 0x63, 0x00, 0x95, 0x21, 0xc9,
+	0x6a, 0xfa, 0x1a, 0x60, 0xf7, 0x60, 0x80, 0x8c, 0x22, 0x1c, 0xc6, 0x22, 0x19, 0xa9, 0x31, 0xb9,
+	0xc1, 0xf1, 0xfb, 0x1a, 0xb9, 0x47, 0x38, 0xbc, 0xac, 0xf3, 0x74, 0x9a, 0xd0, 0x94, 0x80, 0xbb,
+	0x06, 0x62, 0xeb, 0xd0, 0xd7, 0xab, 0xa1, 0x4a, 0x87, 0xa3, 0x94, 0x6e, 0x5a, 0xc7, 0x07, 0x8d,
+	0x7b, 0x99, 0x3e, 0x4b, 0xbd, 0x3b, 0xd0, 0x24, 0xef, 0xe1, 0xe9, 0xc7, 0x3c, 0x9e, 0x0a, 0x33,
+	0xe6, 0x68, 0xc0, 0xfb, 0x83, 0x03, 0x3d, 0xab, 0xf6, 0xd8, 0x12, 0x2c, 0x94, 0xa3, 0xc3, 0x42,
+	0x14, 0x32, 0x06, 0x8b, 0xd6, 0x20, 0x40, 0x6b, 0xf6, 0x16, 0xe0, 0x55, 0x1e, 0x44, 0x59, 0x1c,
+	0x25, 0xc2, 0x0c, 0x00, 0x16, 0x06, 0x4f, 0x0a, 0x62, 0x2e, 0x25, 0x69, 0xd3, 0xf4, 0x35, 0xc0,
+	0xee, 0x00, 0x1c, 0x8b, 0x64, 0x2a, 0x86, 0x41, 0x1a, 0x0a, 0xba, 0xf8, 0xbb, 0x7e, 0x97, 0x30,
+	0xdb, 0x69, 0x28, 0xaa, 0x6d, 0x3a, 0xae, 0x65, 0x6d, 0xe3, 0xe0, 0xe1, 0xfd, 0x7d, 0x01, 0x3a,
+	0x45, 0x7d, 0x5e, 0x49, 0xc9, 0xdb, 0xd0, 0x4a, 0xa6, 0x93, 0x03, 0x91, 0x93, 0x82, 0x4d, 0xdf,
+	0x40, 0xe7, 0x94, 0x5f, 0xbc, 0xa0, 0xfc, 0x1d, 0x00, 0xaa, 0xb9, 0xe1, 0xa4, 0x50, 0xb3, 0xe9,
+	0x77, 0x09, 0xf3, 0x39, 0xaa, 0xf9, 0x31, 0xb8, 0x64, 0x4e, 0x74, 0x18, 0x05, 0x9c, 0x06, 0x8c,
+	0x2c, 0x17, 0x41, 0x24, 0xb1, 0xbb, 0xb5, 0xc8, 0xb1, 0x2b, 0x67, 0xf7, 0x5f, 0x14, 0xdb, 0x38,
+	0xef, 0xa9, 0x9c, 0x07, 0x47, 0x45, 0x9c, 0xdb, 0x24, 0xbb, 0x47, 0x38, 0x13, 0xe6, 0x32, 0x46,
+	0x1d, 0xed, 0x39, 0x02, 0x70, 0xe8, 0xa2, 0xc5, 0xf0, 0x8b, 0x29, 0x4f, 0x54, 0xa4, 0x4e, 0xdd,
+	0x2e, 0x6d, 0x0f, 0x08, 0xfb, 0x03, 0x83, 0xb4, 0x72, 0x04, 0xb4, 0xc5, 0x1a, 0x42, 0x8b, 0x0e,
+	0xa3, 0x5c, 0xaa, 0x21, 0x4d, 0x5c, 0x3d, 0x6d, 0x11, 0x61, 0xb0, 0x41, 0x78, 0x1f, 0x41, 0xdb,
+	0xcc, 0x5a, 0x78, 0x3c, 0xf1, 0x14, 0x09, 0x4a, 0x80, 0xe5, 0xc9, 0x05, 0xdb, 0x93, 0xde, 0xbf,
+	0x1c, 0xe8, 0x14, 0x2d, 0x86, 0xbd, 0x0f, 0x8b, 0xd8, 0x64, 0x4c, 0x99, 0xac, 0xcc, 0xb8, 0x14,
+	0x7d, 0x22, 0x62, 0x9f, 0xc1, 0x92, 0x90, 0x2a, 0x9a, 0x70, 0xbc, 0xc2, 0x63, 0x9e, 0xe9, 0xc1,
+	0xf8, 0x0a, 0x9d, 0x63, 0x50, 0xb2, 0xed, 0xf1, 0x4c, 0xb2, 0xf7, 0x60, 0x91, 0xb8, 0x1b, 0xeb,
+	0x8d, 0x39, 0x1d, 0x8c, 0x68, 0xd8, 0x23, 0xe8, 0x98, 0xfa, 0xc4, 0xbc, 0x6c, 0x5c, 0x52, 0xcb,
+	0x25, 0xad, 0xf7, 0xd7, 0x06, 0x2c, 0xfa, 0xbc, 0x26, 0xe9, 0x4a, 0x67, 0x2d, 0xd8, 0xce, 0x62,
+	0x66, 0xb0, 0xd5, 0x49, 0x47, 0x6b, 0xc4, 0xc5, 0xdc, 0x24, 0x5b, 0xd3, 0xa7, 0x35, 0xd5, 0x48,
+	0x1a, 0xa7, 0xb9, 0xc9, 0x30, 0x0d, 0xb0, 0x4f, 0x00, 0x8a, 0xbb, 0x2f, 0xcd, 0x29, 0x9f, 0x7a,
+	0x0f, 0xef, 0xcc, 0xb9, 0x2d, 0xd3, 0xdc, 0xb7, 0x18, 0xd8, 0x27, 0x30, 0xc8, 0x44, 0x2e, 0xd3,
+	0x84, 0xc7, 0xc3, 0x03, 0x21, 0x95, 0xdb, 0xbe, 0xa4, 0xa3, 0xf6, 0x0b, 0xf2, 0x2d, 0x21, 0x15,
+	0xfb, 0x18, 0x7a, 0x52, 0x70, 0x99, 0x26, 0x9a, 0xb9, 0x73, 0x09, 0x33, 0x68, 0x62, 0x62, 0xdd,
+	0xa2, 0xdc, 0x4e, 0x64, 0x96, 0x26, 0xa1, 0xc8, 0xa5, 0xdb, 0x5d, 0x6f, 0xcc, 0xb8, 0xe8, 0x5f,
+	0x56, 0x64, 0xfe, 0x19, 0x1e, 0xb6, 0x69, 0x6e, 0x7a, 0x98, 0x79, 0x41, 0x63, 0x1c, 0xf0, 0x6e,
+	0xd4, 0x57, 0x3d, 0xfb, 0x36, 0xb4, 0x72, 0x21, 0xa7, 0xb1, 0x72, 0x7b, 0x33, 0x3d, 0x45, 0x59,
+	0x47, 0x44, 0xbe, 0x21, 0xf6, 0xfe, 0xed, 0x00, 0x54, 0x0e, 0xbc, 0x10, 0xd7, 0xb9, 0xef, 0x9f,
+	0x35, 0xe8, 0x1e, 0x4e, 0xe3, 0x58, 0x6f, 0xea, 0xce, 0xd7, 0x41, 0x04, 0x6d, 0x62, 0xeb, 0x18,
+	0xa7, 0xb9, 0xd2, 0xbb, 0xba, 0xb5, 0x74, 0x09, 0x43, 0xdb, 0x6f, 0x43, 0x5f, 0x77, 0x16, 0x53,
+	0x4d, 0x3a, 0xf2, 0x3d, 0xc2, 0x3d, 0x27, 0x14, 0x5b, 0x85, 0x4e, 0xc0, 0x95, 0x18, 0xa5, 0xf9,
+	0xa9, 0x69, 0x81, 0x25, 0xcc, 0xbe, 0x0e, 0xcb, 0x09, 0x75, 0x14, 0x1e, 0x47, 0xea, 0x54, 0x77,
+	0xd1, 0x36, 0xd1, 0x5c, 0xb7, 0xf0, 0xd4, 0x4b, 0xd7, 0xa0, 0xab, 0x03, 0x8b, 0xef, 0xa5, 0x8e,
+	0x96, 0xa3, 0x11, 0xbb, 0xa1, 0xf7, 0x0b, 0xe8, 0x59, 0x31, 0xc0, 0xe4, 0x24, 0x51, 0xfa, 0x56,
+	0xa0, 0x35, 0x26, 0x67, 0xcc, 0x0f, 0x44, 0x6c, 0xcc, 0xd7, 0xc0, 0x59, 0xa9, 0x8d, 0xb3, 0x52,
+	0xf1, 0x1d, 0x26, 0x85, 0x32, 0x29, 0x8e, 0x4b, 0x14, 0x8c, 0x6e, 0x33, 0x9d, 0x9e, 0xd6, 0xde,
+	0x2f, 0x1d, 0x60, 0xa6, 0xa0, 0x49, 0xe5, 0xfd, 0x74, 0x9a, 0x07, 0x82, 0x3d, 0x82, 0x15, 0x9e,
+	0x65, 0x71, 0xa4, 0xdf, 0x8b, 0x89, 0x79, 0x5c, 0x91, 0x17, 0x75, 0x5c, 0xde, 0x28, 0xb7, 0x77,
+	0xcd, 0x6e, 0xf1, 0x1a, 0xad, 0xf8, 0xac, 0x78, 0x0d, 0x4a, 0x2c, 0x91, 0x2d, 0x43, 0x63, 0x9c,
+	0x9e, 0x18, 0x95, 0x71, 0xe9, 0xfd, 0xd3, 0x81, 0xc6, 0x1e, 0xcf, 0x18, 0x33, 0x29, 0x67, 0x8c,
+	0xc7, 0x35, 0x0e, 0x9c, 0x27, 0x63, 0x91, 0x5c, 0x65, 0xe0, 0x44, 0x3a, 0x74, 0xd6, 0x61, 0xcc,
+	0x47, 0xd2, 0x94, 0xbc, 0x06, 0xf0, 0x56, 0x3f, 0x6b, 0x88, 0x4e, 0x87, 0x7e, 0x64, 0xeb, 0xff,
+	0x23, 0xb8, 0x99, 0x59, 0xde, 0x18, 0x4a, 0x72, 0x87, 0x79, 0x14, 0xbf, 0x3b, 0xbb, 0x19, 0x5a,
+	0xbe, 0xf3, 0x59, 0x76, 0x01, 0xe7, 0xfd, 0xc5, 0x81, 0xbe, 0xdd, 0x37, 0x67, 0x4c, 0x1e, 0xcc,
+	0x1a, 0xad, 0x0b, 0xeb, 0xbf, 0x02, 0x1d, 0x7c, 0x2e, 0x10, 0xbe, 0x41, 0xf8, 0x76, 0xcc, 0x33,
+	0xb4, 0xf9, 0xe2, 0xa0, 0xb2, 0x58, 0x33, 0xa8, 0xb8, 0xf8, 0x50, 0x4f, 0x8e, 0x70, 0x62, 0xd2,
+	0xf9, 0x5d, 0x80, 0xd6, 0xf5, 0xd4, 0x9a, 0x3b, 0xc2, 0xb4, 0x2f, 0x8c, 0x30, 0xbf, 0x5f, 0x80,
+	0xb6, 0x69, 0xcc, 0x5f, 0x56, 0xc4, 0x4e, 0xc6, 0x22, 0x17, 0x45, 0xc4, 0x08, 0x40, 0xfd, 0xb4,
+	0x25, 0xc6, 0x2e, 0x03, 0x21, 0x35, 0xbd, 0xce, 0xc8, 0x1e, 0xc7, 0xd7, 0x40, 0x15, 0xf5, 0xd6,
+	0xdc, 0xa8, 0xb7, 0xaf, 0x1e, 0xf5, 0xce, 0xff, 0x1a, 0xf5, 0xcf, 0xa1, 0x53, 0x34, 0xc8, 0x5a,
+	0x37, 0x61, 0x63, 0xc3, 0x89, 0x3b, 0x4a, 0x0e, 0x53, 0x73, 0x69, 0x75, 0x10, 0xb1, 0x9b, 0x1c,
+	0xa6, 0x35, 0x35, 0xf2, 0x0e, 0x40, 0xd5, 0x3c, 0xd1, 0x3b, 0x52, 0x71, 0x35, 0x95, 0x26, 0x85,
+	0x0c, 0xe4, 0xfd, 0xc9, 0x81, 0x65, 0x6b, 0x7e, 0xa4, 0x2f, 0xa9, 0x2f, 0xe1, 0xd5, 0xf7, 0x01,
+	0x30, 0xfa, 0x08, 0xc1, 0xf7, 0x41, 0xf5, 0x8f, 0xa4, 0xab, 0x7b, 0x59, 0xef, 0x3c, 0xad, 0x7e,
+	0x93, 0xbe, 0x0b, 0xdd, 0x82, 0xac, 0x18, 0x06, 0xd6, 0xe7, 0xbc, 0xef, 0x48, 0x49, 0xbf, 0x62,
+	0xf1, 0x7e, 0xe7, 0xc0, 0xe0, 0xcc, 0xe6, 0x6b, 0x3f, 0x18, 0xd1, 0x4f, 0x5a, 0x3d, 0x52, 0xb6,
+	0xe3, 0x1b, 0x88, 0x3d, 0x84, 0x26, 0xce, 0x02, 0x85, 0x7a, 0x6f, 0xce, 0xfe, 0x8c, 0x51, 0xc2,
+	0xd7, 0xa4, 0xde, 0x3f, 0x1c, 0xe8, 0x96, 0x48, 0xf6, 0x01, 0x34, 0x8e, 0xc4, 0xe9, 0x9c, 0x77,
+	0x48, 0xf1, 0x63, 0x86, 0x64, 0x33, 0xf5, 0xb0, 0x5e, 0x9f, 0x8d, 0x2b, 0xbf, 0x3e, 0xd9, 0x9b,
+	0xd0, 0xad, 0xfe, 0x81, 0x16, 0x49, 0x60, 0x85, 0x40, 0xdb, 0xf4, 0x53, 0xb4, 0x39, 0xd3, 0x36,
+	0x7a, 0x59, 0x69, 0xdb, 0xf4, 0x5b, 0xf4, 0xcf, 0x0e, 0x74, 0x4b, 0xe4, 0xff, 0x67, 0x7a, 0xfc,
+	0x0c, 0x96, 0xaa, 0x8f, 0x24, 0x6b, 0x8e, 0xbc, 0x5c, 0x4e, 0x66, 0x41, 0xf2, 0xe1, 0x1f, 0x17,
+	0xa0, 0x45, 0x5f, 0xaa, 0x92, 0x45, 0xb0, 0x7c, 0xfe, 0xbb, 0x99, 0xbd, 0x57, 0x37, 0x96, 0xd6,
+	0xff, 0x49, 0xaf, 0x5e, 0xf5, 0x73, 0xf2, 0x43, 0x87, 0xfd, 0x04, 0x6e, 0x5c, 0xf8, 0x96, 0x66,
+	0xef, 0xd7, 0xf0, 0xcf, 0xfa, 0xbc, 0x5e, 0xad, 0x9b, 0xec, 0xc8, 0x8e, 0x0f, 0x1d, 0xb6, 0x0f,
+	0x9d, 0xe2, 0x83, 0x99, 0x79, 0x35, 0x74, 0xe7, 0x7e, 0x9f, 0x57, 0xef, 0xcd, 0x57, 0x9c, 0x68,
+	0xb7, 0x1e, 0xff, 0xf8, 0xd1, 0x28, 0x52, 0xe3, 0xe9, 0xc1, 0x46, 0x90, 0x4e, 0x36, 0xc5, 0x84,
+	0x27, 0x61, 0xba, 0x69, 0xf8, 0xbe, 0xa1, 0xf9, 0x36, 0xb3, 0xa3, 0xd1, 0xa6, 0x5e, 0x66, 0x07,
+	0xdf, 0x29, 0x16, 0x07, 0x2d, 0x4a, 0xce, 0x6f, 0xfe, 0x67, 0x00, 0x67, 0x42, 0x99, 0xdb, 0x11,
+	0x18, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// EventsClient is the client API for Events service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type EventsClient interface {
+	// ListCompetitions streams competition activations within the time window.
+	ListCompetitions(ctx context.Context, in *ListCompetitionsRequest, opts ...grpc.CallOption) (Events_ListCompetitionsClient, error)
+	// StreamCompetition streams the events of a competition.
+	StreamCompetition(ctx context.Context, in *StreamCompetitionRequest, opts ...grpc.CallOption) (Events_StreamCompetitionClient, error)
+	// GetState returns a snapshot of the state of a competition.
+	GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*CompetitionState, error)
+}
+
+type eventsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewEventsClient(cc *grpc.ClientConn) EventsClient {
+	return &eventsClient{cc}
+}
+
+func (c *eventsClient) ListCompetitions(ctx context.Context, in *ListCompetitionsRequest, opts ...grpc.CallOption) (Events_ListCompetitionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Events_serviceDesc.Streams[0], "/vantage.events.v1.Events/ListCompetitions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventsListCompetitionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Events_ListCompetitionsClient interface {
+	Recv() (*CompetitionActivated, error)
+	grpc.ClientStream
+}
+
+type eventsListCompetitionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventsListCompetitionsClient) Recv() (*CompetitionActivated, error) {
+	m := new(CompetitionActivated)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventsClient) StreamCompetition(ctx context.Context, in *StreamCompetitionRequest, opts ...grpc.CallOption) (Events_StreamCompetitionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Events_serviceDesc.Streams[1], "/vantage.events.v1.Events/StreamCompetition", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventsStreamCompetitionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Events_StreamCompetitionClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type eventsStreamCompetitionClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventsStreamCompetitionClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventsClient) GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*CompetitionState, error) {
+	out := new(CompetitionState)
+	err := c.cc.Invoke(ctx, "/vantage.events.v1.Events/GetState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EventsServer is the server API for Events service.
+type EventsServer interface {
+	// ListCompetitions streams competition activations within the time window.
+	ListCompetitions(*ListCompetitionsRequest, Events_ListCompetitionsServer) error
+	// StreamCompetition streams the events of a competition.
+	StreamCompetition(*StreamCompetitionRequest, Events_StreamCompetitionServer) error
+	// GetState returns a snapshot of the state of a competition.
+	GetState(context.Context, *GetStateRequest) (*CompetitionState, error)
+}
+
+// UnimplementedEventsServer can be embedded to have forward compatible implementations.
+type UnimplementedEventsServer struct {
+}
+
+func (*UnimplementedEventsServer) ListCompetitions(req *ListCompetitionsRequest, srv Events_ListCompetitionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListCompetitions not implemented")
+}
+func (*UnimplementedEventsServer) StreamCompetition(req *StreamCompetitionRequest, srv Events_StreamCompetitionServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamCompetition not implemented")
+}
+func (*UnimplementedEventsServer) GetState(ctx context.Context, req *GetStateRequest) (*CompetitionState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetState not implemented")
+}
+
+func RegisterEventsServer(s *grpc.Server, srv EventsServer) {
+	s.RegisterService(&_Events_serviceDesc, srv)
+}
+
+func _Events_ListCompetitions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListCompetitionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventsServer).ListCompetitions(m, &eventsListCompetitionsServer{stream})
+}
+
+type Events_ListCompetitionsServer interface {
+	Send(*CompetitionActivated) error
+	grpc.ServerStream
+}
+
+type eventsListCompetitionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventsListCompetitionsServer) Send(m *CompetitionActivated) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Events_StreamCompetition_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamCompetitionRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventsServer).StreamCompetition(m, &eventsStreamCompetitionServer{stream})
+}
+
+type Events_StreamCompetitionServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type eventsStreamCompetitionServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventsStreamCompetitionServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Events_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventsServer).GetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vantage.events.v1.Events/GetState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventsServer).GetState(ctx, req.(*GetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Events_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "vantage.events.v1.Events",
+	HandlerType: (*EventsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetState",
+			Handler:    _Events_GetState_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListCompetitions",
+			Handler:       _Events_ListCompetitions_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamCompetition",
+			Handler:       _Events_StreamCompetition_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "events.proto",
+}
@@ -0,0 +1,189 @@
+// Copyright © 2020 Emando B.V.
+
+// Package leaderboard computes the running standings of competition distances.
+package leaderboard
+
+import (
+	"sort"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/results"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// Leaderboard is the running standings of a distance.
+type Leaderboard struct {
+	CompetitionID  string `json:"competitionId"`
+	DistanceID     string `json:"distanceId"`
+	DistanceName   string `json:"distanceName"`
+	DistanceNumber int    `json:"distanceNumber"`
+	// Standings are the committed races, ranked by time. Races that are not classified follow the ranked races.
+	Standings []Standing `json:"standings"`
+	// Provisional are the races in progress with their position at the last passed length.
+	Provisional []Provisional `json:"provisional"`
+}
+
+// Competitor is a competitor in the leaderboard.
+type Competitor struct {
+	RaceID      string `json:"raceId"`
+	Round       int    `json:"round"`
+	Pair        int    `json:"pair"`
+	Lane        int    `json:"lane"`
+	Name        string `json:"name"`
+	StartNumber int    `json:"startNumber"`
+	Nationality string `json:"nationality"`
+}
+
+// Standing is a committed race in the leaderboard.
+type Standing struct {
+	Competitor
+	// Ranking is shared by times that are equal after truncating to the classification precision.
+	Ranking int          `json:"ranking"`
+	Time    results.Time `json:"time"`
+	// Behind is the difference to the leader, or nil for the leader.
+	Behind *results.Time `json:"behind"`
+	// PersonalBest and SeasonBest are the bests of the competitor before the race, if known.
+	PersonalBest *results.Time `json:"personalBest"`
+	SeasonBest   *results.Time `json:"seasonBest"`
+	// NewPersonalBest and NewSeasonBest are set if the time improves the known personal or season best.
+	NewPersonalBest bool `json:"newPersonalBest"`
+	NewSeasonBest   bool `json:"newSeasonBest"`
+	// Status is the result status of races that are not classified, i.e. disqualified races, which have no ranking.
+	Status int `json:"status,omitempty"`
+}
+
+// Provisional is a race in progress in the leaderboard.
+type Provisional struct {
+	Competitor
+	PassedLength float64      `json:"passedLength"`
+	Time         results.Time `json:"time"`
+	// Position is the position among the committed races at the passed length.
+	Position int `json:"position"`
+	// Behind is the difference to the best committed time at the passed length, or nil if there is none.
+	Behind *results.Time `json:"behind"`
+}
+
+func competitorOf(h *state.Heat, r *state.Race) Competitor {
+	return Competitor{
+		RaceID:      r.ID,
+		Round:       h.Key.Round,
+		Pair:        h.Key.Number,
+		Lane:        r.Lane,
+		Name:        r.Competitor.FullName,
+		StartNumber: r.Competitor.StartNumber,
+		Nationality: r.Competitor.NationalityCode,
+	}
+}
+
+func timeOf(t, precision entities.Ticks) results.Time {
+	return results.Time{Ticks: t, Text: results.FormatTime(t, precision)}
+}
+
+func timePtr(t *entities.Ticks, precision entities.Ticks) *results.Time {
+	if t == nil {
+		return nil
+	}
+	res := timeOf(*t, precision)
+	return &res
+}
+
+// FromDistance returns the leaderboard of the distance.
+func FromDistance(c *state.Competition, d *state.Distance) *Leaderboard {
+	precision := d.ClassificationPrecision
+	lb := &Leaderboard{
+		CompetitionID:  c.ID,
+		DistanceID:     d.ID,
+		DistanceName:   d.Name,
+		DistanceNumber: d.Number,
+		Standings:      []Standing{},
+		Provisional:    []Provisional{},
+	}
+	var committed []*state.Race
+	for _, h := range d.Heats {
+		for _, r := range h.Races {
+			if !h.Committed || r.Time == nil || r.Time.Time <= 0 {
+				continue
+			}
+			s := Standing{
+				Competitor:   competitorOf(h, r),
+				Time:         timeOf(r.Time.Time, precision),
+				PersonalBest: timePtr(r.PersonalBest, precision),
+				SeasonBest:   timePtr(r.SeasonBest, precision),
+			}
+			if !r.Classified() {
+				s.Status = r.Result.Status
+				lb.Standings = append(lb.Standings, s)
+				continue
+			}
+			committed = append(committed, r)
+			t := results.Truncate(r.Time.Time, precision)
+			s.NewPersonalBest = r.PersonalBest != nil && t < results.Truncate(*r.PersonalBest, precision)
+			s.NewSeasonBest = r.SeasonBest != nil && t < results.Truncate(*r.SeasonBest, precision)
+			lb.Standings = append(lb.Standings, s)
+		}
+	}
+	sort.SliceStable(lb.Standings, func(i, j int) bool {
+		a, b := lb.Standings[i], lb.Standings[j]
+		if (a.Status == 0) != (b.Status == 0) {
+			return a.Status == 0
+		}
+		return results.Truncate(a.Time.Ticks, precision) < results.Truncate(b.Time.Ticks, precision)
+	})
+	for i := range lb.Standings {
+		s := &lb.Standings[i]
+		if s.Status != 0 {
+			break
+		}
+		t := results.Truncate(s.Time.Ticks, precision)
+		if i == 0 {
+			s.Ranking = 1
+			continue
+		}
+		if t == results.Truncate(lb.Standings[i-1].Time.Ticks, precision) {
+			s.Ranking = lb.Standings[i-1].Ranking
+		} else {
+			s.Ranking = i + 1
+		}
+		behind := timeOf(t-results.Truncate(lb.Standings[0].Time.Ticks, precision), precision)
+		s.Behind = &behind
+	}
+
+	for _, h := range d.Heats {
+		if h.Committed || !h.Active {
+			continue
+		}
+		for _, r := range h.Races {
+			if len(r.PresentedLaps) == 0 {
+				continue
+			}
+			lap := r.PresentedLaps[len(r.PresentedLaps)-1]
+			t := results.Truncate(lap.Time, precision)
+			p := Provisional{
+				Competitor:   competitorOf(h, r),
+				PassedLength: lap.PassedLength,
+				Time:         timeOf(lap.Time, precision),
+				Position:     1,
+			}
+			var best *entities.Ticks
+			for _, o := range committed {
+				ot, ok := state.TimeAt(o.PresentedLaps, lap.PassedLength)
+				if !ok {
+					continue
+				}
+				ot = results.Truncate(ot, precision)
+				if ot < t {
+					p.Position++
+				}
+				if best == nil || ot < *best {
+					best = &ot
+				}
+			}
+			if best != nil {
+				behind := timeOf(t-*best, precision)
+				p.Behind = &behind
+			}
+			lb.Provisional = append(lb.Provisional, p)
+		}
+	}
+	return lb
+}
@@ -0,0 +1,136 @@
+// Copyright © 2020 Emando B.V.
+
+package leaderboard
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// race returns a race with the final time, or without a final time if it is zero, and the result status.
+func race(id string, t entities.Ticks, status int) *state.Race {
+	r := &state.Race{Race: entities.Race{ID: id}}
+	if t > 0 {
+		r.Time = &entities.RaceTime{Time: t}
+	}
+	if status != 0 {
+		r.Result = &entities.RaceResult{Status: status}
+	}
+	return r
+}
+
+func TestStandings(t *testing.T) {
+	type standing struct {
+		id      string
+		ranking int
+		behind  entities.Ticks
+		status  int
+	}
+	for _, tc := range []struct {
+		name      string
+		races     []*state.Race
+		precision entities.Ticks
+		expected  []standing
+	}{
+		{
+			name:     "Time",
+			races:    []*state.Race{race("a", 800, 0), race("b", 700, 0), race("c", 900, 0)},
+			expected: []standing{{id: "b", ranking: 1}, {id: "a", ranking: 2, behind: 100}, {id: "c", ranking: 3, behind: 200}},
+		},
+		{
+			name:      "TieAfterTruncate",
+			races:     []*state.Race{race("a", 709, 0), race("b", 701, 0), race("c", 710, 0)},
+			precision: 10,
+			expected:  []standing{{id: "a", ranking: 1}, {id: "b", ranking: 1}, {id: "c", ranking: 3, behind: 10}},
+		},
+		{
+			name:     "NoTime",
+			races:    []*state.Race{race("a", 0, 0), race("b", 700, 0)},
+			expected: []standing{{id: "b", ranking: 1}},
+		},
+		{
+			name:     "Status",
+			races:    []*state.Race{race("a", 600, 1), race("b", 700, 0), race("c", 800, 0)},
+			expected: []standing{{id: "b", ranking: 1}, {id: "c", ranking: 2, behind: 100}, {id: "a", status: 1}},
+		},
+		{
+			name:     "OnlyStatus",
+			races:    []*state.Race{race("a", 800, 2), race("b", 700, 1)},
+			expected: []standing{{id: "b", status: 1}, {id: "a", status: 2}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &state.Distance{
+				Distance: entities.Distance{ClassificationPrecision: tc.precision},
+				Heats:    []*state.Heat{{Committed: true, Races: tc.races}},
+			}
+			lb := FromDistance(&state.Competition{}, d)
+			var res []standing
+			for _, s := range lb.Standings {
+				st := standing{id: s.RaceID, ranking: s.Ranking, status: s.Status}
+				if s.Behind != nil {
+					st.behind = s.Behind.Ticks
+				}
+				res = append(res, st)
+			}
+			if !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("standings are %+v, want %+v", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestProvisional(t *testing.T) {
+	lap := func(length float64, t entities.Ticks) []entities.PresentedLap {
+		return []entities.PresentedLap{{PassedLength: length, Time: t}}
+	}
+	committed := func(id string, t entities.Ticks, status int) *state.Race {
+		r := race(id, t, status)
+		r.PresentedLaps = lap(200, t/5)
+		return r
+	}
+	for _, tc := range []struct {
+		name      string
+		committed []*state.Race
+		time      entities.Ticks
+		position  int
+		behind    *entities.Ticks
+	}{
+		{name: "NoCommitted", time: 150, position: 1},
+		{name: "Leading", committed: []*state.Race{committed("a", 800, 0)}, time: 150, position: 1, behind: ticks(-10)},
+		{name: "Behind", committed: []*state.Race{committed("a", 700, 0), committed("b", 800, 0)}, time: 150, position: 2, behind: ticks(10)},
+		{name: "Status", committed: []*state.Race{committed("a", 700, 1), committed("b", 800, 0)}, time: 150, position: 1, behind: ticks(-10)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			racing := race("r", 0, 0)
+			racing.PresentedLaps = lap(200, tc.time)
+			d := &state.Distance{
+				Heats: []*state.Heat{
+					{Key: state.HeatKey{Round: 1, Number: 1}, Committed: true, Races: tc.committed},
+					{Key: state.HeatKey{Round: 1, Number: 2}, Active: true, Races: []*state.Race{racing}},
+				},
+			}
+			lb := FromDistance(&state.Competition{}, d)
+			if len(lb.Provisional) != 1 {
+				t.Fatalf("provisional is %+v, want 1 race", lb.Provisional)
+			}
+			p := lb.Provisional[0]
+			if p.Position != tc.position {
+				t.Errorf("position is %d, want %d", p.Position, tc.position)
+			}
+			switch {
+			case tc.behind == nil && p.Behind != nil:
+				t.Errorf("behind is %d, want none", p.Behind.Ticks)
+			case tc.behind != nil && (p.Behind == nil || p.Behind.Ticks != *tc.behind):
+				t.Errorf("behind is %+v, want %d", p.Behind, *tc.behind)
+			}
+		})
+	}
+}
+
+func ticks(t entities.Ticks) *entities.Ticks {
+	return &t
+}
@@ -0,0 +1,272 @@
+// Copyright © 2020 Emando B.V.
+
+// Package odf maps competition state to XML documents in the style of the Olympic Data Feed.
+package odf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/results"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// Document types.
+const (
+	StartList = "DT_START_LIST"
+	Result    = "DT_RESULT"
+)
+
+// Result statuses.
+const (
+	StatusStartList    = "START_LIST"
+	StatusIntermediate = "INTERMEDIATE"
+	StatusUnofficial   = "UNOFFICIAL"
+)
+
+// Document is an ODF body.
+type Document struct {
+	XMLName         xml.Name `xml:"OdfBody"`
+	CompetitionCode string   `xml:"CompetitionCode,attr"`
+	DocumentCode    string   `xml:"DocumentCode,attr"`
+	DocumentType    string   `xml:"DocumentType,attr"`
+	ResultStatus    string   `xml:"ResultStatus,attr"`
+	Version         int64    `xml:"Version,attr"`
+	FeedFlag        string   `xml:"FeedFlag,attr"`
+	Date            string   `xml:"Date,attr"`
+	Time            string   `xml:"Time,attr"`
+	Source          string   `xml:"Source,attr"`
+
+	Competition struct {
+		Name      string `xml:"Name,attr"`
+		EventUnit struct {
+			Code   string `xml:"Code,attr"`
+			Name   string `xml:"Name,attr"`
+			Round  int    `xml:"Round,attr,omitempty"`
+			Heat   int    `xml:"Heat,attr,omitempty"`
+			Length int    `xml:"Length,attr,omitempty"`
+		} `xml:"EventUnit"`
+		Results []ResultElement `xml:"Result"`
+	} `xml:"Competition"`
+}
+
+// ResultElement is a result of a competitor.
+type ResultElement struct {
+	Rank            int              `xml:"Rank,attr,omitempty"`
+	SortOrder       int              `xml:"SortOrder,attr"`
+	StartOrder      int              `xml:"StartOrder,attr,omitempty"`
+	Bib             int              `xml:"Bib,attr,omitempty"`
+	Lane            string           `xml:"Lane,attr,omitempty"`
+	ResultType      string           `xml:"ResultType,attr,omitempty"`
+	Result          string           `xml:"Result,attr,omitempty"`
+	Competitor      Competitor       `xml:"Competitor"`
+	ExtendedResults *ExtendedResults `xml:"ExtendedResults"`
+}
+
+// ExtendedResults are the intermediate times of a competitor.
+type ExtendedResults struct {
+	Results []ExtendedResult `xml:"ExtendedResult"`
+}
+
+// Competitor is a competitor in a result.
+type Competitor struct {
+	Code         string `xml:"Code,attr"`
+	Type         string `xml:"Type,attr"`
+	Organisation string `xml:"Organisation,attr,omitempty"`
+	Name         string `xml:"Description>Name,omitempty"`
+}
+
+// ExtendedResult is an intermediate time at a passed length.
+type ExtendedResult struct {
+	Type  string `xml:"Type,attr"`
+	Code  string `xml:"Code,attr"`
+	Pos   string `xml:"Pos,attr"`
+	Value string `xml:"Value,attr"`
+	Rank  int    `xml:"Rank,attr,omitempty"`
+}
+
+// version returns the version of a document created at the given time, so that later documents supersede earlier
+// documents.
+func version(now time.Time) int64 {
+	return now.UnixNano() / int64(time.Millisecond)
+}
+
+func newDocument(c *state.Competition, d *state.Distance, now time.Time) *Document {
+	doc := &Document{
+		CompetitionCode: c.ID,
+		Version:         version(now),
+		FeedFlag:        "P",
+		Date:            now.Format("2006-01-02"),
+		Time:            fmt.Sprintf("%s%03d", now.Format("150405"), now.Nanosecond()/int(time.Millisecond)),
+		Source:          "VANTAGE",
+	}
+	doc.Competition.Name = c.Name
+	doc.Competition.EventUnit.Name = d.Name
+	if d.ValueQuantity == 0 {
+		doc.Competition.EventUnit.Length = d.Value
+	}
+	return doc
+}
+
+func competitorType(typeName string) string {
+	if typeName == "TeamCompetitor" {
+		return "T"
+	}
+	return "A"
+}
+
+func laneName(lane int) string {
+	switch lane {
+	case 0:
+		return "I"
+	case 1:
+		return "O"
+	default:
+		return strconv.Itoa(lane)
+	}
+}
+
+// FromHeat returns the document of the heat. This is a start list if the heat did not start, an intermediate result
+// if the heat is in progress and an unofficial result if the heat is committed.
+func FromHeat(c *state.Competition, d *state.Distance, h *state.Heat, now time.Time) *Document {
+	doc := newDocument(c, d, now)
+	doc.DocumentCode = fmt.Sprintf("%02d-%d-%02d", d.Number, h.Key.Round, h.Key.Number)
+	doc.Competition.EventUnit.Code = doc.DocumentCode
+	doc.Competition.EventUnit.Round = h.Key.Round
+	doc.Competition.EventUnit.Heat = h.Key.Number
+
+	table := results.FromDistance(c, d)
+	var started bool
+	for i, race := range h.Races {
+		elem := ResultElement{
+			SortOrder:  i + 1,
+			StartOrder: i + 1,
+			Bib:        race.Competitor.StartNumber,
+			Lane:       laneName(race.Lane),
+			Competitor: Competitor{
+				Code:         race.Competitor.ID,
+				Type:         competitorType(race.Competitor.Type),
+				Organisation: race.Competitor.NationalityCode,
+				Name:         race.Competitor.FullName,
+			},
+		}
+		for _, row := range table.Rows {
+			if row.RaceID != race.ID {
+				continue
+			}
+			for _, split := range row.Splits {
+				if elem.ExtendedResults == nil {
+					elem.ExtendedResults = &ExtendedResults{}
+				}
+				elem.ExtendedResults.Results = append(elem.ExtendedResults.Results, ExtendedResult{
+					Type:  "ER",
+					Code:  "INTERMEDIATE",
+					Pos:   strconv.FormatFloat(split.Length, 'f', -1, 64),
+					Value: split.Time.Text,
+				})
+				started = true
+			}
+			if row.Time != nil {
+				elem.Rank = row.Ranking
+				elem.ResultType = "TIME"
+				elem.Result = row.Time.Text
+			}
+		}
+		doc.Competition.Results = append(doc.Competition.Results, elem)
+	}
+	switch {
+	case h.Committed:
+		doc.DocumentType, doc.ResultStatus = Result, StatusUnofficial
+	case started || !h.Started.IsZero():
+		doc.DocumentType, doc.ResultStatus = Result, StatusIntermediate
+	default:
+		doc.DocumentType, doc.ResultStatus = StartList, StatusStartList
+	}
+	return doc
+}
+
+// FromDistance returns the result document of the distance with the ranking of committed races.
+func FromDistance(c *state.Competition, d *state.Distance, now time.Time) *Document {
+	doc := newDocument(c, d, now)
+	doc.DocumentCode = fmt.Sprintf("%02d", d.Number)
+	doc.DocumentType, doc.ResultStatus = Result, StatusIntermediate
+	doc.Competition.EventUnit.Code = doc.DocumentCode
+
+	races := make(map[string]*state.Race)
+	for _, h := range d.Heats {
+		for _, r := range h.Races {
+			races[r.ID] = r
+		}
+	}
+	table := results.FromDistance(c, d)
+	for i, row := range table.Rows {
+		if row.Time == nil {
+			break
+		}
+		race := races[row.RaceID]
+		doc.Competition.Results = append(doc.Competition.Results, ResultElement{
+			Rank:       row.Ranking,
+			SortOrder:  i + 1,
+			Bib:        row.StartNumber,
+			ResultType: "TIME",
+			Result:     row.Time.Text,
+			Competitor: Competitor{
+				Code:         race.Competitor.ID,
+				Type:         competitorType(race.Competitor.Type),
+				Organisation: row.Nationality,
+				Name:         row.Competitor,
+			},
+		})
+	}
+	return doc
+}
+
+// Write writes the document as XML.
+func (d *Document) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// FileName returns a unique file name of the document.
+func (d *Document) FileName() string {
+	return fmt.Sprintf("%s_%s_%s_%s_%d.xml", d.CompetitionCode, d.DocumentCode, d.DocumentType, d.ResultStatus, d.Version)
+}
+
+// WriteFile writes the document to the directory. The file appears atomically in the directory.
+func (d *Document) WriteFile(dir string) (string, error) {
+	tmp, err := ioutil.TempFile(dir, ".odf-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := d.Write(tmp); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	name := filepath.Join(dir, d.FileName())
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
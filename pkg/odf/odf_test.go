@@ -0,0 +1,205 @@
+// Copyright © 2020 Emando B.V.
+
+package odf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+var now = time.Date(2020, 1, 12, 10, 30, 15, 250000000, time.UTC)
+
+// race returns a race in the lane with the presented laps at each 100 meters and the final time if it is not zero.
+func race(id string, lane int, final entities.Ticks, laps ...entities.Ticks) *state.Race {
+	r := &state.Race{Race: entities.Race{
+		ID:   id,
+		Lane: lane,
+		Competitor: entities.Competitor{
+			ID:              "p-" + id,
+			FullName:        "Skater " + id,
+			StartNumber:     lane + 1,
+			NationalityCode: "NED",
+		},
+	}}
+	for i, t := range laps {
+		r.PresentedLaps = append(r.PresentedLaps, entities.PresentedLap{Index: i, PassedLength: float64(100 * (i + 1)), Time: t})
+	}
+	if final > 0 {
+		r.Time = &entities.RaceTime{Time: final}
+	}
+	return r
+}
+
+func competition(heats ...*state.Heat) (*state.Competition, *state.Distance) {
+	d := &state.Distance{
+		Distance: entities.Distance{ID: "d1", Name: "Men 500 meter", Number: 3, Value: 500, ClassificationPrecision: 100000},
+		Heats:    heats,
+	}
+	c := &state.Competition{
+		Competition: entities.Competition{ID: "c1", Name: "EC Single Distances"},
+		Distances:   map[string]*state.Distance{d.ID: d},
+	}
+	return c, d
+}
+
+func TestFromHeat(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		heat         *state.Heat
+		documentType string
+		status       string
+		ranks        []int
+		splits       []int
+	}{
+		{
+			name:         "StartList",
+			heat:         &state.Heat{Races: []*state.Race{race("a", 0, 0), race("b", 1, 0)}},
+			documentType: StartList,
+			status:       StatusStartList,
+			ranks:        []int{0, 0},
+			splits:       []int{0, 0},
+		},
+		{
+			name:         "Started",
+			heat:         &state.Heat{Started: now, Races: []*state.Race{race("a", 0, 0), race("b", 1, 0)}},
+			documentType: Result,
+			status:       StatusIntermediate,
+			ranks:        []int{0, 0},
+			splits:       []int{0, 0},
+		},
+		{
+			name:         "Intermediate",
+			heat:         &state.Heat{Races: []*state.Race{race("a", 0, 0, 96000000), race("b", 1, 0)}},
+			documentType: Result,
+			status:       StatusIntermediate,
+			ranks:        []int{0, 0},
+			splits:       []int{1, 0},
+		},
+		{
+			name: "Unofficial",
+			heat: &state.Heat{Committed: true, Races: []*state.Race{
+				race("a", 0, 352000000, 96000000, 352000000),
+				race("b", 1, 348000000, 95000000, 348000000),
+			}},
+			documentType: Result,
+			status:       StatusUnofficial,
+			ranks:        []int{2, 1},
+			splits:       []int{2, 2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.heat.Key = state.HeatKey{Round: 1, Number: 4}
+			c, d := competition(tc.heat)
+			doc := FromHeat(c, d, tc.heat, now)
+			if doc.DocumentCode != "03-1-04" || doc.Competition.EventUnit.Length != 500 {
+				t.Errorf("document code is %q and length is %d", doc.DocumentCode, doc.Competition.EventUnit.Length)
+			}
+			if doc.DocumentType != tc.documentType || doc.ResultStatus != tc.status {
+				t.Errorf("document is %s %s, want %s %s", doc.DocumentType, doc.ResultStatus, tc.documentType, tc.status)
+			}
+			var ranks, splits []int
+			for _, res := range doc.Competition.Results {
+				ranks = append(ranks, res.Rank)
+				if res.ExtendedResults == nil {
+					splits = append(splits, 0)
+				} else {
+					splits = append(splits, len(res.ExtendedResults.Results))
+				}
+			}
+			if !reflect.DeepEqual(ranks, tc.ranks) || !reflect.DeepEqual(splits, tc.splits) {
+				t.Errorf("ranks are %v with splits %v, want %v with %v", ranks, splits, tc.ranks, tc.splits)
+			}
+		})
+	}
+}
+
+func TestFromDistance(t *testing.T) {
+	c, d := competition(
+		&state.Heat{Key: state.HeatKey{Round: 1, Number: 1}, Committed: true, Races: []*state.Race{
+			race("a", 0, 352000000),
+			race("b", 1, 348000000),
+		}},
+		&state.Heat{Key: state.HeatKey{Round: 1, Number: 2}, Committed: true, Races: []*state.Race{
+			race("c", 0, 348090000),
+		}},
+		&state.Heat{Key: state.HeatKey{Round: 1, Number: 3}, Active: true, Races: []*state.Race{
+			race("d", 0, 0, 96000000),
+		}},
+	)
+	doc := FromDistance(c, d, now)
+	if doc.DocumentCode != "03" || doc.DocumentType != Result || doc.ResultStatus != StatusIntermediate {
+		t.Errorf("document is %s %s %s", doc.DocumentCode, doc.DocumentType, doc.ResultStatus)
+	}
+	type result struct {
+		code, result string
+		rank         int
+	}
+	var res []result
+	for _, r := range doc.Competition.Results {
+		res = append(res, result{code: r.Competitor.Code, result: r.Result, rank: r.Rank})
+	}
+	// Times that are equal after truncating to hundredths share the rank.
+	expected := []result{{"p-b", "34.80", 1}, {"p-c", "34.80", 1}, {"p-a", "35.20", 3}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("results are %+v, want %+v", res, expected)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	heat := &state.Heat{Key: state.HeatKey{Round: 1, Number: 4}, Committed: true, Races: []*state.Race{
+		race("a", 0, 352000000, 96000000, 352000000),
+	}}
+	c, d := competition(heat)
+	doc := FromHeat(c, d, heat, now)
+	if doc.Date != "2020-01-12" || doc.Time != "103015250" {
+		t.Errorf("date and time are %s %s", doc.Date, doc.Time)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte(xml.Header)) {
+		t.Error("document has no XML header")
+	}
+	var decoded Document
+	if err := xml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	decoded.XMLName = doc.XMLName
+	if !reflect.DeepEqual(&decoded, doc) {
+		t.Errorf("decoded document is %+v, want %+v", decoded, *doc)
+	}
+
+	dir, err := ioutil.TempDir("", "odf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name, err := doc.WriteFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(dir, "c1_03-1-04_DT_RESULT_UNOFFICIAL_1578825015250.xml"); name != expected {
+		t.Errorf("file is %s, want %s", name, expected)
+	}
+	written, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, buf.Bytes()) {
+		t.Error("written file differs from the document")
+	}
+	if infos, _ := ioutil.ReadDir(dir); len(infos) != 1 {
+		t.Errorf("%d files in the directory, want 1", len(infos))
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright © 2020 Emando B.V.
+
+// Package results builds results tables of competition distances.
+package results
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// Schema is the identifier of the JSON schema of results tables.
+const Schema = "vantage-results/1"
+
+// Table is the results table of a distance.
+type Table struct {
+	Schema          string    `json:"schema"`
+	CompetitionID   string    `json:"competitionId"`
+	CompetitionName string    `json:"competitionName"`
+	DistanceID      string    `json:"distanceId"`
+	DistanceName    string    `json:"distanceName"`
+	DistanceNumber  int       `json:"distanceNumber"`
+	Splits          []float64 `json:"splits"`
+	Rows            []Row     `json:"rows"`
+}
+
+// Row is a result of a race.
+type Row struct {
+	Ranking     int     `json:"ranking,omitempty"`
+	Round       int     `json:"round"`
+	Pair        int     `json:"pair"`
+	Lane        int     `json:"lane"`
+	RaceID      string  `json:"raceId"`
+	Competitor  string  `json:"competitor"`
+	StartNumber int     `json:"startNumber"`
+	Nationality string  `json:"nationality"`
+	Splits      []Split `json:"splits"`
+	Time        *Time   `json:"time,omitempty"`
+	// Points are the sprint points in mass start distances.
+	Points *int `json:"points,omitempty"`
+}
+
+// Split is the time at a passed length.
+type Split struct {
+	Length float64 `json:"length"`
+	Time   Time    `json:"time"`
+}
+
+// Time is a formatted time.
+type Time struct {
+	Ticks entities.Ticks `json:"ticks"`
+	Text  string         `json:"text"`
+}
+
+// FromCompetition returns the results tables of the distances in the competition, ordered by distance number.
+func FromCompetition(c *state.Competition) []*Table {
+	tables := make([]*Table, 0, len(c.Distances))
+	for _, d := range c.Distances {
+		tables = append(tables, FromDistance(c, d))
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].DistanceNumber < tables[j].DistanceNumber
+	})
+	return tables
+}
+
+// FromDistance returns the results table of the distance.
+// Rows are ordered by ranking; races without final time are ordered by round, pair and lane after ranked races.
+// In mass start distances, rows are ordered by round and heat, and by the mass start standings of committed heats.
+func FromDistance(c *state.Competition, d *state.Distance) *Table {
+	t := &Table{
+		Schema:          Schema,
+		CompetitionID:   c.ID,
+		CompetitionName: c.Name,
+		DistanceID:      d.ID,
+		DistanceName:    d.Name,
+		DistanceNumber:  d.Number,
+		Splits:          []float64{},
+		Rows:            []Row{},
+	}
+	lengths := make(map[float64]struct{})
+	for _, h := range d.Heats {
+		for _, r := range h.Races {
+			row := Row{
+				Round:       h.Key.Round,
+				Pair:        h.Key.Number,
+				Lane:        r.Lane,
+				RaceID:      r.ID,
+				Competitor:  r.Competitor.FullName,
+				StartNumber: r.Competitor.StartNumber,
+				Nationality: r.Competitor.NationalityCode,
+				Splits:      []Split{},
+			}
+			for _, lap := range r.PresentedLaps {
+				row.Splits = append(row.Splits, Split{
+					Length: lap.PassedLength,
+					Time:   Time{Ticks: lap.Time, Text: FormatTime(lap.Time, d.ClassificationPrecision)},
+				})
+				lengths[lap.PassedLength] = struct{}{}
+			}
+			if h.Committed && r.Time != nil {
+				row.Time = &Time{
+					Ticks: r.Time.Time,
+					Text:  FormatTime(r.Time.Time, d.ClassificationPrecision),
+				}
+			}
+			t.Rows = append(t.Rows, row)
+		}
+	}
+	for length := range lengths {
+		t.Splits = append(t.Splits, length)
+	}
+	sort.Float64s(t.Splits)
+	if d.MassStart() {
+		rankMassStart(t.Rows, d)
+	} else {
+		rank(t.Rows, d.ClassificationPrecision)
+	}
+	return t
+}
+
+// rankMassStart sorts the rows and sets the points and the ranking of the races in committed heats of the mass start
+// distance.
+func rankMassStart(rows []Row, d *state.Distance) {
+	standings := make(map[string]state.MassStartStanding)
+	for _, h := range d.Heats {
+		for _, s := range h.MassStartStandings(d.ClassificationPrecision) {
+			if !h.Committed {
+				s.Ranking = 0
+			}
+			standings[s.Race.ID] = s
+		}
+	}
+	for i := range rows {
+		s := standings[rows[i].RaceID]
+		points := s.Points
+		rows[i].Points = &points
+		rows[i].Ranking = s.Ranking
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch {
+		case a.Round != b.Round:
+			return a.Round < b.Round
+		case a.Pair != b.Pair:
+			return a.Pair < b.Pair
+		case a.Ranking != b.Ranking:
+			return a.Ranking != 0 && (b.Ranking == 0 || a.Ranking < b.Ranking)
+		default:
+			return a.Lane < b.Lane
+		}
+	})
+}
+
+// rank sorts the rows and sets the ranking of rows with a final time.
+// Times that are equal after truncating to the precision share the ranking.
+func rank(rows []Row, precision entities.Ticks) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch {
+		case a.Time != nil && b.Time != nil:
+			return Truncate(a.Time.Ticks, precision) < Truncate(b.Time.Ticks, precision)
+		case a.Time != nil:
+			return true
+		case b.Time != nil:
+			return false
+		case a.Round != b.Round:
+			return a.Round < b.Round
+		case a.Pair != b.Pair:
+			return a.Pair < b.Pair
+		default:
+			return a.Lane < b.Lane
+		}
+	})
+	for i := range rows {
+		if rows[i].Time == nil {
+			break
+		}
+		if i > 0 && Truncate(rows[i].Time.Ticks, precision) == Truncate(rows[i-1].Time.Ticks, precision) {
+			rows[i].Ranking = rows[i-1].Ranking
+		} else {
+			rows[i].Ranking = i + 1
+		}
+	}
+}
+
+// Truncate truncates the time to the classification precision.
+func Truncate(t, precision entities.Ticks) entities.Ticks {
+	return t.Truncate(precision)
+}
+
+// FormatTime formats the time truncated to the classification precision, i.e. 1:08.25.
+func FormatTime(t, precision entities.Ticks) string {
+	const second = entities.Ticks(10000000)
+	sign := ""
+	if t < 0 {
+		sign, t = "-", -t
+	}
+	t = Truncate(t, precision)
+	decimals, unit := 3, second/1000
+	if precision > 0 {
+		decimals, unit = 0, second
+		for unit > precision && unit%10 == 0 {
+			decimals, unit = decimals+1, unit/10
+		}
+	}
+	minutes, seconds, fraction := t/(60*second), t%(60*second)/second, t%second/unit
+	var res string
+	if minutes > 0 {
+		res = fmt.Sprintf("%s%d:%02d", sign, minutes, seconds)
+	} else {
+		res = fmt.Sprintf("%s%d", sign, seconds)
+	}
+	if decimals > 0 {
+		res += fmt.Sprintf(".%0*d", decimals, fraction)
+	}
+	return res
+}
+
+// WriteJSON writes the table as JSON.
+func (t *Table) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// WriteCSV writes the table as CSV with a header row and a column per split.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"ranking", "round", "pair", "lane", "competitor", "start number", "nationality"}
+	for _, length := range t.Splits {
+		header = append(header, strconv.FormatFloat(length, 'f', -1, 64)+"m")
+	}
+	header = append(header, "time")
+	var points bool
+	for _, row := range t.Rows {
+		points = points || row.Points != nil
+	}
+	if points {
+		header = append(header, "points")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		record := []string{
+			"",
+			strconv.Itoa(row.Round),
+			strconv.Itoa(row.Pair),
+			strconv.Itoa(row.Lane),
+			row.Competitor,
+			strconv.Itoa(row.StartNumber),
+			row.Nationality,
+		}
+		if row.Ranking > 0 {
+			record[0] = strconv.Itoa(row.Ranking)
+		}
+		for _, length := range t.Splits {
+			var text string
+			for _, split := range row.Splits {
+				if split.Length == length {
+					text = split.Time.Text
+					break
+				}
+			}
+			record = append(record, text)
+		}
+		if row.Time != nil {
+			record = append(record, row.Time.Text)
+		} else {
+			record = append(record, "")
+		}
+		if points {
+			var text string
+			if row.Points != nil {
+				text = strconv.Itoa(*row.Points)
+			}
+			record = append(record, text)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,170 @@
+// Copyright © 2020 Emando B.V.
+
+package results
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"os"
+	"testing"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/state"
+)
+
+// competitionOf returns the competition of the events in the example file.
+func competitionOf(t *testing.T, name string) *state.Competition {
+	t.Helper()
+	file, err := os.Open("../../examples/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	store := state.NewStore()
+	s := bufio.NewScanner(file)
+	s.Buffer(nil, 1<<22)
+	for s.Scan() {
+		if err := store.Apply(append([]byte(nil), s.Bytes()...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var res *state.Competition
+	store.Competitions(func(c *state.Competition) {
+		res = c
+	})
+	if res == nil {
+		t.Fatal("no competition")
+	}
+	return res
+}
+
+func TestFromCompetition(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		splits   int
+		rows     []string
+		rankings []int
+		times    []string
+	}{
+		{
+			name:     "20200112-ec-single-distances-recover-10-3.json",
+			splits:   16,
+			rows:     []string{"Russia", "Italy"},
+			rankings: []int{1, 2},
+			times:    []string{"3:42.48", "3:46.77"},
+		},
+		{
+			name:     "20200112-ec-single-distances-recover-11-4.json",
+			splits:   3,
+			rows:     []string{"Ellia Smeding", "Andzelika Wójcik", "Kaja Ziomek", "Ida Njåtun"},
+			rankings: []int{1, 2, 0, 0},
+			times:    []string{"1:18.50", "1:19.24", "", ""},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tables := FromCompetition(competitionOf(t, tc.name))
+			if len(tables) != 1 {
+				t.Fatalf("%d tables, want 1", len(tables))
+			}
+			table := tables[0]
+			if table.Schema != Schema || len(table.Splits) != tc.splits {
+				t.Errorf("table has schema %q and %d splits, want %d", table.Schema, len(table.Splits), tc.splits)
+			}
+			if len(table.Rows) != len(tc.rows) {
+				t.Fatalf("table has %d rows, want %d", len(table.Rows), len(tc.rows))
+			}
+			for i, row := range table.Rows {
+				var time string
+				if row.Time != nil {
+					time = row.Time.Text
+				}
+				if row.Competitor != tc.rows[i] || row.Ranking != tc.rankings[i] || time != tc.times[i] {
+					t.Errorf("row %d is %s ranked %d in %q, want %s ranked %d in %q",
+						i, row.Competitor, row.Ranking, time, tc.rows[i], tc.rankings[i], tc.times[i])
+				}
+			}
+
+			var buf bytes.Buffer
+			if err := table.WriteCSV(&buf); err != nil {
+				t.Fatal(err)
+			}
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != len(tc.rows)+1 || len(records[0]) != 8+tc.splits {
+				t.Errorf("CSV has %d records with %d columns", len(records), len(records[0]))
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	row := func(lane int, ticks entities.Ticks) Row {
+		r := Row{Round: 1, Pair: 1, Lane: lane}
+		if ticks > 0 {
+			r.Time = &Time{Ticks: ticks}
+		}
+		return r
+	}
+	for _, tc := range []struct {
+		name      string
+		precision entities.Ticks
+		rows      []Row
+		lanes     []int
+		rankings  []int
+	}{
+		{
+			name:     "ByTime",
+			rows:     []Row{row(0, 300), row(1, 100), row(2, 200)},
+			lanes:    []int{1, 2, 0},
+			rankings: []int{1, 2, 3},
+		},
+		{
+			name:      "TieAfterTruncate",
+			precision: 100,
+			rows:      []Row{row(0, 250), row(1, 210), row(2, 100)},
+			lanes:     []int{2, 0, 1},
+			rankings:  []int{1, 2, 2},
+		},
+		{
+			name:     "WithoutTime",
+			rows:     []Row{row(1, 0), row(0, 0), row(2, 100)},
+			lanes:    []int{2, 0, 1},
+			rankings: []int{1, 0, 0},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rank(tc.rows, tc.precision)
+			for i, row := range tc.rows {
+				if row.Lane != tc.lanes[i] || row.Ranking != tc.rankings[i] {
+					t.Errorf("row %d is lane %d ranked %d, want lane %d ranked %d", i, row.Lane, row.Ranking, tc.lanes[i], tc.rankings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	for _, tc := range []struct {
+		ticks, precision entities.Ticks
+		expected         string
+	}{
+		{ticks: 682512345, precision: 100000, expected: "1:08.25"},
+		{ticks: 682512345, precision: 10000, expected: "1:08.251"},
+		{ticks: 682512345, precision: 1000000, expected: "1:08.2"},
+		{ticks: 682512345, precision: 10000000, expected: "1:08"},
+		{ticks: 682512345, expected: "1:08.251"},
+		{ticks: 192544000, precision: 100000, expected: "19.25"},
+		{ticks: -192544000, precision: 100000, expected: "-19.25"},
+		{ticks: 0, precision: 100000, expected: "0.00"},
+	} {
+		if res := FormatTime(tc.ticks, tc.precision); res != tc.expected {
+			t.Errorf("time %d with precision %d is %q, want %q", tc.ticks, tc.precision, res, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright © 2020 Emando B.V.
+
+package state
+
+import (
+	"sort"
+
+	"github.com/emando/vantage-events/pkg/entities"
+)
+
+// MassStart returns whether the distance is a mass start distance.
+func (d *Distance) MassStart() bool {
+	return d.Discipline == entities.LongTrackMassStart
+}
+
+// Rounds returns the rounds completed and the rounds to go at the last presented lap of the race.
+func (r *Race) Rounds() (rounds, roundsToGo float64) {
+	if n := len(r.PresentedLaps); n > 0 {
+		last := r.PresentedLaps[n-1]
+		return last.Rounds, last.RoundsToGo
+	}
+	if n := len(r.EstimatedLaps); n > 0 {
+		last := r.EstimatedLaps[n-1]
+		return 0, last.Rounds
+	}
+	return 0, 0
+}
+
+// SprintPoints returns the sum of the points of the laps of the race.
+func (r *Race) SprintPoints() int {
+	var points int
+	for _, l := range r.Laps {
+		if l.Points != nil {
+			points += *l.Points
+		}
+	}
+	return points
+}
+
+// MassStartStanding is the standing of a race in a mass start heat.
+type MassStartStanding struct {
+	Race       *Race
+	Rounds     float64
+	RoundsToGo float64
+	Points     int
+	// Time is the final time, or the time at the last presented lap if the race did not finish.
+	Time     entities.Ticks
+	Finished bool
+	// Ranking is shared by races with equal points, rounds and time after truncating to the classification precision.
+	Ranking int
+}
+
+// MassStartStandings returns the standings of the races in the mass start heat. Races are ranked by sprint points,
+// then by completed rounds and then by time. Races with a result status, i.e. disqualified races, are ranked last.
+func (h *Heat) MassStartStandings(precision entities.Ticks) []MassStartStanding {
+	res := make([]MassStartStanding, 0, len(h.Races))
+	for _, r := range h.Races {
+		s := MassStartStanding{
+			Race:   r,
+			Points: r.SprintPoints(),
+		}
+		s.Rounds, s.RoundsToGo = r.Rounds()
+		if n := len(r.PresentedLaps); n > 0 {
+			s.Time = r.PresentedLaps[n-1].Time
+			s.Finished = s.RoundsToGo <= 0
+		}
+		if r.Time != nil && r.Time.Time > 0 {
+			s.Time = r.Time.Time
+			s.Finished = true
+		}
+		res = append(res, s)
+	}
+	excluded := func(s MassStartStanding) bool {
+		return !s.Race.Classified()
+	}
+	less := func(a, b MassStartStanding) bool {
+		if excluded(a) != excluded(b) {
+			return !excluded(a)
+		}
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Rounds != b.Rounds {
+			return a.Rounds > b.Rounds
+		}
+		if a.Rounds == 0 {
+			return false
+		}
+		return a.Time.Truncate(precision) < b.Time.Truncate(precision)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return less(res[i], res[j])
+	})
+	for i := range res {
+		if i > 0 && !less(res[i-1], res[i]) {
+			res[i].Ranking = res[i-1].Ranking
+		} else {
+			res[i].Ranking = i + 1
+		}
+	}
+	return res
+}
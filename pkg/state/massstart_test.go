@@ -0,0 +1,151 @@
+// Copyright © 2020 Emando B.V.
+
+package state
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emando/vantage-events/pkg/entities"
+)
+
+// massStartRace returns a race with the sprint points of the laps and the last presented lap.
+func massStartRace(id string, points []int, rounds, roundsToGo float64, t entities.Ticks) *Race {
+	r := &Race{Race: entities.Race{ID: id}}
+	for i := range points {
+		r.Laps = append(r.Laps, entities.Lap{RaceID: id, Points: &points[i]})
+	}
+	if rounds > 0 {
+		r.PresentedLaps = []entities.PresentedLap{{Time: t, Rounds: rounds, RoundsToGo: roundsToGo}}
+	}
+	return r
+}
+
+func TestMassStartStandings(t *testing.T) {
+	disqualified := massStartRace("disqualified", []int{60}, 16, 0, 4000)
+	disqualified.Result = &entities.RaceResult{Status: 1}
+	finished := massStartRace("finished", nil, 15, 1, 3000)
+	finished.Time = &entities.RaceTime{Time: 3100}
+
+	for _, tc := range []struct {
+		name      string
+		races     []*Race
+		precision entities.Ticks
+		ids       []string
+		rankings  []int
+	}{
+		{
+			name: "Points",
+			races: []*Race{
+				massStartRace("a", []int{1, 3}, 16, 0, 4000),
+				massStartRace("b", []int{60}, 16, 0, 4100),
+				massStartRace("c", nil, 16, 0, 3900),
+			},
+			ids:      []string{"b", "a", "c"},
+			rankings: []int{1, 2, 3},
+		},
+		{
+			name: "Rounds",
+			races: []*Race{
+				massStartRace("a", nil, 10, 6, 2500),
+				massStartRace("b", nil, 12, 4, 3000),
+				massStartRace("c", nil, 0, 0, 0),
+			},
+			ids:      []string{"b", "a", "c"},
+			rankings: []int{1, 2, 3},
+		},
+		{
+			name: "Time",
+			races: []*Race{
+				massStartRace("a", []int{3}, 16, 0, 4100),
+				massStartRace("b", []int{3}, 16, 0, 4000),
+			},
+			ids:      []string{"b", "a"},
+			rankings: []int{1, 2},
+		},
+		{
+			name: "TieAfterTruncate",
+			races: []*Race{
+				massStartRace("a", nil, 16, 0, 4009),
+				massStartRace("b", nil, 16, 0, 4001),
+				massStartRace("c", nil, 16, 0, 4010),
+			},
+			precision: 10,
+			ids:       []string{"a", "b", "c"},
+			rankings:  []int{1, 1, 3},
+		},
+		{
+			name: "NoRounds",
+			races: []*Race{
+				massStartRace("a", nil, 0, 0, 0),
+				massStartRace("b", nil, 0, 0, 0),
+			},
+			ids:      []string{"a", "b"},
+			rankings: []int{1, 1},
+		},
+		{
+			name: "FinishedTime",
+			races: []*Race{
+				massStartRace("a", nil, 15, 1, 3050),
+				finished,
+			},
+			ids:      []string{"a", "finished"},
+			rankings: []int{1, 2},
+		},
+		{
+			name: "Status",
+			races: []*Race{
+				disqualified,
+				massStartRace("a", nil, 16, 0, 4000),
+			},
+			ids:      []string{"a", "disqualified"},
+			rankings: []int{1, 2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &Heat{Races: tc.races}
+			var ids []string
+			var rankings []int
+			for _, s := range h.MassStartStandings(tc.precision) {
+				ids = append(ids, s.Race.ID)
+				rankings = append(rankings, s.Ranking)
+			}
+			if !reflect.DeepEqual(ids, tc.ids) {
+				t.Errorf("races are %v, want %v", ids, tc.ids)
+			}
+			if !reflect.DeepEqual(rankings, tc.rankings) {
+				t.Errorf("rankings are %v, want %v", rankings, tc.rankings)
+			}
+		})
+	}
+}
+
+func TestMassStartStandingFinished(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		race     *Race
+		time     entities.Ticks
+		finished bool
+	}{
+		{name: "Racing", race: massStartRace("a", nil, 10, 6, 2500), time: 2500},
+		{name: "LastLap", race: massStartRace("a", nil, 16, 0, 4000), time: 4000, finished: true},
+		{name: "NotStarted", race: massStartRace("a", nil, 0, 0, 0)},
+		{
+			name: "Time",
+			race: func() *Race {
+				r := massStartRace("a", nil, 15, 1, 3000)
+				r.Time = &entities.RaceTime{Time: 3100}
+				return r
+			}(),
+			time:     3100,
+			finished: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := (&Heat{Races: []*Race{tc.race}}).MassStartStandings(0)[0]
+			if s.Time != tc.time || s.Finished != tc.finished {
+				t.Errorf("time is %d and finished is %v, want %d and %v", s.Time, s.Finished, tc.time, tc.finished)
+			}
+		})
+	}
+}
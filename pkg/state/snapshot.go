@@ -0,0 +1,110 @@
+// Copyright © 2020 Emando B.V.
+
+package state
+
+import (
+	"sort"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+)
+
+const (
+	// StreamSnapshotType is the event name of the control message with the state of a competition that replaces the
+	// replayed events in snapshot streams.
+	StreamSnapshotType = "StreamSnapshotEvent"
+)
+
+// StreamSnapshot is the event data of the control message with the state of a competition.
+type StreamSnapshot struct {
+	events.Competition
+	State CompetitionSnapshot `json:"state"`
+	// Cursor is the cursor of the last event in the snapshot.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// CompetitionSnapshot is the JSON encodable state of a competition.
+type CompetitionSnapshot struct {
+	Competition      entities.Competition `json:"competition"`
+	ActiveDistanceID string               `json:"activeDistanceId,omitempty"`
+	LastActivity     *time.Time           `json:"lastActivity,omitempty"`
+	Distances        []DistanceSnapshot   `json:"distances"`
+}
+
+// DistanceSnapshot is the JSON encodable state of a competition distance.
+type DistanceSnapshot struct {
+	Distance entities.Distance `json:"distance"`
+	Active   bool              `json:"active"`
+	Heats    []HeatSnapshot    `json:"heats"`
+}
+
+// HeatSnapshot is the JSON encodable state of a competition distance heat.
+type HeatSnapshot struct {
+	Round     int            `json:"round"`
+	Number    int            `json:"number"`
+	Active    bool           `json:"active"`
+	Started   *time.Time     `json:"started,omitempty"`
+	Committed bool           `json:"committed"`
+	Races     []RaceSnapshot `json:"races"`
+}
+
+// RaceSnapshot is the JSON encodable state of a race.
+type RaceSnapshot struct {
+	Race          entities.Race           `json:"race"`
+	Start         *entities.Start         `json:"start,omitempty"`
+	EstimatedLaps []entities.PresentedLap `json:"estimatedLaps,omitempty"`
+	PresentedLaps []entities.PresentedLap `json:"presentedLaps,omitempty"`
+	Laps          []entities.Lap          `json:"laps,omitempty"`
+	Passings      []entities.Passing      `json:"passings,omitempty"`
+}
+
+// Snapshot returns the JSON encodable state of the competition. Distances are ordered by number.
+func (c *Competition) Snapshot() CompetitionSnapshot {
+	res := CompetitionSnapshot{
+		Competition:      c.Competition,
+		ActiveDistanceID: c.ActiveDistanceID,
+		LastActivity:     timeOrNil(c.LastActivity),
+		Distances:        make([]DistanceSnapshot, 0, len(c.Distances)),
+	}
+	for _, d := range c.Distances {
+		distance := DistanceSnapshot{
+			Distance: d.Distance,
+			Active:   d.Active,
+			Heats:    make([]HeatSnapshot, 0, len(d.Heats)),
+		}
+		for _, h := range d.Heats {
+			heat := HeatSnapshot{
+				Round:     h.Key.Round,
+				Number:    h.Key.Number,
+				Active:    h.Active,
+				Started:   timeOrNil(h.Started),
+				Committed: h.Committed,
+				Races:     make([]RaceSnapshot, 0, len(h.Races)),
+			}
+			for _, r := range h.Races {
+				heat.Races = append(heat.Races, RaceSnapshot{
+					Race:          r.Race,
+					Start:         r.Start,
+					EstimatedLaps: r.EstimatedLaps,
+					PresentedLaps: r.PresentedLaps,
+					Laps:          r.Laps,
+					Passings:      r.Passings,
+				})
+			}
+			distance.Heats = append(distance.Heats, heat)
+		}
+		res.Distances = append(res.Distances, distance)
+	}
+	sort.Slice(res.Distances, func(i, j int) bool {
+		return res.Distances[i].Distance.Number < res.Distances[j].Distance.Number
+	})
+	return res
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
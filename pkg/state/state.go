@@ -0,0 +1,416 @@
+// Copyright © 2020 Emando B.V.
+
+// Package state reconstructs the state of Vantage competitions from events.
+package state
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/emando/vantage-events/pkg/entities"
+	"github.com/emando/vantage-events/pkg/events"
+)
+
+// Competition is the state of a competition.
+type Competition struct {
+	entities.Competition
+	Distances        map[string]*Distance
+	ActiveDistanceID string
+	// LastActivity is the time of the last start, lap or passing in the competition.
+	LastActivity time.Time
+}
+
+// Distance is the state of a competition distance.
+type Distance struct {
+	entities.Distance
+	Active bool
+	Heats  []*Heat
+}
+
+// HeatKey identifies a heat in a distance.
+type HeatKey struct {
+	Round,
+	Number int
+}
+
+// KeyOf returns the key of the heat.
+func KeyOf(heat entities.Heat) HeatKey {
+	return HeatKey{Round: heat.Key.Round, Number: heat.Key.Number}
+}
+
+// Heat is the state of a competition distance heat.
+type Heat struct {
+	Key       HeatKey
+	Active    bool
+	Started   time.Time
+	Committed bool
+	Races     []*Race
+}
+
+// Race is the state of a race in a heat.
+type Race struct {
+	entities.Race
+	Start         *entities.Start
+	EstimatedLaps []entities.PresentedLap
+	PresentedLaps []entities.PresentedLap
+	Laps          []entities.Lap
+	Passings      []entities.Passing
+}
+
+// Classified returns whether the race is classified, i.e. it has no result status such as a disqualification.
+func (r *Race) Classified() bool {
+	return r.Result == nil || r.Result.Status == 0
+}
+
+// LengthTolerance is the tolerance in meters to compare passed lengths.
+const LengthTolerance = 0.01
+
+// TimeAt returns the time of the lap at the passed length.
+func TimeAt(laps []entities.PresentedLap, length float64) (entities.Ticks, bool) {
+	for _, l := range laps {
+		if math.Abs(l.PassedLength-length) < LengthTolerance {
+			return l.Time, true
+		}
+	}
+	return 0, false
+}
+
+// Store keeps the state of competitions.
+type Store struct {
+	mu           sync.RWMutex
+	competitions map[string]*Competition
+}
+
+// NewStore returns a new Store.
+func NewStore() *Store {
+	return &Store{
+		competitions: make(map[string]*Competition),
+	}
+}
+
+// Reset clears the state of all competitions.
+func (s *Store) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.competitions = make(map[string]*Competition)
+}
+
+// Apply applies the JSON encoded event to the state. Unknown events are ignored.
+func (s *Store) Apply(buf []byte) error {
+	var header events.Race
+	if err := json.Unmarshal(buf, &header); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if header.TypeName() == events.CompetitionActivatedType {
+		event := &events.CompetitionActivated{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		c, ok := s.competitions[event.CompetitionID]
+		if !ok {
+			c = &Competition{
+				Distances: make(map[string]*Distance),
+			}
+			s.competitions[event.CompetitionID] = c
+		}
+		c.Competition = event.Value
+		return nil
+	}
+	c, ok := s.competitions[header.CompetitionID]
+	if !ok {
+		return nil
+	}
+	if err := c.apply(header, buf); err != nil {
+		return err
+	}
+	return c.touch(header, buf)
+}
+
+// Competition calls f with the state of the competition with the given ID.
+// The state must not be retained or modified after f returns.
+// This method returns false if the competition is unknown.
+func (s *Store) Competition(id string, f func(*Competition)) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.competitions[id]
+	if !ok {
+		return false
+	}
+	f(c)
+	return true
+}
+
+// Heat calls f with the state of the heat in the competition distance.
+// The state must not be retained or modified after f returns.
+// This method returns false if the heat is unknown.
+func (s *Store) Heat(competitionID, distanceID string, key HeatKey, f func(*Competition, *Distance, *Heat)) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.competitions[competitionID]
+	if !ok {
+		return false
+	}
+	d, ok := c.Distances[distanceID]
+	if !ok {
+		return false
+	}
+	h := d.Heat(key)
+	if h == nil {
+		return false
+	}
+	f(c, d, h)
+	return true
+}
+
+// Competitions calls f with the state of each known competition.
+// The state must not be retained or modified after f returns.
+func (s *Store) Competitions(f func(*Competition)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.competitions {
+		f(c)
+	}
+}
+
+func (c *Competition) apply(header events.Race, buf []byte) error {
+	switch header.TypeName() {
+	case events.DistanceActivatedType:
+		event := &events.DistanceActivated{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		d := c.distance(event.DistanceID)
+		d.Distance = event.Value
+		d.Active = true
+		if active, ok := c.Distances[c.ActiveDistanceID]; ok && active != d {
+			active.Active = false
+		}
+		c.ActiveDistanceID = event.DistanceID
+		return nil
+	case events.DistanceDeactivatedType:
+		if d, ok := c.Distances[header.DistanceID]; ok {
+			d.Active = false
+		}
+		if c.ActiveDistanceID == header.DistanceID {
+			c.ActiveDistanceID = ""
+		}
+		return nil
+	}
+	if header.DistanceID == "" {
+		return nil
+	}
+	return c.distance(header.DistanceID).apply(header, buf)
+}
+
+// touch updates the last activity from the time of a start, lap or passing.
+func (c *Competition) touch(header events.Race, buf []byte) error {
+	switch header.TypeName() {
+	case events.HeatStartedType, events.RaceLapAddedType, events.RacePassingAddedType:
+	default:
+		return nil
+	}
+	var activity struct {
+		Started time.Time `json:"started"`
+		Lap     struct {
+			When time.Time `json:"when"`
+		} `json:"lap"`
+		Passing struct {
+			When time.Time `json:"when"`
+		} `json:"passing"`
+	}
+	if err := json.Unmarshal(buf, &activity); err != nil {
+		return err
+	}
+	for _, t := range []time.Time{activity.Started, activity.Lap.When, activity.Passing.When} {
+		if t.After(c.LastActivity) {
+			c.LastActivity = t
+		}
+	}
+	return nil
+}
+
+func (c *Competition) distance(id string) *Distance {
+	d, ok := c.Distances[id]
+	if !ok {
+		d = &Distance{}
+		d.ID = id
+		c.Distances[id] = d
+	}
+	return d
+}
+
+// Heat returns the heat with the given key, or nil if the heat is unknown.
+func (d *Distance) Heat(key HeatKey) *Heat {
+	for _, h := range d.Heats {
+		if h.Key == key {
+			return h
+		}
+	}
+	return nil
+}
+
+func (d *Distance) heat(key HeatKey) *Heat {
+	if h := d.Heat(key); h != nil {
+		return h
+	}
+	h := &Heat{Key: key}
+	d.Heats = append(d.Heats, h)
+	sort.Slice(d.Heats, func(i, j int) bool {
+		a, b := d.Heats[i].Key, d.Heats[j].Key
+		if a.Round != b.Round {
+			return a.Round < b.Round
+		}
+		return a.Number < b.Number
+	})
+	return h
+}
+
+func (d *Distance) apply(header events.Race, buf []byte) error {
+	key := KeyOf(header.Heat.Heat)
+	switch header.TypeName() {
+	case events.HeatActivatedType:
+		event := &events.HeatActivated{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		h := d.heat(key)
+		h.Active = true
+		h.setRaces(event.Races)
+		return nil
+	case events.HeatCommittedType:
+		event := &events.HeatCommitted{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		h := d.heat(key)
+		h.Committed = true
+		h.setRaces(event.Races)
+		return nil
+	}
+	h := d.Heat(key)
+	if h == nil {
+		return nil
+	}
+	return h.apply(header, buf)
+}
+
+// Race returns the race with the given ID, or nil if the race is unknown.
+func (h *Heat) Race(id string) *Race {
+	for _, r := range h.Races {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// setRaces sets the races from a heat activation or commit. Presented laps are retained.
+func (h *Heat) setRaces(races []entities.HeatRace) {
+	res := make([]*Race, 0, len(races))
+	for _, hr := range races {
+		r := &Race{
+			Race:          hr.Race,
+			Start:         hr.Start,
+			EstimatedLaps: hr.EstimatedLaps,
+			Laps:          hr.Laps,
+			Passings:      hr.Passings,
+		}
+		if existing := h.Race(hr.Race.ID); existing != nil {
+			r.PresentedLaps = existing.PresentedLaps
+		}
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Lane < res[j].Lane
+	})
+	h.Races = res
+}
+
+func (h *Heat) apply(header events.Race, buf []byte) error {
+	switch header.TypeName() {
+	case events.HeatDeactivatedType:
+		h.Active = false
+		return nil
+	case events.HeatClearedType:
+		h.Started = time.Time{}
+		for _, r := range h.Races {
+			r.Start = nil
+			r.PresentedLaps = nil
+			r.Laps = nil
+			r.Passings = nil
+		}
+		return nil
+	case events.HeatStartedType:
+		event := &events.HeatStarted{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		h.Started = event.Started
+		return nil
+	}
+	r := h.Race(header.RaceID)
+	if r == nil {
+		return nil
+	}
+	switch header.TypeName() {
+	case events.RaceLapAddedType:
+		event := &events.RaceLapAdded{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		r.addLap(event.Lap)
+	case events.LastPresentedRaceLapChangedType:
+		event := &events.LastPresentedRaceLapChanged{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		r.setPresentedLap(event.Lap)
+	case events.RacePassingAddedType:
+		event := &events.RacePassingAdded{}
+		if err := json.Unmarshal(buf, event); err != nil {
+			return err
+		}
+		r.addPassing(event.Passing)
+	}
+	return nil
+}
+
+// addLap adds the lap, unless the lap of the appliance with the time was added before, i.e. when events are replayed
+// after resubscribing.
+func (r *Race) addLap(lap entities.Lap) {
+	for _, l := range r.Laps {
+		if l.Time == lap.Time && l.InstanceName == lap.InstanceName && l.PresentationSource == lap.PresentationSource {
+			return
+		}
+	}
+	r.Laps = append(r.Laps, lap)
+}
+
+// addPassing adds the passing, unless the passing of the appliance with the time and timing point was added before.
+func (r *Race) addPassing(passing entities.Passing) {
+	for _, p := range r.Passings {
+		if p.Time == passing.Time && p.Where == passing.Where && p.InstanceName == passing.InstanceName &&
+			p.PresentationSource == passing.PresentationSource {
+			return
+		}
+	}
+	r.Passings = append(r.Passings, passing)
+}
+
+func (r *Race) setPresentedLap(lap entities.PresentedLap) {
+	for i, l := range r.PresentedLaps {
+		if l.Index == lap.Index {
+			r.PresentedLaps[i] = lap
+			return
+		}
+	}
+	r.PresentedLaps = append(r.PresentedLaps, lap)
+	sort.Slice(r.PresentedLaps, func(i, j int) bool {
+		return r.PresentedLaps[i].Index < r.PresentedLaps[j].Index
+	})
+}
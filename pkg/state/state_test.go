@@ -0,0 +1,178 @@
+// Copyright © 2020 Emando B.V.
+
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/emando/vantage-events/pkg/events"
+)
+
+// readEvents returns the events of the example file.
+func readEvents(t *testing.T, name string) [][]byte {
+	t.Helper()
+	file, err := os.Open("../../examples/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var res [][]byte
+	s := bufio.NewScanner(file)
+	s.Buffer(nil, 1<<22)
+	for s.Scan() {
+		res = append(res, append([]byte(nil), s.Bytes()...))
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// counts returns the number of laps and passings in the heat.
+func counts(h *Heat) (laps, passings int) {
+	for _, r := range h.Races {
+		laps += len(r.Laps)
+		passings += len(r.Passings)
+	}
+	return laps, passings
+}
+
+func TestApply(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		distance string
+		heats    map[HeatKey][3]int
+		active   HeatKey
+	}{
+		{
+			name:     "20200112-ec-single-distances-recover-10-3.json",
+			distance: "Men Team Pursuit",
+			heats:    map[HeatKey][3]int{{1, 3}: {2, 34, 194}},
+		},
+		{
+			name:     "20200112-ec-single-distances-recover-11-4.json",
+			distance: "Ladies 1000 meter",
+			heats:    map[HeatKey][3]int{{1, 4}: {2, 14, 68}, {1, 5}: {2, 0, 0}},
+			active:   HeatKey{1, 5},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewStore()
+			for _, buf := range readEvents(t, tc.name) {
+				if err := store.Apply(buf); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var competitions int
+			store.Competitions(func(c *Competition) {
+				competitions++
+				if c.LastActivity.IsZero() {
+					t.Error("last activity is not set")
+				}
+				d, ok := c.Distances[c.ActiveDistanceID]
+				if !ok {
+					t.Fatalf("active distance %q not found", c.ActiveDistanceID)
+				}
+				if d.Name != tc.distance {
+					t.Errorf("distance is %q, want %q", d.Name, tc.distance)
+				}
+				if len(d.Heats) != len(tc.heats) {
+					t.Errorf("distance has %d heats, want %d", len(d.Heats), len(tc.heats))
+				}
+				for key, expected := range tc.heats {
+					h := d.Heat(key)
+					if h == nil {
+						t.Errorf("heat %v not found", key)
+						continue
+					}
+					laps, passings := counts(h)
+					if len(h.Races) != expected[0] || laps != expected[1] || passings != expected[2] {
+						t.Errorf("heat %v has %d races, %d laps and %d passings, want %v", key, len(h.Races), laps, passings, expected)
+					}
+					if h.Active != (key == tc.active) {
+						t.Errorf("heat %v active is %v", key, h.Active)
+					}
+				}
+			})
+			if competitions != 1 {
+				t.Errorf("store has %d competitions, want 1", competitions)
+			}
+		})
+	}
+}
+
+// totals returns the number of laps and passings in the store.
+func totals(store *Store) (laps, passings int) {
+	store.Competitions(func(c *Competition) {
+		for _, d := range c.Distances {
+			for _, h := range d.Heats {
+				l, p := counts(h)
+				laps, passings = laps+l, passings+p
+			}
+		}
+	})
+	return laps, passings
+}
+
+func TestApplyReplayed(t *testing.T) {
+	for _, name := range []string{
+		"20200112-ec-single-distances-recover-10-3.json",
+		"20200112-ec-single-distances-recover-11-4.json",
+	} {
+		evs := readEvents(t, name)
+		for _, n := range []int{len(evs) / 4, len(evs) / 2, len(evs) * 3 / 4, len(evs)} {
+			t.Run(fmt.Sprintf("%s/%d", name, n), func(t *testing.T) {
+				// The events are applied up to n, and the last 20 events are replayed after resubscribing.
+				expected, replayed := NewStore(), NewStore()
+				for _, buf := range evs[:n] {
+					if err := expected.Apply(buf); err != nil {
+						t.Fatal(err)
+					}
+				}
+				for _, buf := range append(evs[:n:n], evs[n-20:n]...) {
+					if err := replayed.Apply(buf); err != nil {
+						t.Fatal(err)
+					}
+				}
+				expectedLaps, expectedPassings := totals(expected)
+				laps, passings := totals(replayed)
+				if laps != expectedLaps || passings != expectedPassings {
+					t.Errorf("store has %d laps and %d passings, want %d and %d", laps, passings, expectedLaps, expectedPassings)
+				}
+			})
+		}
+	}
+}
+
+func TestAddPassing(t *testing.T) {
+	const passing = `{"time":1,"where":7,"instanceName":"Primary","presentationSource":{"how":"Transponder"}}`
+	for _, tc := range []struct {
+		name     string
+		passings []string
+		expected int
+	}{
+		{name: "Once", passings: []string{passing}, expected: 1},
+		{name: "Twice", passings: []string{passing, passing}, expected: 1},
+		{name: "OtherTime", passings: []string{passing, `{"time":2,"where":7,"instanceName":"Primary","presentationSource":{"how":"Transponder"}}`}, expected: 2},
+		{name: "OtherTimingPoint", passings: []string{passing, `{"time":1,"where":8,"instanceName":"Primary","presentationSource":{"how":"Transponder"}}`}, expected: 2},
+		{name: "OtherAppliance", passings: []string{passing, `{"time":1,"where":7,"instanceName":"Primary","presentationSource":{"how":"Optical"}}`}, expected: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var r Race
+			for _, p := range tc.passings {
+				var event events.RacePassingAdded
+				if err := json.Unmarshal([]byte(`{"passing":`+p+`}`), &event); err != nil {
+					t.Fatal(err)
+				}
+				r.addPassing(event.Passing)
+			}
+			if len(r.Passings) != tc.expected {
+				t.Errorf("race has %d passings, want %d", len(r.Passings), tc.expected)
+			}
+		})
+	}
+}
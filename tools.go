@@ -4,6 +4,7 @@ package main
 
 import (
 	_ "github.com/FiloSottile/mkcert"
+	_ "github.com/golang/protobuf/protoc-gen-go"
 	_ "golang.org/x/lint/golint"
 	_ "mvdan.cc/gofumpt/gofumports"
 )